@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+	_ "time/tzdata"
+	"todo-service/config"
+	"todo-service/internal/repair"
+	"todo-service/internal/setting"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// migrate runs one-off data fixes against the service database:
+//
+//	go run ./cmd/migrate -task repair-job-numbers [-dry-run]
+func main() {
+	task := flag.String("task", "", "migration to run: repair-job-numbers")
+	dryRun := flag.Bool("dry-run", false, "report the changes without writing them")
+	flag.Parse()
+
+	if _, err := os.Stat(".env"); err == nil {
+		if err := godotenv.Load(); err != nil {
+			log.Printf("Warning: Error loading .env file: %v", err)
+		}
+	}
+
+	cfg := config.LoadConfig()
+
+	mongoClient, err := connectToMongoDB(cfg.MongoURI)
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	db := mongoClient.Database(cfg.MongoDB)
+	ctx := context.Background()
+
+	switch *task {
+	case "repair-job-numbers":
+		settingRepository := setting.NewSettingRepository(db.Collection("organization_setting"), db.Collection("user_setting"))
+		// The migration only reads settings, which needs no user lookups
+		settingService := setting.NewSettingService(settingRepository, nil)
+		repairRepository := repair.NewRepairRepository(db.Collection("repair"), db.Collection("repair_sla_policy"), db.Collection("repair_counter"), db.Collection("repair_assignment_queue"), db.Collection("repair_maintenance_plan"))
+
+		result, err := repair.MigrateJobNumbers(ctx, repairRepository, settingService, *dryRun)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Checked %d organizations: %d repairs renumbered, %d given a job code (dry run: %t)",
+			result.Organizations, result.Renumbered, result.Coded, *dryRun)
+
+		if !*dryRun {
+			if err := repairRepository.EnsureIndexes(ctx); err != nil {
+				log.Fatalf("Failed to create repair indexes: %v", err)
+			}
+			log.Println("Repair job numbers are unique per organization")
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func connectToMongoDB(uri string) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -7,15 +7,25 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 	"todo-service/config"
+	"todo-service/internal/asset"
+	"todo-service/internal/board"
+	"todo-service/internal/label"
 	"todo-service/internal/location"
+	"todo-service/internal/notification"
+	"todo-service/internal/overdue"
 	"todo-service/internal/repair"
+	"todo-service/internal/scan"
+	"todo-service/internal/setting"
 	"todo-service/internal/shop"
 	"todo-service/internal/task"
+	"todo-service/internal/timesheet"
 	"todo-service/internal/todo"
 	"todo-service/internal/uploader"
 	"todo-service/internal/user"
 	"todo-service/pkg/consul"
+	"todo-service/pkg/firebase"
 	"todo-service/pkg/zap"
 
 	"github.com/gin-gonic/gin"
@@ -38,6 +48,9 @@ func main() {
 	}
 
 	cfg := config.LoadConfig()
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET is required to verify bearer tokens")
+	}
 
 	logger, err := zap.New(cfg)
 	if err != nil {
@@ -63,6 +76,17 @@ func main() {
 	locationService := location.NewLocationService(consulClient)
 	uploaderService := uploader.NewImageService(consulClient)
 
+	organizationSettingCollection := mongoClient.Database(cfg.MongoDB).Collection("organization_setting")
+	userSettingCollection := mongoClient.Database(cfg.MongoDB).Collection("user_setting")
+	settingRepository := setting.NewSettingRepository(organizationSettingCollection, userSettingCollection)
+	settingService := setting.NewSettingService(settingRepository, userService)
+	settingHandler := setting.NewSettingHandler(settingService)
+
+	notificationCollection := mongoClient.Database(cfg.MongoDB).Collection("notification")
+	notificationRepository := notification.NewNotificationRepository(notificationCollection)
+	notificationService := notification.NewNotificationService(notificationRepository, newPusher())
+	notificationHandler := notification.NewNotificationHandler(notificationService)
+
 	repairItemCollection := mongoClient.Database(cfg.MongoDB).Collection("repair_item")
 	productCollection := mongoClient.Database(cfg.MongoDB).Collection("product")
 	shopCollection := mongoClient.Database(cfg.MongoDB).Collection("shop")
@@ -71,26 +95,91 @@ func main() {
 	shopService := shop.NewShopService(shopRepository, uploaderService)
 	shopHandler := shop.NewShopHandler(shopService)
 	todoCollection := mongoClient.Database(cfg.MongoDB).Collection("todo")
-	todoRepository := todo.NewTodoRepository(todoCollection)
-	todoService := todo.NewTodoService(todoRepository, userService)
+	boardCollection := mongoClient.Database(cfg.MongoDB).Collection("board")
+	boardRepository := board.NewBoardRepository(boardCollection, todoCollection)
+	boardService := board.NewBoardService(boardRepository, settingService)
+	boardHandler := board.NewBoardHandler(boardService)
+
+	todoInviteCollection := mongoClient.Database(cfg.MongoDB).Collection("todo_invite")
+	todoInviteRedemptionCollection := mongoClient.Database(cfg.MongoDB).Collection("todo_invite_redemption")
+	todoJoinRequestCollection := mongoClient.Database(cfg.MongoDB).Collection("todo_join_request")
+	todoRepository := todo.NewTodoRepository(todoCollection, todoInviteCollection, todoInviteRedemptionCollection, todoJoinRequestCollection)
+
+	taskCollection := mongoClient.Database(cfg.MongoDB).Collection("task")
+	taskRepository := task.NewTaskRepository(taskCollection)
+
+	timeEntryCollection := mongoClient.Database(cfg.MongoDB).Collection("time_entry")
+	timesheetRepository := timesheet.NewTimesheetRepository(timeEntryCollection)
+	if err := timesheetRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("[WARN] failed to create timesheet indexes: %v", err)
+	}
+	timesheetService := timesheet.NewTimesheetService(timesheetRepository, userService, settingService, todo.NewTimesheetTarget(todoRepository), task.NewTimesheetTarget(taskRepository))
+	timesheetHandler := timesheet.NewTimesheetHandler(timesheetService)
+
+	todoService := todo.NewTodoService(todoRepository, userService, settingService, boardService, notificationService, timesheetService)
 	todoHandler := todo.NewTodoHandler(todoService)
 
+	assetCollection := mongoClient.Database(cfg.MongoDB).Collection("asset")
+	assetRepository := asset.NewAssetRepository(assetCollection)
+	if err := assetRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("[WARN] failed to create asset indexes: %v", err)
+	}
+
 	repairCollection := mongoClient.Database(cfg.MongoDB).Collection("repair")
-	repairRepository := repair.NewRepairRepository(repairCollection)
-	repairService := repair.NewRepairService(repairRepository, locationService, userService, uploaderService, shopService)
+	repairSLAPolicyCollection := mongoClient.Database(cfg.MongoDB).Collection("repair_sla_policy")
+	repairCounterCollection := mongoClient.Database(cfg.MongoDB).Collection("repair_counter")
+	repairQueueCollection := mongoClient.Database(cfg.MongoDB).Collection("repair_assignment_queue")
+	repairPlanCollection := mongoClient.Database(cfg.MongoDB).Collection("repair_maintenance_plan")
+	repairRepository := repair.NewRepairRepository(repairCollection, repairSLAPolicyCollection, repairCounterCollection, repairQueueCollection, repairPlanCollection)
+	if err := repairRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("[WARN] failed to create repair indexes, run cmd/migrate -task repair-job-numbers: %v", err)
+	}
+	repairService := repair.NewRepairService(repairRepository, locationService, userService, uploaderService, shopService, settingService, notificationService, assetRepository)
 	repairHandler := repair.NewRepairHandler(repairService)
 
-	taskCollection := mongoClient.Database(cfg.MongoDB).Collection("task")
-	taskRepository := task.NewTaskRepository(taskCollection)
-	taskService := task.NewTaskService(taskRepository, userService, uploaderService)
+	assetService := asset.NewAssetService(assetRepository, repair.NewAssetHistory(repairRepository), locationService, userService, settingService)
+	assetHandler := asset.NewAssetHandler(assetService)
+
+	taskService := task.NewTaskService(taskRepository, userService, uploaderService, settingService, timesheetService)
 	taskHandler := task.NewTaskHandler(taskService)
 
+	labelService := label.NewLabelService(todoRepository, repairRepository, locationService, userService)
+	labelHandler := label.NewLabelHandler(labelService)
+
+	scanService := scan.NewScanService(todoService, repairService, assetService, locationService)
+	scanHandler := scan.NewScanHandler(scanService)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	if os.Getenv("OVERDUE_WORKER_ENABLED") != "false" {
+		overdueWorker := overdue.NewWorker(todoRepository, taskRepository, settingService, notificationService, durationFromEnv("OVERDUE_WORKER_INTERVAL", overdue.DefaultInterval))
+		go overdueWorker.Start(workerCtx)
+	}
+
+	if os.Getenv("REPAIR_SLA_WORKER_ENABLED") != "false" {
+		slaWorker := repair.NewSLAWorker(repairRepository, durationFromEnv("REPAIR_SLA_WORKER_INTERVAL", repair.DefaultSLAWorkerInterval))
+		go slaWorker.Start(workerCtx)
+	}
+
+	if os.Getenv("MAINTENANCE_WORKER_ENABLED") != "false" {
+		maintenanceWorker := repair.NewMaintenanceWorker(repairService, durationFromEnv("MAINTENANCE_WORKER_INTERVAL", repair.DefaultMaintenanceWorkerInterval))
+		go maintenanceWorker.Start(workerCtx)
+	}
+
 	r := gin.Default()
 
 	todo.RegisterRoutes(r, todoHandler)
 	repair.RegisterRoutes(r, repairHandler)
+	asset.RegisterRoutes(r, assetHandler)
 	shop.RegisterRoutes(r, shopHandler)
 	task.RegisterRoutes(r, taskHandler)
+	setting.RegisterRoutes(r, settingHandler)
+	board.RegisterRoutes(r, boardHandler)
+	label.RegisterRoutes(r, labelHandler)
+	scan.RegisterRoutes(r, scanHandler)
+	notification.RegisterRoutes(r, notificationHandler)
+	timesheet.RegisterRoutes(r, timesheetHandler)
 	// Handle OS signal để deregister
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -98,6 +187,7 @@ func main() {
 	go func() {
 		<-quit
 		log.Println("Shutting down server... De-registering from Consul...")
+		stopWorkers()
 		consulConn.Deregister()
 		os.Exit(0)
 	}()
@@ -113,6 +203,33 @@ func main() {
 	}
 }
 
+// newPusher enables FCM push when FCM_ENABLED is true; otherwise notifications stay in the inbox.
+func newPusher() notification.Pusher {
+	if os.Getenv("FCM_ENABLED") != "true" {
+		log.Println("FCM push disabled, notifications are stored in the inbox only")
+		return nil
+	}
+
+	_, _, messagingClient := firebase.SetUpFireBase()
+	return notification.NewFCMPusher(messagingClient)
+}
+
+// durationFromEnv reads a Go duration such as "10m", falling back when unset or invalid.
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+
+	return d
+}
+
 func connectToMongoDB(uri string) (*mongo.Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -55,6 +55,9 @@ type Config struct {
 	Registry Registry         `mapstructure:"registry" validate:"required"`
 	App      AppConfiguration `mapstructure:"app"`
 	Zap      ZapConfig        `mapstructure:"zap"`
+
+	// JWTSecret verifies the bearer tokens. The server does not start without it.
+	JWTSecret string
 }
 
 func LoadConfig() *Config {
@@ -98,6 +101,7 @@ func LoadConfig() *Config {
 				},
 			},
 		},
+		JWTSecret: getEnv("JWT_SECRET", ""),
 	}
 	return config
 }
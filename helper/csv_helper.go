@@ -0,0 +1,13 @@
+package helper
+
+import "strings"
+
+// CSVText makes user-supplied text safe to put in an exported CSV cell. Spreadsheets run a
+// cell starting with =, +, -, @, a tab or a carriage return as a formula, so such text is
+// prefixed with a quote.
+func CSVText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+)
+
+// LegacyDateTimeLayout is the zone-less layout clients used before RFC 3339 was accepted.
+const LegacyDateTimeLayout = "2006-01-02 15:04:05"
+
+// ParseDateTime parses an RFC 3339 timestamp or a legacy "YYYY-MM-DD HH:MM:SS" value.
+// Legacy values carry no offset, so they are read as wall-clock time in loc.
+// The result is always returned in UTC.
+func ParseDateTime(value string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.ParseInLocation(LegacyDateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 or YYYY-MM-DD HH:MM:SS: %v", err)
+	}
+
+	return t.UTC(), nil
+}
+
+// StartOfDay returns midnight of t's calendar day in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// InLocation converts an optional timestamp to loc, keeping nil as nil.
+func InLocation(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil || loc == nil {
+		return t
+	}
+	local := t.In(loc)
+	return &local
+}
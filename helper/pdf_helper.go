@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"github.com/go-pdf/fpdf"
+)
+
+// LoadPDFFont picks the font text is printed with. A TTF at fontPath keeps non-Latin-1
+// names intact; without one the core Helvetica font is used and text is translated to
+// its encoding. Strings must go through the returned translate before being printed.
+func LoadPDFFont(pdf *fpdf.Fpdf, fontPath string) (family string, translate func(string) string, err error) {
+	if fontPath == "" {
+		return "Helvetica", pdf.UnicodeTranslatorFromDescriptor(""), nil
+	}
+
+	pdf.AddUTF8Font("custom", "", fontPath)
+	pdf.AddUTF8Font("custom", "B", fontPath)
+	if pdf.Err() {
+		return "", nil, pdf.Error()
+	}
+
+	return "custom", func(s string) string { return s }, nil
+}
+
+// FitPDFText shortens text with an ellipsis until it fits in width at the current font.
+// Truncation happens on runes before translating, so multi-byte characters stay intact.
+func FitPDFText(pdf *fpdf.Fpdf, translate func(string) string, text string, width float64) string {
+	if encoded := translate(text); pdf.GetStringWidth(encoded) <= width {
+		return encoded
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := translate(string(runes) + "...")
+		if pdf.GetStringWidth(candidate) <= width {
+			return candidate
+		}
+	}
+
+	return ""
+}
@@ -1,12 +1,18 @@
 package helper
 
 import (
+	"errors"
+	"fmt"
+	"todo-service/internal/authz"
+
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	ErrInvalidOperation = "ERR_INVALID_OPERATION"
 	ErrInvalidRequest   = "ERR_INVALID_REQUEST"
+	ErrForbidden        = "ERR_FORBIDDEN"
+	ErrConflict         = "ERR_CONFLICT"
 )
 
 type APIResponse struct {
@@ -34,3 +40,46 @@ func SendError(c *gin.Context, statusCode int, err error, errorCode string) {
 		ErrorCode:  errorCode,
 	})
 }
+
+// ConflictError is returned when a request clashes with the current state, such as a
+// duplicate or a concurrent change. Handlers answer it with 409.
+type ConflictError struct {
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Reason
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return &ConflictError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+// SendServiceError answers authorization denials with 403, conflicts with 409 and any other
+// service error with 500.
+func SendServiceError(c *gin.Context, err error) {
+	if authz.IsDenied(err) {
+		SendError(c, 403, err, ErrForbidden)
+		return
+	}
+	if IsConflict(err) {
+		SendConflict(c, err, nil)
+		return
+	}
+	SendError(c, 500, err, ErrInvalidOperation)
+}
+
+// SendConflict answers with 409 and the data the request clashed with, so the client can decide.
+func SendConflict(c *gin.Context, err error, data interface{}) {
+	c.JSON(409, APIResponse{
+		StatusCode: 409,
+		Data:       data,
+		Error:      err.Error(),
+		ErrorCode:  ErrConflict,
+	})
+}
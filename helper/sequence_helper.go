@@ -0,0 +1,57 @@
+package helper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSequenceFormatLength = 32
+
+var sequenceToken = regexp.MustCompile(`\{(YYYY|YY|MM|N(?::([1-9]))?)\}`)
+
+// FormatSequence renders a display number such as "REP-{YYYY}-{N:5}" as "REP-2026-00042".
+// {YYYY}, {YY} and {MM} come from at; {N} is the number and {N:k} pads it to k digits.
+func FormatSequence(format string, number int, at time.Time) string {
+	return sequenceToken.ReplaceAllStringFunc(format, func(token string) string {
+		match := sequenceToken.FindStringSubmatch(token)
+		switch match[1] {
+		case "YYYY":
+			return at.Format("2006")
+		case "YY":
+			return at.Format("06")
+		case "MM":
+			return at.Format("01")
+		}
+		if match[2] != "" {
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, number)
+		}
+		return strconv.Itoa(number)
+	})
+}
+
+// ValidateSequenceFormat checks a format for FormatSequence. It must show the number once.
+func ValidateSequenceFormat(format string) error {
+	if len(format) > maxSequenceFormatLength {
+		return fmt.Errorf("format cannot be longer than %d characters", maxSequenceFormatLength)
+	}
+
+	numbers := 0
+	for _, match := range sequenceToken.FindAllStringSubmatch(format, -1) {
+		if strings.HasPrefix(match[1], "N") {
+			numbers++
+		}
+	}
+	if numbers != 1 {
+		return fmt.Errorf("format must contain {N} or {N:k} exactly once")
+	}
+
+	if rest := sequenceToken.ReplaceAllString(format, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("format has an unknown placeholder, use {YYYY}, {YY}, {MM}, {N} or {N:k}")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssetHandler struct {
+	AssetService AssetService
+}
+
+func NewAssetHandler(assetService AssetService) *AssetHandler {
+	return &AssetHandler{
+		AssetService: assetService,
+	}
+}
+
+func (h *AssetHandler) CreateAsset(c *gin.Context) {
+	var req CreateAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.AssetService.CreateAsset(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Create asset successfully", data, 0)
+}
+
+func (h *AssetHandler) GetAssets(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.AssetService.GetAssets(ctx, AssetQuery{
+		OrganizationID: organizationID,
+		Location:       c.Query("location"),
+		Category:       c.Query("category"),
+		Search:         c.Query("q"),
+	}, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get assets successfully", data, 0)
+}
+
+func (h *AssetHandler) GetAssetByID(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.AssetService.GetAssetByID(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get asset successfully", data, 0)
+}
+
+func (h *AssetHandler) UpdateAsset(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req UpdateAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.AssetService.UpdateAsset(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update asset successfully", data, 0)
+}
+
+func (h *AssetHandler) DeleteAsset(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.AssetService.DeleteAsset(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Delete asset successfully", nil, 0)
+}
+
+func (h *AssetHandler) GetQRCode(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	payload, err := h.AssetService.GetAssetQRCode(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendQRCode(c, payload)
+}
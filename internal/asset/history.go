@@ -0,0 +1,26 @@
+package asset
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RepairRecord is a repair reported against an asset, as seen by the registry.
+type RepairRecord struct {
+	ID         primitive.ObjectID `json:"id"`
+	JobCode    string             `json:"job_code"`
+	JobName    string             `json:"job_name"`
+	Status     string             `json:"status"`
+	Open       bool               `json:"open"`
+	Preventive bool               `json:"preventive"`
+	DateReport time.Time          `json:"date_report"`
+	DateRepair *time.Time         `json:"date_repair"`
+	TotalCost  *float64           `json:"total_cost"`
+}
+
+// RepairHistory looks up the repairs reported against an asset, newest first.
+type RepairHistory interface {
+	GetAssetRepairs(ctx context.Context, assetID primitive.ObjectID) ([]RepairRecord, error)
+}
@@ -0,0 +1,40 @@
+package asset
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions a caller can take on an asset, as offered to clients that scan its QR code
+const (
+	ActionReportRepair = "report_repair"
+	ActionUpdate       = "update"
+	ActionDelete       = "delete"
+)
+
+// Asset is a piece of equipment the organization maintains, such as a projector or an AC
+// unit. Repairs can be reported against it to build up its history.
+type Asset struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Name           string             `json:"name" bson:"name"`
+	Category       string             `json:"category" bson:"category"`
+	SerialNumber   string             `json:"serial_number" bson:"serial_number"`
+	Location       string             `json:"location" bson:"location"`
+	QRCode         string             `json:"qrcode" bson:"qrcode"`
+	PurchaseDate   *time.Time         `json:"purchase_date" bson:"purchase_date"`
+	WarrantyExpiry *time.Time         `json:"warranty_expiry" bson:"warranty_expiry"`
+	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	UpdatedBy      string             `json:"updated_by" bson:"updated_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// AssetFilter narrows asset listings. Search matches the name or serial number.
+type AssetFilter struct {
+	OrganizationID string
+	Location       string
+	Category       string
+	Search         string
+}
@@ -0,0 +1,22 @@
+package asset
+
+import (
+	"context"
+	"todo-service/internal/authz"
+)
+
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+func (p *Policy) CanManageAssets(ctx context.Context, userID string) error {
+	return authz.Authorize(ctx, authz.AssetManage, userID)
+}
+
+// CanViewAssets lets the organization's members look up its assets, so they can report
+// repairs against them.
+func (p *Policy) CanViewAssets(ctx context.Context, userID string, isMember bool) error {
+	return authz.Authorize(ctx, authz.AssetView, userID, authz.When("being a member of the organization", isMember))
+}
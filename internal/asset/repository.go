@@ -0,0 +1,106 @@
+package asset
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AssetRepository interface {
+	CreateAsset(ctx context.Context, asset *Asset) error
+	GetAssets(ctx context.Context, filter AssetFilter) ([]*Asset, error)
+	GetAssetByID(ctx context.Context, id primitive.ObjectID) (*Asset, error)
+	UpdateAsset(ctx context.Context, asset *Asset) error
+	DeleteAsset(ctx context.Context, id primitive.ObjectID) error
+
+	EnsureIndexes(ctx context.Context) error
+}
+
+type assetRepository struct {
+	assetCollection *mongo.Collection
+}
+
+func NewAssetRepository(assetCollection *mongo.Collection) AssetRepository {
+	return &assetRepository{
+		assetCollection: assetCollection,
+	}
+}
+
+// EnsureIndexes makes serial numbers unique per organization. Assets without one are exempt.
+func (r *assetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.assetCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "serial_number", Value: 1}},
+			Options: options.Index().SetName("organization_serial_number").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"serial_number": bson.M{"$gt": ""}}),
+		},
+		{
+			Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "location", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *assetRepository) CreateAsset(ctx context.Context, asset *Asset) error {
+	_, err := r.assetCollection.InsertOne(ctx, asset)
+	return err
+}
+
+func (r *assetRepository) GetAssets(ctx context.Context, filter AssetFilter) ([]*Asset, error) {
+
+	query := bson.M{"organization_id": filter.OrganizationID}
+	if filter.Location != "" {
+		query["location"] = filter.Location
+	}
+	if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+	if filter.Search != "" {
+		pattern := regexp.QuoteMeta(filter.Search)
+		query["$or"] = bson.A{
+			bson.M{"name": bson.M{"$regex": pattern, "$options": "i"}},
+			bson.M{"serial_number": bson.M{"$regex": pattern, "$options": "i"}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.assetCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var assets []*Asset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (r *assetRepository) GetAssetByID(ctx context.Context, id primitive.ObjectID) (*Asset, error) {
+	var asset Asset
+	err := r.assetCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&asset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *assetRepository) UpdateAsset(ctx context.Context, asset *Asset) error {
+	_, err := r.assetCollection.ReplaceOne(ctx, bson.M{"_id": asset.ID}, asset)
+	return err
+}
+
+func (r *assetRepository) DeleteAsset(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.assetCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,29 @@
+package asset
+
+// CreateAssetRequest registers an asset. Dates take RFC 3339 or YYYY-MM-DD HH:MM:SS.
+type CreateAssetRequest struct {
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	Category       string `json:"category"`
+	SerialNumber   string `json:"serial_number"`
+	Location       string `json:"location"`
+	PurchaseDate   string `json:"purchase_date"`
+	WarrantyExpiry string `json:"warranty_expiry"`
+}
+
+// UpdateAssetRequest changes the given fields. An empty date clears it.
+type UpdateAssetRequest struct {
+	Name           *string `json:"name"`
+	Category       *string `json:"category"`
+	SerialNumber   *string `json:"serial_number"`
+	Location       *string `json:"location"`
+	PurchaseDate   *string `json:"purchase_date"`
+	WarrantyExpiry *string `json:"warranty_expiry"`
+}
+
+type AssetQuery struct {
+	OrganizationID string
+	Location       string
+	Category       string
+	Search         string
+}
@@ -0,0 +1,35 @@
+package asset
+
+import (
+	"time"
+	"todo-service/internal/location"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AssetResponse struct {
+	ID             primitive.ObjectID     `json:"id"`
+	OrganizationID string                 `json:"organization_id"`
+	Name           string                 `json:"name"`
+	Category       string                 `json:"category"`
+	SerialNumber   string                 `json:"serial_number"`
+	Location       location.LocationInfor `json:"location"`
+	QRCode         string                 `json:"qrcode"`
+	PurchaseDate   *time.Time             `json:"purchase_date"`
+	WarrantyExpiry *time.Time             `json:"warranty_expiry"`
+	UnderWarranty  bool                   `json:"under_warranty"`
+	CreatedBy      string                 `json:"created_by"`
+	UpdatedBy      string                 `json:"updated_by"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// AssetDetailResponse is an asset with its repair history. TotalCost adds up what every
+// repair of the asset has cost so far, tax included.
+type AssetDetailResponse struct {
+	Asset       *AssetResponse `json:"asset"`
+	Repairs     []RepairRecord `json:"repairs"`
+	RepairCount int            `json:"repair_count"`
+	OpenRepairs int            `json:"open_repairs"`
+	TotalCost   float64        `json:"total_cost"`
+}
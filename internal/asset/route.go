@@ -0,0 +1,19 @@
+package asset
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, assetHandler *AssetHandler) {
+	assetGroup := r.Group("/api/v1/assets", middleware.Secured())
+	{
+		assetGroup.POST("", assetHandler.CreateAsset)
+		assetGroup.GET("", assetHandler.GetAssets)
+		assetGroup.GET("/:id", assetHandler.GetAssetByID)
+		assetGroup.PUT("/:id", assetHandler.UpdateAsset)
+		assetGroup.DELETE("/:id", assetHandler.DeleteAsset)
+		assetGroup.GET("/:id/qrcode", assetHandler.GetQRCode)
+	}
+}
@@ -0,0 +1,402 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/authz"
+	"todo-service/internal/location"
+	"todo-service/internal/setting"
+	"todo-service/internal/user"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AssetService interface {
+	CreateAsset(ctx context.Context, req CreateAssetRequest, userID string) (*AssetResponse, error)
+	GetAssets(ctx context.Context, query AssetQuery, userID string) ([]*AssetResponse, error)
+	GetAssetByID(ctx context.Context, id string, userID string) (*AssetDetailResponse, error)
+	UpdateAsset(ctx context.Context, id string, req UpdateAssetRequest, userID string) (*AssetResponse, error)
+	DeleteAsset(ctx context.Context, id string, userID string) error
+	GetAssetQRCode(ctx context.Context, id string, userID string) (string, error)
+	GetAssetActions(ctx context.Context, id string, userID string) ([]string, error)
+}
+
+type assetService struct {
+	AssetRepo       AssetRepository
+	RepairHistory   RepairHistory
+	LocationService location.LocationService
+	UserService     user.UserService
+	SettingService  setting.SettingService
+	Policy          *Policy
+}
+
+func NewAssetService(
+	AssetRepo AssetRepository,
+	RepairHistory RepairHistory,
+	LocationService location.LocationService,
+	UserService user.UserService,
+	SettingService setting.SettingService,
+) AssetService {
+	return &assetService{
+		AssetRepo:       AssetRepo,
+		RepairHistory:   RepairHistory,
+		LocationService: LocationService,
+		UserService:     UserService,
+		SettingService:  SettingService,
+		Policy:          NewPolicy(),
+	}
+}
+
+func (s *assetService) CreateAsset(ctx context.Context, req CreateAssetRequest, userID string) (*AssetResponse, error) {
+
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanManageAssets(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, req.OrganizationID)
+
+	purchaseDate, err := parseDate("purchase_date", req.PurchaseDate, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	warrantyExpiry, err := parseDate("warranty_expiry", req.WarrantyExpiry, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	now := time.Now().UTC()
+
+	asset := &Asset{
+		ID:             id,
+		OrganizationID: req.OrganizationID,
+		Name:           strings.TrimSpace(req.Name),
+		Category:       strings.ToLower(strings.TrimSpace(req.Category)),
+		SerialNumber:   strings.TrimSpace(req.SerialNumber),
+		Location:       req.Location,
+		QRCode:         fmt.Sprintf("SENBOX.ORG[ASSET]:%s", id.Hex()),
+		PurchaseDate:   purchaseDate,
+		WarrantyExpiry: warrantyExpiry,
+		CreatedBy:      userID,
+		UpdatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.validateAsset(ctx, asset, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.AssetRepo.CreateAsset(ctx, asset); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, helper.Conflict("serial number %s is already registered", asset.SerialNumber)
+		}
+		return nil, err
+	}
+
+	return s.buildAssetResponse(ctx, asset, loc, map[string]*location.LocationInfor{}), nil
+}
+
+func (s *assetService) GetAssets(ctx context.Context, query AssetQuery, userID string) ([]*AssetResponse, error) {
+
+	if query.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewAssets(ctx, userID, s.isOrganizationMember(ctx, userID, query.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	assets, err := s.AssetRepo.GetAssets(ctx, AssetFilter{
+		OrganizationID: query.OrganizationID,
+		Location:       query.Location,
+		Category:       strings.ToLower(strings.TrimSpace(query.Category)),
+		Search:         strings.TrimSpace(query.Search),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, query.OrganizationID)
+	locations := map[string]*location.LocationInfor{}
+
+	results := make([]*AssetResponse, 0, len(assets))
+	for _, asset := range assets {
+		results = append(results, s.buildAssetResponse(ctx, asset, loc, locations))
+	}
+
+	return results, nil
+}
+
+// GetAssetByID returns the asset with every repair reported against it and what they cost.
+func (s *assetService) GetAssetByID(ctx context.Context, id string, userID string) (*AssetDetailResponse, error) {
+
+	asset, err := s.getAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanViewAssets(ctx, userID, s.isOrganizationMember(ctx, userID, asset.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	repairs, err := s.RepairHistory.GetAssetRepairs(ctx, asset.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repair history: %v", err)
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, asset.OrganizationID)
+
+	response := &AssetDetailResponse{
+		Asset:       s.buildAssetResponse(ctx, asset, loc, map[string]*location.LocationInfor{}),
+		Repairs:     make([]RepairRecord, 0, len(repairs)),
+		RepairCount: len(repairs),
+	}
+
+	for _, repair := range repairs {
+		repair.DateReport = repair.DateReport.In(loc)
+		repair.DateRepair = helper.InLocation(repair.DateRepair, loc)
+		response.Repairs = append(response.Repairs, repair)
+
+		if repair.Open {
+			response.OpenRepairs++
+		}
+		if repair.TotalCost != nil {
+			response.TotalCost += *repair.TotalCost
+		}
+	}
+	response.TotalCost = math.Round(response.TotalCost*100) / 100
+
+	return response, nil
+}
+
+func (s *assetService) UpdateAsset(ctx context.Context, id string, req UpdateAssetRequest, userID string) (*AssetResponse, error) {
+
+	asset, err := s.getAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanManageAssets(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, asset.OrganizationID)
+	locationChanged := false
+
+	if req.Name != nil {
+		asset.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Category != nil {
+		asset.Category = strings.ToLower(strings.TrimSpace(*req.Category))
+	}
+	if req.SerialNumber != nil {
+		asset.SerialNumber = strings.TrimSpace(*req.SerialNumber)
+	}
+	if req.Location != nil && *req.Location != asset.Location {
+		asset.Location = *req.Location
+		locationChanged = true
+	}
+	if req.PurchaseDate != nil {
+		if asset.PurchaseDate, err = parseDate("purchase_date", *req.PurchaseDate, loc); err != nil {
+			return nil, err
+		}
+	}
+	if req.WarrantyExpiry != nil {
+		if asset.WarrantyExpiry, err = parseDate("warranty_expiry", *req.WarrantyExpiry, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validateAsset(ctx, asset, locationChanged); err != nil {
+		return nil, err
+	}
+
+	asset.UpdatedBy = userID
+	asset.UpdatedAt = time.Now().UTC()
+
+	if err := s.AssetRepo.UpdateAsset(ctx, asset); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, helper.Conflict("serial number %s is already registered", asset.SerialNumber)
+		}
+		return nil, err
+	}
+
+	return s.buildAssetResponse(ctx, asset, loc, map[string]*location.LocationInfor{}), nil
+}
+
+// DeleteAsset removes an asset that was never repaired. One with a history is kept, since
+// its repairs would otherwise point to nothing.
+func (s *assetService) DeleteAsset(ctx context.Context, id string, userID string) error {
+
+	asset, err := s.getAsset(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Policy.CanManageAssets(ctx, userID); err != nil {
+		return err
+	}
+
+	repairs, err := s.RepairHistory.GetAssetRepairs(ctx, asset.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get repair history: %v", err)
+	}
+	if len(repairs) > 0 {
+		return fmt.Errorf("the asset has %d repairs and cannot be deleted", len(repairs))
+	}
+
+	return s.AssetRepo.DeleteAsset(ctx, asset.ID)
+}
+
+func (s *assetService) GetAssetQRCode(ctx context.Context, id string, userID string) (string, error) {
+
+	asset, err := s.getAsset(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.Policy.CanViewAssets(ctx, userID, s.isOrganizationMember(ctx, userID, asset.OrganizationID)); err != nil {
+		return "", err
+	}
+
+	return asset.QRCode, nil
+}
+
+// GetAssetActions lists what the user may do with the asset. Anyone who may see it may
+// report a repair against it.
+func (s *assetService) GetAssetActions(ctx context.Context, id string, userID string) ([]string, error) {
+
+	asset, err := s.getAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanViewAssets(ctx, userID, s.isOrganizationMember(ctx, userID, asset.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	actions := []string{ActionReportRepair}
+	if s.Policy.CanManageAssets(authz.Probe(ctx), userID) == nil {
+		actions = append(actions, ActionUpdate, ActionDelete)
+	}
+
+	return actions, nil
+}
+
+func (s *assetService) getAsset(ctx context.Context, id string) (*Asset, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset id")
+	}
+
+	asset, err := s.AssetRepo.GetAssetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if asset == nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	return asset, nil
+}
+
+// validateAsset checks the asset's fields. The location is looked up only when it is new,
+// since the call goes to another service.
+func (s *assetService) validateAsset(ctx context.Context, asset *Asset, checkLocation bool) error {
+
+	if asset.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if asset.Location == "" {
+		return fmt.Errorf("location is required")
+	}
+
+	if asset.PurchaseDate != nil && asset.WarrantyExpiry != nil && asset.WarrantyExpiry.Before(*asset.PurchaseDate) {
+		return fmt.Errorf("warranty_expiry cannot be before purchase_date")
+	}
+
+	if checkLocation {
+		if _, err := s.LocationService.GetLocationByID(ctx, asset.Location); err != nil {
+			return fmt.Errorf("location %s not found: %v", asset.Location, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *assetService) buildAssetResponse(ctx context.Context, asset *Asset, loc *time.Location, locations map[string]*location.LocationInfor) *AssetResponse {
+
+	response := &AssetResponse{
+		ID:             asset.ID,
+		OrganizationID: asset.OrganizationID,
+		Name:           asset.Name,
+		Category:       asset.Category,
+		SerialNumber:   asset.SerialNumber,
+		QRCode:         asset.QRCode,
+		PurchaseDate:   helper.InLocation(asset.PurchaseDate, loc),
+		WarrantyExpiry: helper.InLocation(asset.WarrantyExpiry, loc),
+		UnderWarranty:  asset.WarrantyExpiry != nil && asset.WarrantyExpiry.After(time.Now()),
+		CreatedBy:      asset.CreatedBy,
+		UpdatedBy:      asset.UpdatedBy,
+		CreatedAt:      asset.CreatedAt.In(loc),
+		UpdatedAt:      asset.UpdatedAt.In(loc),
+	}
+
+	info, ok := locations[asset.Location]
+	if !ok {
+		var err error
+		info, err = s.LocationService.GetLocationByID(ctx, asset.Location)
+		if err != nil {
+			log.Printf("[WARN] failed to get location %s of asset %s: %v", asset.Location, asset.ID.Hex(), err)
+			info = nil
+		}
+		locations[asset.Location] = info
+	}
+	if info != nil {
+		response.Location = *info
+	}
+
+	return response
+}
+
+// isOrganizationMember reports whether the user is a teacher or staff member of the organization.
+func (s *assetService) isOrganizationMember(ctx context.Context, userID, organizationID string) bool {
+	teacher, err := s.UserService.GetTeacherInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check teacher %s in organization %s: %v", userID, organizationID, err)
+	} else if teacher != nil && teacher.UserID != "" {
+		return true
+	}
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check staff %s in organization %s: %v", userID, organizationID, err)
+		return false
+	}
+	return staff != nil && staff.UserID != ""
+}
+
+// parseDate reads an optional date field; an empty value means none.
+func parseDate(field, value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := helper.ParseDateTime(value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format: %v", field, err)
+	}
+	return &parsed, nil
+}
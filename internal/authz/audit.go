@@ -0,0 +1,69 @@
+package authz
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Decision struct {
+	Action   Action    `json:"action"`
+	UserID   string    `json:"user_id"`
+	Roles    []string  `json:"roles"`
+	Verified bool      `json:"verified"`
+	Allowed  bool      `json:"allowed"`
+	Reason   string    `json:"reason"`
+	At       time.Time `json:"at"`
+}
+
+// DecisionLogger receives every authorization decision for auditing.
+type DecisionLogger interface {
+	LogDecision(ctx context.Context, decision Decision)
+}
+
+type stdDecisionLogger struct{}
+
+func (stdDecisionLogger) LogDecision(ctx context.Context, d Decision) {
+	result := "deny"
+	if d.Allowed {
+		result = "allow"
+	}
+	log.Printf("[AUTHZ] %s action=%s user=%s roles=[%s] verified=%t reason=%q",
+		result, d.Action, d.UserID, strings.Join(d.Roles, ","), d.Verified, d.Reason)
+}
+
+var (
+	loggerMu       sync.RWMutex
+	decisionLogger DecisionLogger = stdDecisionLogger{}
+)
+
+// SetDecisionLogger replaces the default log-based audit sink. Passing nil silences auditing.
+func SetDecisionLogger(logger DecisionLogger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	decisionLogger = logger
+}
+
+type probeKey struct{}
+
+// Probe marks ctx as checking which actions a user could take, without taking any.
+// Decisions made under it are not audited.
+func Probe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, probeKey{}, true)
+}
+
+func logDecision(ctx context.Context, decision Decision) {
+	if probing, _ := ctx.Value(probeKey{}).(bool); probing {
+		return
+	}
+
+	loggerMu.RLock()
+	logger := decisionLogger
+	loggerMu.RUnlock()
+
+	if logger != nil {
+		logger.LogDecision(ctx, decision)
+	}
+}
@@ -0,0 +1,128 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeniedError is returned when a caller may not perform an action. Handlers answer it with 403.
+type DeniedError struct {
+	Action Action
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return e.Reason
+}
+
+func IsDenied(err error) bool {
+	var denied *DeniedError
+	return errors.As(err, &denied)
+}
+
+// Deny builds a DeniedError for checks that do not fit the role/rule model.
+func Deny(action Action, format string, args ...interface{}) error {
+	return &DeniedError{Action: action, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Rule is an ownership or membership predicate evaluated against the caller.
+type Rule struct {
+	Name  string
+	Match func(userID string) bool
+}
+
+// Owner matches when the caller is ownerID. name describes the relation, e.g. "the reporter".
+func Owner(name, ownerID string) Rule {
+	return Rule{
+		Name: name,
+		Match: func(userID string) bool {
+			return ownerID != "" && userID == ownerID
+		},
+	}
+}
+
+// MemberOf matches when the caller is one of ids.
+func MemberOf(name string, ids []string) Rule {
+	return Rule{
+		Name: name,
+		Match: func(userID string) bool {
+			for _, id := range ids {
+				if id != "" && id == userID {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// When matches when ok holds, for conditions that do not depend on the caller.
+func When(name string, ok bool) Rule {
+	return Rule{
+		Name: name,
+		Match: func(string) bool {
+			return ok
+		},
+	}
+}
+
+// Authorize allows the action when one of the caller's token roles is granted it,
+// or when any of the rules matches the caller. Roles count only when the token
+// signature was verified. Every decision is audited.
+func Authorize(ctx context.Context, action Action, userID string, rules ...Rule) error {
+	claims := FromContext(ctx)
+
+	decision := Decision{
+		Action: action,
+		UserID: userID,
+		At:     time.Now(),
+	}
+	if claims != nil {
+		decision.Roles = claims.Roles
+		decision.Verified = claims.Verified
+	}
+
+	for _, role := range rolePermissions[action] {
+		if claims != nil && claims.Verified && claims.HasRole(role) {
+			decision.Allowed = true
+			decision.Reason = "role " + role
+			logDecision(ctx, decision)
+			return nil
+		}
+	}
+
+	if userID != "" {
+		for _, rule := range rules {
+			if rule.Match != nil && rule.Match(userID) {
+				decision.Allowed = true
+				decision.Reason = rule.Name
+				logDecision(ctx, decision)
+				return nil
+			}
+		}
+	}
+
+	decision.Reason = describeRequirement(action, rules)
+	logDecision(ctx, decision)
+
+	return &DeniedError{Action: action, Reason: decision.Reason}
+}
+
+func describeRequirement(action Action, rules []Rule) string {
+	var options []string
+	for _, role := range rolePermissions[action] {
+		options = append(options, "role "+role)
+	}
+	for _, rule := range rules {
+		options = append(options, rule.Name)
+	}
+
+	if len(options) == 0 {
+		return fmt.Sprintf("%s is not permitted", action)
+	}
+
+	return fmt.Sprintf("%s requires %s", action, strings.Join(options, " or "))
+}
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"context"
+	"strings"
+	"todo-service/pkg/constants"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the caller identity the middleware extracted from the bearer token.
+type Claims struct {
+	UserID string
+	Roles  []string
+	// Verified is set once the token signature was checked. Roles of an
+	// unverified token are never honored.
+	Verified bool
+}
+
+// NewClaims reads the user id and roles from raw token claims. Roles may be
+// a JSON array or the legacy comma-separated string.
+func NewClaims(raw jwt.MapClaims, verified bool) *Claims {
+	claims := &Claims{Verified: verified}
+
+	if userID, ok := raw[constants.UserID].(string); ok {
+		claims.UserID = userID
+	}
+
+	switch roles := raw["roles"].(type) {
+	case string:
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				claims.Roles = append(claims.Roles, role)
+			}
+		}
+	case []interface{}:
+		for _, item := range roles {
+			if role, ok := item.(string); ok && strings.TrimSpace(role) != "" {
+				claims.Roles = append(claims.Roles, strings.TrimSpace(role))
+			}
+		}
+	}
+
+	return claims
+}
+
+func (c *Claims) HasRole(role string) bool {
+	if c == nil {
+		return false
+	}
+	for _, r := range c.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromContext returns the claims stored by middleware.Secured, or nil outside a request.
+func FromContext(ctx context.Context) *Claims {
+	if ctx == nil {
+		return nil
+	}
+	claims, _ := ctx.Value(constants.Claims).(*Claims)
+	return claims
+}
@@ -0,0 +1,80 @@
+package authz
+
+type Action string
+
+const (
+	RoleAdmin = "Admin"
+)
+
+const (
+	TodoUpdate         Action = "todo.update"
+	TodoUpdateStage    Action = "todo.update_stage"
+	TodoUpdateProgress Action = "todo.update_progress"
+	TodoUpdatePictures Action = "todo.update_pictures"
+	TodoDelete         Action = "todo.delete"
+	TodoManageMembers  Action = "todo.manage_members"
+	TodoViewProgress   Action = "todo.view_progress"
+	TodoViewStats      Action = "todo.view_stats"
+
+	RepairUpdate    Action = "repair.update"
+	RepairDelete    Action = "repair.delete"
+	RepairAssign    Action = "repair.assign"
+	RepairComplete  Action = "repair.complete"
+	RepairWork      Action = "repair.work"
+	RepairVerify    Action = "repair.verify"
+	RepairReopen    Action = "repair.reopen"
+	RepairVote      Action = "repair.vote"
+	RepairManageSLA Action = "repair.manage_sla"
+	RepairViewSLA   Action = "repair.view_sla"
+	RepairCosts     Action = "repair.manage_costs"
+	RepairPlans     Action = "repair.manage_plans"
+	RepairReport    Action = "repair.report"
+
+	TaskUpdate       Action = "task.update"
+	TaskDelete       Action = "task.delete"
+	TaskUpdateStatus Action = "task.update_status"
+
+	ShopUpdate    Action = "shop.update"
+	ShopDelete    Action = "shop.delete"
+	ProductManage Action = "shop.product_manage"
+
+	BoardManage   Action = "board.manage"
+	SettingUpdate Action = "setting.update"
+	SettingView   Action = "setting.view"
+
+	TimesheetManage Action = "timesheet.manage"
+	TimesheetReport Action = "timesheet.report"
+
+	AssetManage Action = "asset.manage"
+	AssetView   Action = "asset.view"
+
+	LabelPrint Action = "label.print"
+)
+
+// rolePermissions lists the token roles that are granted an action outright.
+// Actions missing here are only allowed through the rules passed to Authorize.
+var rolePermissions = map[Action][]string{
+	TodoDelete:       {RoleAdmin},
+	TodoViewStats:    {RoleAdmin},
+	RepairAssign:     {RoleAdmin},
+	RepairWork:       {RoleAdmin},
+	RepairVerify:     {RoleAdmin},
+	RepairReopen:     {RoleAdmin},
+	RepairVote:       {RoleAdmin},
+	RepairManageSLA:  {RoleAdmin},
+	RepairViewSLA:    {RoleAdmin},
+	RepairCosts:      {RoleAdmin},
+	RepairPlans:      {RoleAdmin},
+	RepairReport:     {RoleAdmin},
+	TaskUpdate:       {RoleAdmin},
+	TaskDelete:       {RoleAdmin},
+	TaskUpdateStatus: {RoleAdmin},
+	BoardManage:      {RoleAdmin},
+	SettingUpdate:    {RoleAdmin},
+	SettingView:      {RoleAdmin},
+	TimesheetManage:  {RoleAdmin},
+	TimesheetReport:  {RoleAdmin},
+	AssetManage:      {RoleAdmin},
+	AssetView:        {RoleAdmin},
+	LabelPrint:       {RoleAdmin},
+}
@@ -0,0 +1,213 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BoardHandler struct {
+	BoardService BoardService
+}
+
+func NewBoardHandler(boardService BoardService) *BoardHandler {
+	return &BoardHandler{
+		BoardService: boardService,
+	}
+}
+
+func (h *BoardHandler) CreateBoard(c *gin.Context) {
+	var req CreateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.BoardService.CreateBoard(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Create board successfully", data, 0)
+}
+
+func (h *BoardHandler) GetBoardByOrganization(c *gin.Context) {
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.BoardService.GetBoardByOrganization(ctx, organizationID, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get board successfully", data, 0)
+}
+
+func (h *BoardHandler) GetBoardByID(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.BoardService.GetBoardByID(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get board successfully", data, 0)
+}
+
+func (h *BoardHandler) UpdateBoard(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req UpdateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.BoardService.UpdateBoard(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update board successfully", nil, 0)
+}
+
+func (h *BoardHandler) DeleteBoard(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.BoardService.DeleteBoard(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Delete board successfully", nil, 0)
+}
+
+func (h *BoardHandler) MoveCard(c *gin.Context) {
+	id := c.Param("id")
+	todoID := c.Param("todo_id")
+	if id == "" || todoID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and todo_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req MoveCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.BoardService.MoveCard(ctx, id, todoID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Move card successfully", nil, 0)
+}
@@ -0,0 +1,66 @@
+package board
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Board struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Name           string             `json:"name" bson:"name"`
+	Columns        []Column           `json:"columns" bson:"columns"`
+	DefaultStage   string             `json:"default_stage" bson:"default_stage"`
+	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Column maps one todo stage onto the board. Columns are displayed by Order.
+type Column struct {
+	Stage    string `json:"stage" bson:"stage"`
+	Name     string `json:"name" bson:"name"`
+	Order    int    `json:"order" bson:"order"`
+	WIPLimit *int   `json:"wip_limit" bson:"wip_limit"`
+}
+
+// Card is the board's view of a todo document.
+type Card struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Name           string             `json:"name" bson:"name"`
+	Status         string             `json:"status" bson:"status"`
+	Progress       int                `json:"progress" bson:"progress"`
+	Urgent         bool               `json:"urgent" bson:"urgent"`
+	Stage          *string            `json:"stage" bson:"stage"`
+	Position       float64            `json:"position" bson:"position"`
+	DueDate        time.Time          `json:"due_date" bson:"due_date"`
+	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	Members        CardMembers        `json:"-" bson:"task_users"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CardMembers mirrors todo.TaskUsers so moves can be checked against the todo's members.
+type CardMembers struct {
+	Teachers []string `bson:"teachers"`
+	Students []string `bson:"students"`
+	Staffs   []string `bson:"staffs"`
+}
+
+// CardPosition is one card's new place in its column.
+type CardPosition struct {
+	ID       primitive.ObjectID
+	Position float64
+}
+
+// positionStep is the gap left between neighbouring cards so most moves touch one document.
+const positionStep = 1024.0
+
+// Card moves hold a lease on their board. A move that finds it taken retries briefly before
+// giving up.
+const (
+	moveLockLease    = 10 * time.Second
+	moveLockAttempts = 20
+	moveLockWait     = 50 * time.Millisecond
+)
@@ -0,0 +1,215 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BoardRepository interface {
+	CreateBoard(ctx context.Context, board *Board) error
+	GetBoardByID(ctx context.Context, id primitive.ObjectID) (*Board, error)
+	GetBoardByOrganization(ctx context.Context, organizationID string) (*Board, error)
+	UpdateBoard(ctx context.Context, board *Board) error
+	DeleteBoard(ctx context.Context, id primitive.ObjectID) error
+	LockMoves(ctx context.Context, id primitive.ObjectID, token primitive.ObjectID, now, until time.Time) (bool, error)
+	UnlockMoves(ctx context.Context, id primitive.ObjectID, token primitive.ObjectID) error
+
+	// Cards live in the todo collection
+	GetCards(ctx context.Context, organizationID string, stage string, isDefault bool) ([]*Card, error)
+	CountCards(ctx context.Context, organizationID string, stage string, isDefault bool) (int, error)
+	GetCardByID(ctx context.Context, todoID primitive.ObjectID) (*Card, error)
+	GetMaxPosition(ctx context.Context, organizationID string, stage string, isDefault bool) (float64, error)
+	MoveCard(ctx context.Context, todoID primitive.ObjectID, stage string, position float64) error
+	SetCardPositions(ctx context.Context, positions []CardPosition) error
+}
+
+type boardRepository struct {
+	boardCollection *mongo.Collection
+	todoCollection  *mongo.Collection
+}
+
+func NewBoardRepository(boardCollection, todoCollection *mongo.Collection) BoardRepository {
+	return &boardRepository{
+		boardCollection: boardCollection,
+		todoCollection:  todoCollection,
+	}
+}
+
+func (r *boardRepository) CreateBoard(ctx context.Context, board *Board) error {
+	_, err := r.boardCollection.InsertOne(ctx, board)
+	return err
+}
+
+func (r *boardRepository) GetBoardByID(ctx context.Context, id primitive.ObjectID) (*Board, error) {
+	var board Board
+	err := r.boardCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&board)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (r *boardRepository) GetBoardByOrganization(ctx context.Context, organizationID string) (*Board, error) {
+	var board Board
+	err := r.boardCollection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&board)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (r *boardRepository) UpdateBoard(ctx context.Context, board *Board) error {
+	_, err := r.boardCollection.UpdateOne(ctx, bson.M{"_id": board.ID}, bson.M{"$set": board})
+	return err
+}
+
+func (r *boardRepository) DeleteBoard(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.boardCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// LockMoves takes a short lease on the board so that card moves run one at a time. It
+// reports false while another move holds the lease. A lease that is never released, e.g.
+// after a crash, runs out at until.
+func (r *boardRepository) LockMoves(ctx context.Context, id primitive.ObjectID, token primitive.ObjectID, now, until time.Time) (bool, error) {
+	result, err := r.boardCollection.UpdateOne(ctx, bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"move_lock.until": nil},
+			{"move_lock.until": bson.M{"$lte": now}},
+		},
+	}, bson.M{"$set": bson.M{"move_lock": bson.M{"token": token, "until": until}}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// UnlockMoves releases the lease, unless it ran out and was taken by another move since.
+func (r *boardRepository) UnlockMoves(ctx context.Context, id primitive.ObjectID, token primitive.ObjectID) error {
+	_, err := r.boardCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "move_lock.token": token},
+		bson.M{"$unset": bson.M{"move_lock": ""}})
+	return err
+}
+
+// stageFilter matches the todos shown in a column. The default column also
+// collects todos that were created without a stage.
+func stageFilter(organizationID string, stage string, isDefault bool) bson.M {
+	filter := bson.M{"organization_id": organizationID}
+	if isDefault {
+		filter["$or"] = []bson.M{
+			{"stage": stage},
+			{"stage": nil},
+			{"stage": ""},
+		}
+	} else {
+		filter["stage"] = stage
+	}
+	return filter
+}
+
+func (r *boardRepository) GetCards(ctx context.Context, organizationID string, stage string, isDefault bool) ([]*Card, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.todoCollection.Find(ctx, stageFilter(organizationID, stage, isDefault), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	cards := []*Card{}
+	for cursor.Next(ctx) {
+		var card Card
+		if err := cursor.Decode(&card); err != nil {
+			return nil, err
+		}
+		cards = append(cards, &card)
+	}
+
+	return cards, cursor.Err()
+}
+
+func (r *boardRepository) CountCards(ctx context.Context, organizationID string, stage string, isDefault bool) (int, error) {
+	count, err := r.todoCollection.CountDocuments(ctx, stageFilter(organizationID, stage, isDefault))
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *boardRepository) GetCardByID(ctx context.Context, todoID primitive.ObjectID) (*Card, error) {
+	var card Card
+	err := r.todoCollection.FindOne(ctx, bson.M{"_id": todoID}).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (r *boardRepository) GetMaxPosition(ctx context.Context, organizationID string, stage string, isDefault bool) (float64, error) {
+
+	opts := options.FindOne().SetSort(bson.M{"position": -1})
+
+	var card Card
+	err := r.todoCollection.FindOne(ctx, stageFilter(organizationID, stage, isDefault), opts).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return card.Position, nil
+}
+
+// MoveCard sets stage and position in a single document update, so a card is never
+// observed in its new column with its old position.
+func (r *boardRepository) MoveCard(ctx context.Context, todoID primitive.ObjectID, stage string, position float64) error {
+	result, err := r.todoCollection.UpdateOne(ctx, bson.M{"_id": todoID}, bson.M{
+		"$set": bson.M{
+			"stage":    stage,
+			"position": position,
+		},
+		"$currentDate": bson.M{"updated_at": true},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("card not found")
+	}
+	return nil
+}
+
+// SetCardPositions writes the positions in the given order and stops at the first failure.
+func (r *boardRepository) SetCardPositions(ctx context.Context, positions []CardPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(positions))
+	for _, position := range positions {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": position.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position.Position}}))
+	}
+
+	_, err := r.todoCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	return err
+}
@@ -0,0 +1,26 @@
+package board
+
+type ColumnRequest struct {
+	Stage    string `json:"stage"`
+	Name     string `json:"name"`
+	WIPLimit *int   `json:"wip_limit"`
+}
+
+type CreateBoardRequest struct {
+	OrganizationID string          `json:"organization_id"`
+	Name           string          `json:"name"`
+	Columns        []ColumnRequest `json:"columns"`
+	DefaultStage   string          `json:"default_stage"`
+}
+
+type UpdateBoardRequest struct {
+	Name         *string          `json:"name"`
+	Columns      *[]ColumnRequest `json:"columns"`
+	DefaultStage *string          `json:"default_stage"`
+}
+
+// MoveCardRequest places a card in Stage at the zero-based Index among the column's other cards.
+type MoveCardRequest struct {
+	Stage string `json:"stage"`
+	Index int    `json:"index"`
+}
@@ -0,0 +1,27 @@
+package board
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BoardResponse struct {
+	ID             primitive.ObjectID `json:"id"`
+	OrganizationID string             `json:"organization_id"`
+	Name           string             `json:"name"`
+	DefaultStage   string             `json:"default_stage"`
+	Columns        []ColumnResponse   `json:"columns"`
+	CreatedBy      string             `json:"created_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type ColumnResponse struct {
+	Stage     string `json:"stage"`
+	Name      string `json:"name"`
+	Order     int    `json:"order"`
+	WIPLimit  *int   `json:"wip_limit"`
+	CardCount int    `json:"card_count"`
+	Cards     []Card `json:"cards"`
+}
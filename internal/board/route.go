@@ -0,0 +1,20 @@
+package board
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, boardHandler *BoardHandler) {
+	boardGroup := r.Group("/api/v1/boards", middleware.Secured())
+	{
+		boardGroup.POST("", boardHandler.CreateBoard)
+		boardGroup.GET("", boardHandler.GetBoardByOrganization)
+		boardGroup.GET("/:id", boardHandler.GetBoardByID)
+		boardGroup.PUT("/:id", boardHandler.UpdateBoard)
+		boardGroup.DELETE("/:id", boardHandler.DeleteBoard)
+
+		boardGroup.POST("/:id/cards/:todo_id/move", boardHandler.MoveCard)
+	}
+}
@@ -0,0 +1,655 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/authz"
+	"todo-service/internal/setting"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BoardService interface {
+	CreateBoard(ctx context.Context, req CreateBoardRequest, userID string) (*string, error)
+	GetBoardByID(ctx context.Context, id string, userID string) (*BoardResponse, error)
+	GetBoardByOrganization(ctx context.Context, organizationID string, userID string) (*BoardResponse, error)
+	UpdateBoard(ctx context.Context, id string, req UpdateBoardRequest, userID string) error
+	DeleteBoard(ctx context.Context, id string, userID string) error
+	MoveCard(ctx context.Context, boardID, todoID string, req MoveCardRequest, userID string) error
+
+	// Used by the todo module to keep Todo.Stage on the board
+	ResolveStage(ctx context.Context, organizationID string, stage *string) (*string, error)
+	AddCard(ctx context.Context, organizationID string, stage *string, create func(stage *string, position float64) error) error
+	ChangeStage(ctx context.Context, organizationID string, todoID primitive.ObjectID, stage *string) error
+}
+
+type boardService struct {
+	BoardRepo      BoardRepository
+	SettingService setting.SettingService
+}
+
+func NewBoardService(boardRepo BoardRepository, settingService setting.SettingService) BoardService {
+	return &boardService{
+		BoardRepo:      boardRepo,
+		SettingService: settingService,
+	}
+}
+
+func (s *boardService) CreateBoard(ctx context.Context, req CreateBoardRequest, userID string) (*string, error) {
+
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if err := authz.Authorize(ctx, authz.BoardManage, userID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.BoardRepo.GetBoardByOrganization(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, fmt.Errorf("organization already has a board")
+	}
+
+	columns, err := buildColumns(req.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultStage, err := resolveDefaultStage(columns, req.DefaultStage)
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+
+	board := &Board{
+		ID:             id,
+		OrganizationID: req.OrganizationID,
+		Name:           req.Name,
+		Columns:        columns,
+		DefaultStage:   defaultStage,
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	err = s.BoardRepo.CreateBoard(ctx, board)
+	if err != nil {
+		return nil, err
+	}
+
+	idParse := id.Hex()
+
+	return &idParse, nil
+}
+
+func (s *boardService) GetBoardByID(ctx context.Context, id string, userID string) (*BoardResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := s.BoardRepo.GetBoardByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, fmt.Errorf("board not found")
+	}
+
+	return s.buildBoardResponse(ctx, board, userID)
+}
+
+func (s *boardService) GetBoardByOrganization(ctx context.Context, organizationID string, userID string) (*BoardResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	board, err := s.BoardRepo.GetBoardByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, fmt.Errorf("board not found")
+	}
+
+	return s.buildBoardResponse(ctx, board, userID)
+}
+
+func (s *boardService) UpdateBoard(ctx context.Context, id string, req UpdateBoardRequest, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	board, err := s.BoardRepo.GetBoardByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return fmt.Errorf("board not found")
+	}
+
+	if err := authz.Authorize(ctx, authz.BoardManage, userID, authz.Owner("being the board creator", board.CreatedBy)); err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return fmt.Errorf("name cannot be empty")
+		}
+		board.Name = *req.Name
+	}
+
+	if req.Columns != nil {
+		columns, err := buildColumns(*req.Columns)
+		if err != nil {
+			return err
+		}
+
+		// A column can only be dropped once its cards have been moved elsewhere
+		kept := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			kept[column.Stage] = true
+		}
+		for _, column := range board.Columns {
+			if kept[column.Stage] {
+				continue
+			}
+			count, err := s.BoardRepo.CountCards(ctx, board.OrganizationID, column.Stage, false)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("column %q still has %d card(s)", column.Stage, count)
+			}
+		}
+
+		board.Columns = columns
+	}
+
+	defaultStage := board.DefaultStage
+	if req.DefaultStage != nil {
+		defaultStage = *req.DefaultStage
+	}
+
+	board.DefaultStage, err = resolveDefaultStage(board.Columns, defaultStage)
+	if err != nil {
+		return err
+	}
+
+	board.UpdatedAt = time.Now()
+
+	return s.BoardRepo.UpdateBoard(ctx, board)
+}
+
+func (s *boardService) DeleteBoard(ctx context.Context, id string, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	board, err := s.BoardRepo.GetBoardByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return fmt.Errorf("board not found")
+	}
+
+	if err := authz.Authorize(ctx, authz.BoardManage, userID, authz.Owner("being the board creator", board.CreatedBy)); err != nil {
+		return err
+	}
+
+	return s.BoardRepo.DeleteBoard(ctx, objectID)
+}
+
+func (s *boardService) MoveCard(ctx context.Context, boardID, todoID string, req MoveCardRequest, userID string) error {
+
+	if req.Stage == "" {
+		return fmt.Errorf("stage is required")
+	}
+
+	if req.Index < 0 {
+		return fmt.Errorf("index must be greater than or equal to 0")
+	}
+
+	boardObjectID, err := primitive.ObjectIDFromHex(boardID)
+	if err != nil {
+		return err
+	}
+
+	todoObjectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return err
+	}
+
+	board, err := s.BoardRepo.GetBoardByID(ctx, boardObjectID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return fmt.Errorf("board not found")
+	}
+
+	column := findColumn(board.Columns, req.Stage)
+	if column == nil {
+		return fmt.Errorf("stage %q is not a column on this board", req.Stage)
+	}
+
+	// Moves run one at a time per board, so the WIP count below cannot go stale before the
+	// card lands, and two rebalances of a column never interleave.
+	token, err := s.lockMoves(ctx, board.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlockMoves(board.ID, token)
+
+	card, err := s.BoardRepo.GetCardByID(ctx, todoObjectID)
+	if err != nil {
+		return err
+	}
+
+	if card == nil || card.OrganizationID != board.OrganizationID {
+		return fmt.Errorf("card not found on this board")
+	}
+
+	// Moving a card changes the todo's stage, so it follows the todo policy for stage edits
+	if err := authz.Authorize(ctx, authz.TodoUpdateStage, userID,
+		authz.Owner("being the creator", card.CreatedBy),
+		authz.MemberOf("being a teacher on the todo", card.Members.Teachers),
+		authz.MemberOf("being staff on the todo", card.Members.Staffs)); err != nil {
+		return err
+	}
+
+	currentStage := board.DefaultStage
+	if card.Stage != nil && *card.Stage != "" {
+		currentStage = *card.Stage
+	}
+
+	isDefault := req.Stage == board.DefaultStage
+
+	if currentStage != req.Stage {
+		if err := s.checkWIPLimit(ctx, board, column); err != nil {
+			return err
+		}
+	}
+
+	cards, err := s.BoardRepo.GetCards(ctx, board.OrganizationID, req.Stage, isDefault)
+	if err != nil {
+		return err
+	}
+
+	others := make([]*Card, 0, len(cards))
+	for _, c := range cards {
+		if c.ID != card.ID {
+			others = append(others, c)
+		}
+	}
+
+	index := req.Index
+	if index > len(others) {
+		index = len(others)
+	}
+
+	position, ok := positionBetween(others, index)
+	if !ok {
+		// Neighbours are too close (or share a position); spread the column out above its
+		// last card and leave a slot for the moved card. Cards are written from the bottom
+		// up, so a rebalance that stops halfway still shows the column in its old order:
+		// the cards already moved all sit above the ones that are not.
+		base := others[len(others)-1].Position
+		positions := make([]CardPosition, 0, len(others))
+		for i := len(others) - 1; i >= 0; i-- {
+			slot := i
+			if i >= index {
+				slot++
+			}
+			positions = append(positions, CardPosition{ID: others[i].ID, Position: base + float64(slot+1)*positionStep})
+		}
+		if err := s.BoardRepo.SetCardPositions(ctx, positions); err != nil {
+			return err
+		}
+		position = base + float64(index+1)*positionStep
+	}
+
+	return s.BoardRepo.MoveCard(ctx, card.ID, req.Stage, position)
+}
+
+// lockMoves takes the board's move lease, waiting briefly while another move holds it.
+func (s *boardService) lockMoves(ctx context.Context, boardID primitive.ObjectID) (primitive.ObjectID, error) {
+	token := primitive.NewObjectID()
+
+	for attempt := 0; attempt < moveLockAttempts; attempt++ {
+		now := time.Now().UTC()
+		locked, err := s.BoardRepo.LockMoves(ctx, boardID, token, now, now.Add(moveLockLease))
+		if err != nil {
+			return token, err
+		}
+		if locked {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return token, ctx.Err()
+		case <-time.After(moveLockWait):
+		}
+	}
+
+	return token, helper.Conflict("the board is busy with another move, try again")
+}
+
+// unlockMoves releases the lease. It runs even when the request was cancelled, so it does
+// not use the request context.
+func (s *boardService) unlockMoves(boardID, token primitive.ObjectID) {
+	if err := s.BoardRepo.UnlockMoves(context.Background(), boardID, token); err != nil {
+		log.Printf("[WARN] failed to release the move lock of board %s: %v", boardID.Hex(), err)
+	}
+}
+
+// checkWIPLimit rejects one more card in the column once it holds its limit. Callers hold
+// the board's move lock, so the count cannot go stale before their card lands.
+func (s *boardService) checkWIPLimit(ctx context.Context, board *Board, column *Column) error {
+
+	if column == nil || column.WIPLimit == nil {
+		return nil
+	}
+
+	count, err := s.BoardRepo.CountCards(ctx, board.OrganizationID, column.Stage, column.Stage == board.DefaultStage)
+	if err != nil {
+		return err
+	}
+	if count >= *column.WIPLimit {
+		return helper.Conflict("column %q is at its WIP limit of %d", column.Name, *column.WIPLimit)
+	}
+
+	return nil
+}
+
+func (s *boardService) ResolveStage(ctx context.Context, organizationID string, stage *string) (*string, error) {
+
+	board, err := s.BoardRepo.GetBoardByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveStage(board, stage)
+}
+
+func resolveStage(board *Board, stage *string) (*string, error) {
+
+	// Organizations without a board keep free-form stages
+	if board == nil {
+		return stage, nil
+	}
+
+	if stage == nil || *stage == "" {
+		defaultStage := board.DefaultStage
+		return &defaultStage, nil
+	}
+
+	if findColumn(board.Columns, *stage) == nil {
+		return nil, fmt.Errorf("stage %q is not a column on the organization's board", *stage)
+	}
+
+	return stage, nil
+}
+
+// AddCard places a new todo at the bottom of its column. create stores the todo with the
+// resolved stage and position; on a board it runs under the move lock and after the WIP
+// check, like MoveCard.
+func (s *boardService) AddCard(ctx context.Context, organizationID string, stage *string, create func(stage *string, position float64) error) error {
+
+	board, err := s.BoardRepo.GetBoardByOrganization(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	stage, err = resolveStage(board, stage)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		position, err := s.nextPosition(ctx, nil, organizationID, stage)
+		if err != nil {
+			return err
+		}
+		return create(stage, position)
+	}
+
+	token, err := s.lockMoves(ctx, board.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlockMoves(board.ID, token)
+
+	if err := s.checkWIPLimit(ctx, board, findColumn(board.Columns, *stage)); err != nil {
+		return err
+	}
+
+	position, err := s.nextPosition(ctx, board, organizationID, stage)
+	if err != nil {
+		return err
+	}
+
+	return create(stage, position)
+}
+
+// ChangeStage moves a todo to the bottom of another column, for stage edits made on the
+// todo itself. On a board it takes the move lock and checks the WIP limit, like MoveCard;
+// the caller has already checked that the user may change the stage.
+func (s *boardService) ChangeStage(ctx context.Context, organizationID string, todoID primitive.ObjectID, stage *string) error {
+
+	board, err := s.BoardRepo.GetBoardByOrganization(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	stage, err = resolveStage(board, stage)
+	if err != nil {
+		return err
+	}
+
+	if stage == nil {
+		return nil
+	}
+
+	if board == nil {
+		position, err := s.nextPosition(ctx, nil, organizationID, stage)
+		if err != nil {
+			return err
+		}
+		return s.BoardRepo.MoveCard(ctx, todoID, *stage, position)
+	}
+
+	token, err := s.lockMoves(ctx, board.ID)
+	if err != nil {
+		return err
+	}
+	defer s.unlockMoves(board.ID, token)
+
+	card, err := s.BoardRepo.GetCardByID(ctx, todoID)
+	if err != nil {
+		return err
+	}
+
+	if card == nil || card.OrganizationID != organizationID {
+		return fmt.Errorf("card not found on this board")
+	}
+
+	currentStage := board.DefaultStage
+	if card.Stage != nil && *card.Stage != "" {
+		currentStage = *card.Stage
+	}
+
+	if currentStage == *stage {
+		return nil
+	}
+
+	if err := s.checkWIPLimit(ctx, board, findColumn(board.Columns, *stage)); err != nil {
+		return err
+	}
+
+	position, err := s.nextPosition(ctx, board, organizationID, stage)
+	if err != nil {
+		return err
+	}
+
+	return s.BoardRepo.MoveCard(ctx, todoID, *stage, position)
+}
+
+// nextPosition is the position just below the last card of the column.
+func (s *boardService) nextPosition(ctx context.Context, board *Board, organizationID string, stage *string) (float64, error) {
+
+	if stage == nil {
+		return positionStep, nil
+	}
+
+	isDefault := board != nil && board.DefaultStage == *stage
+
+	maxPosition, err := s.BoardRepo.GetMaxPosition(ctx, organizationID, *stage, isDefault)
+	if err != nil {
+		return 0, err
+	}
+
+	return maxPosition + positionStep, nil
+}
+
+func (s *boardService) buildBoardResponse(ctx context.Context, board *Board, userID string) (*BoardResponse, error) {
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, board.OrganizationID)
+
+	columns := make([]ColumnResponse, 0, len(board.Columns))
+	for _, column := range board.Columns {
+		cards, err := s.BoardRepo.GetCards(ctx, board.OrganizationID, column.Stage, column.Stage == board.DefaultStage)
+		if err != nil {
+			return nil, err
+		}
+
+		columnCards := make([]Card, 0, len(cards))
+		for _, card := range cards {
+			card.DueDate = card.DueDate.In(loc)
+			card.CreatedAt = card.CreatedAt.In(loc)
+			columnCards = append(columnCards, *card)
+		}
+
+		columns = append(columns, ColumnResponse{
+			Stage:     column.Stage,
+			Name:      column.Name,
+			Order:     column.Order,
+			WIPLimit:  column.WIPLimit,
+			CardCount: len(columnCards),
+			Cards:     columnCards,
+		})
+	}
+
+	return &BoardResponse{
+		ID:             board.ID,
+		OrganizationID: board.OrganizationID,
+		Name:           board.Name,
+		DefaultStage:   board.DefaultStage,
+		Columns:        columns,
+		CreatedBy:      board.CreatedBy,
+		CreatedAt:      board.CreatedAt.In(loc),
+		UpdatedAt:      board.UpdatedAt.In(loc),
+	}, nil
+}
+
+func buildColumns(reqs []ColumnRequest) ([]Column, error) {
+
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("at least one column is required")
+	}
+
+	seen := make(map[string]bool, len(reqs))
+	columns := make([]Column, 0, len(reqs))
+
+	for i, req := range reqs {
+		if req.Stage == "" {
+			return nil, fmt.Errorf("column %d: stage is required", i+1)
+		}
+		if seen[req.Stage] {
+			return nil, fmt.Errorf("stage %q is used by more than one column", req.Stage)
+		}
+		if req.WIPLimit != nil && *req.WIPLimit < 1 {
+			return nil, fmt.Errorf("column %q: wip_limit must be at least 1", req.Stage)
+		}
+		seen[req.Stage] = true
+
+		name := req.Name
+		if name == "" {
+			name = req.Stage
+		}
+
+		columns = append(columns, Column{
+			Stage:    req.Stage,
+			Name:     name,
+			Order:    i,
+			WIPLimit: req.WIPLimit,
+		})
+	}
+
+	return columns, nil
+}
+
+func resolveDefaultStage(columns []Column, defaultStage string) (string, error) {
+	if defaultStage == "" {
+		return columns[0].Stage, nil
+	}
+	if findColumn(columns, defaultStage) == nil {
+		return "", fmt.Errorf("default_stage %q is not one of the board's columns", defaultStage)
+	}
+	return defaultStage, nil
+}
+
+func findColumn(columns []Column, stage string) *Column {
+	for i := range columns {
+		if columns[i].Stage == stage {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// positionBetween returns a position that sorts the card at index among cards.
+// It reports false when the neighbours leave no usable gap.
+func positionBetween(cards []*Card, index int) (float64, bool) {
+
+	switch {
+	case len(cards) == 0:
+		return positionStep, true
+	case index == 0:
+		return cards[0].Position - positionStep, true
+	case index >= len(cards):
+		return cards[len(cards)-1].Position + positionStep, true
+	}
+
+	prev, next := cards[index-1].Position, cards[index].Position
+	if next-prev < 1e-6 {
+		return 0, false
+	}
+
+	return (prev + next) / 2, true
+}
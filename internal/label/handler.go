@@ -0,0 +1,54 @@
+package label
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	LabelService LabelService
+}
+
+func NewLabelHandler(labelService LabelService) *LabelHandler {
+	return &LabelHandler{
+		LabelService: labelService,
+	}
+}
+
+func (h *LabelHandler) CreateLabelSheet(c *gin.Context) {
+
+	var req CreateLabelSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.LabelService.CreateLabelSheet(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("labels-%s.pdf", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(200, "application/pdf", data)
+}
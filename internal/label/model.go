@@ -0,0 +1,9 @@
+package label
+
+// Label is one sticker on a sheet: the QR payload and the text printed under it.
+type Label struct {
+	Payload   string `json:"payload"`
+	Title     string `json:"title"`
+	JobNumber string `json:"job_number"`
+	Location  string `json:"location"`
+}
@@ -0,0 +1,7 @@
+package label
+
+type CreateLabelSheetRequest struct {
+	Title     string   `json:"title"`
+	TodoIDs   []string `json:"todo_ids"`
+	RepairIDs []string `json:"repair_ids"`
+}
@@ -0,0 +1,14 @@
+package label
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, labelHandler *LabelHandler) {
+	labelGroup := r.Group("/api/v1/labels", middleware.Secured())
+	{
+		labelGroup.POST("/sheet", labelHandler.CreateLabelSheet)
+	}
+}
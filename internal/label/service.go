@@ -0,0 +1,192 @@
+package label
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"todo-service/internal/authz"
+	"todo-service/internal/location"
+	"todo-service/internal/repair"
+	"todo-service/internal/todo"
+	"todo-service/internal/user"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxLabelsPerSheet bounds a single request so a PDF stays printable and fast to render.
+const MaxLabelsPerSheet = 120
+
+type LabelService interface {
+	CreateLabelSheet(ctx context.Context, req CreateLabelSheetRequest, userID string) ([]byte, error)
+}
+
+type labelService struct {
+	TodoRepo        todo.TodoRepository
+	RepairRepo      repair.RepairRepository
+	LocationService location.LocationService
+	UserService     user.UserService
+}
+
+func NewLabelService(todoRepo todo.TodoRepository, repairRepo repair.RepairRepository, locationService location.LocationService, userService user.UserService) LabelService {
+	return &labelService{
+		TodoRepo:        todoRepo,
+		RepairRepo:      repairRepo,
+		LocationService: locationService,
+		UserService:     userService,
+	}
+}
+
+// CreateLabelSheet renders the QR labels of the todos and repairs. The caller must belong to
+// the organization of every one of them.
+func (s *labelService) CreateLabelSheet(ctx context.Context, req CreateLabelSheetRequest, userID string) ([]byte, error) {
+
+	total := len(req.TodoIDs) + len(req.RepairIDs)
+	if total == 0 {
+		return nil, fmt.Errorf("todo_ids or repair_ids is required")
+	}
+
+	if total > MaxLabelsPerSheet {
+		return nil, fmt.Errorf("at most %d labels can be printed at once", MaxLabelsPerSheet)
+	}
+
+	labels := make([]Label, 0, total)
+	allowed := map[string]bool{}
+
+	todoLabels, err := s.todoLabels(ctx, req.TodoIDs, userID, allowed)
+	if err != nil {
+		return nil, err
+	}
+	labels = append(labels, todoLabels...)
+
+	repairLabels, err := s.repairLabels(ctx, req.RepairIDs, userID, allowed)
+	if err != nil {
+		return nil, err
+	}
+	labels = append(labels, repairLabels...)
+
+	return renderSheet(req.Title, labels)
+}
+
+func (s *labelService) todoLabels(ctx context.Context, ids []string, userID string, allowed map[string]bool) ([]Label, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid todo id %q", id)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	todos, err := s.TodoRepo.GetTodosByIDs(ctx, objectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[primitive.ObjectID]*todo.Todo, len(todos))
+	for _, t := range todos {
+		byID[t.ID] = t
+	}
+
+	// Keep the order the caller asked for so sheets match their lists
+	labels := make([]Label, 0, len(objectIDs))
+	for _, objectID := range objectIDs {
+		t, ok := byID[objectID]
+		if !ok {
+			return nil, fmt.Errorf("todo %s not found", objectID.Hex())
+		}
+		if err := s.canPrint(ctx, userID, t.OrganizationID, allowed); err != nil {
+			return nil, err
+		}
+		labels = append(labels, Label{
+			Payload: t.QRCode,
+			Title:   t.Name,
+		})
+	}
+
+	return labels, nil
+}
+
+func (s *labelService) repairLabels(ctx context.Context, ids []string, userID string, allowed map[string]bool) ([]Label, error) {
+	locationNames := map[string]string{}
+
+	labels := make([]Label, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repair id %q", id)
+		}
+
+		r, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, fmt.Errorf("repair %s not found", id)
+		}
+		if err := s.canPrint(ctx, userID, r.OrganizationID, allowed); err != nil {
+			return nil, err
+		}
+
+		name, ok := locationNames[r.Location]
+		if !ok && r.Location != "" {
+			loc, err := s.LocationService.GetLocationByID(ctx, r.Location)
+			if err != nil {
+				log.Printf("[WARN] failed to resolve location %s for label: %v", r.Location, err)
+			} else if loc != nil {
+				name = loc.Name
+			}
+			locationNames[r.Location] = name
+		}
+
+		// Labels show the same display code as the rest of the app
+		jobNumber := r.JobCode
+		if jobNumber == "" {
+			jobNumber = fmt.Sprintf("Job #%d", r.JobNumber)
+		}
+
+		labels = append(labels, Label{
+			Payload:   r.QRCode,
+			Title:     r.JobName,
+			JobNumber: jobNumber,
+			Location:  name,
+		})
+	}
+
+	return labels, nil
+}
+
+// canPrint allows the organization's teachers and staff to print its labels. Organizations
+// already allowed are remembered, since a sheet usually holds one organization's labels.
+func (s *labelService) canPrint(ctx context.Context, userID, organizationID string, allowed map[string]bool) error {
+	if allowed[organizationID] {
+		return nil
+	}
+
+	if err := authz.Authorize(ctx, authz.LabelPrint, userID,
+		authz.When("being a member of the organization", s.isOrganizationMember(ctx, userID, organizationID))); err != nil {
+		return err
+	}
+
+	allowed[organizationID] = true
+	return nil
+}
+
+// isOrganizationMember reports whether the user is a teacher or staff member of the organization.
+func (s *labelService) isOrganizationMember(ctx context.Context, userID, organizationID string) bool {
+	teacher, err := s.UserService.GetTeacherInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check teacher %s in organization %s: %v", userID, organizationID, err)
+	} else if teacher != nil && teacher.UserID != "" {
+		return true
+	}
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check staff %s in organization %s: %v", userID, organizationID, err)
+		return false
+	}
+	return staff != nil && staff.UserID != ""
+}
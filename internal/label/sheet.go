@@ -0,0 +1,101 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"todo-service/helper"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// A4 portrait, three columns by four rows of stickers.
+const (
+	sheetMargin  = 10.0
+	sheetColumns = 3
+	sheetRows    = 4
+	headerHeight = 12.0
+	qrSize       = 40.0 // mm
+	qrPixels     = 512
+)
+
+// renderSheet lays the labels out on A4 pages. Text is printed with the TTF
+// in LABEL_FONT_PATH when set, so non-Latin-1 names keep their accents;
+// otherwise the core Helvetica font is used.
+func renderSheet(title string, labels []Label) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(sheetMargin, sheetMargin, sheetMargin)
+	pdf.SetAutoPageBreak(false, sheetMargin)
+
+	family, translate, err := helper.LoadPDFFont(pdf, os.Getenv("LABEL_FONT_PATH"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load label font: %v", err)
+	}
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	top := sheetMargin
+	if title != "" {
+		top += headerHeight
+	}
+	cellWidth := (pageWidth - 2*sheetMargin) / sheetColumns
+	cellHeight := (pageHeight - top - sheetMargin) / sheetRows
+	perPage := sheetColumns * sheetRows
+
+	for i, label := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+			if title != "" {
+				pdf.SetFont(family, "B", 14)
+				pdf.SetXY(sheetMargin, sheetMargin)
+				pdf.CellFormat(pageWidth-2*sheetMargin, headerHeight-2, translate(title), "", 0, "C", false, 0, "")
+			}
+		}
+
+		slot := i % perPage
+		x := sheetMargin + float64(slot%sheetColumns)*cellWidth
+		y := top + float64(slot/sheetColumns)*cellHeight
+
+		// Dashed cut lines around each sticker
+		pdf.SetDrawColor(180, 180, 180)
+		pdf.SetDashPattern([]float64{1, 1}, 0)
+		pdf.Rect(x, y, cellWidth, cellHeight, "D")
+		pdf.SetDashPattern([]float64{}, 0)
+
+		png, err := helper.RenderQRCodePNG(label.Payload, qrPixels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render qrcode for %q: %v", label.Title, err)
+		}
+
+		imageName := fmt.Sprintf("qr-%d", i)
+		pdf.RegisterImageOptionsReader(imageName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+		pdf.ImageOptions(imageName, x+(cellWidth-qrSize)/2, y+3, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		textWidth := cellWidth - 4
+		lineY := y + qrSize + 5
+
+		pdf.SetFont(family, "B", 10)
+		pdf.SetXY(x+2, lineY)
+		pdf.CellFormat(textWidth, 5, helper.FitPDFText(pdf, translate, label.Title, textWidth), "", 0, "C", false, 0, "")
+
+		pdf.SetFont(family, "", 8)
+		for _, line := range []string{label.JobNumber, label.Location} {
+			if line == "" {
+				continue
+			}
+			lineY += 5
+			pdf.SetXY(x+2, lineY)
+			pdf.CellFormat(textWidth, 4, helper.FitPDFText(pdf, translate, line, textWidth), "", 0, "C", false, 0, "")
+		}
+	}
+
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -1,22 +1,44 @@
 package middleware
 
 import (
-	"todo-service/pkg/constants"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"todo-service/internal/authz"
+	"todo-service/pkg/constants"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+var missingSecretWarning sync.Once
+
+// Secured rejects requests without a bearer token signed with JWT_SECRET. Without a secret
+// no token can be trusted, so every request is rejected.
 func Secured() gin.HandlerFunc {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		missingSecretWarning.Do(func() {
+			log.Println("[WARN] JWT_SECRET is not set, all secured requests are rejected")
+		})
+	}
+
 	return func(context *gin.Context) {
+		if secret == "" {
+			context.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		authorizationHeader := context.GetHeader("Authorization")
 
 		if len(authorizationHeader) == 0 {
 			context.AbortWithStatus(http.StatusForbidden)
 			return
 		}
-		
+
 		if !strings.HasPrefix(authorizationHeader, "Bearer ") {
 			context.AbortWithStatus(http.StatusUnauthorized)
 			return
@@ -24,15 +46,35 @@ func Secured() gin.HandlerFunc {
 
 		tokenString := strings.Split(authorizationHeader, " ")[1]
 
-		token, _, _ := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+		claims, err := parseClaims(tokenString, secret)
+		if err != nil {
+			context.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userId, ok := claims[constants.UserID].(string); ok {
-				context.Set(constants.UserID, userId)
-			}
+		if userId, ok := claims[constants.UserID].(string); ok {
+			context.Set(constants.UserID, userId)
 		}
 
+		context.Set(constants.Claims, authz.NewClaims(claims, true))
 		context.Set(constants.Token, tokenString)
 		context.Next()
 	}
 }
+
+// parseClaims verifies the HMAC signature of the token and returns its claims.
+func parseClaims(tokenString, secret string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"fmt"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	NotificationService NotificationService
+}
+
+func NewNotificationHandler(notificationService NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		NotificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	data, err := h.NotificationService.GetMyNotifications(c, userID.(string), unreadOnly)
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get notifications successfully", data, 0)
+}
+
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.NotificationService.MarkAsRead(c, id, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Mark notification as read successfully", nil, 0)
+}
+
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	err := h.NotificationService.MarkAllAsRead(c, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Mark all notifications as read successfully", nil, 0)
+}
@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Notification struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	UserID         string             `json:"user_id" bson:"user_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Type           string             `json:"type" bson:"type"`
+	Title          string             `json:"title" bson:"title"`
+	Body           string             `json:"body" bson:"body"`
+	Data           map[string]string  `json:"data" bson:"data"`
+	ReadAt         *time.Time         `json:"read_at" bson:"read_at"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Message is what a module asks to send; one Notification is stored per recipient.
+type Message struct {
+	UserIDs        []string
+	OrganizationID string
+	Type           string
+	Title          string
+	Body           string
+	Data           map[string]string
+}
@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+
+	"firebase.google.com/go/v4/messaging"
+)
+
+// Pusher delivers a stored notification to the user's devices.
+type Pusher interface {
+	Push(ctx context.Context, n *Notification) error
+}
+
+// TopicPrefix is the FCM topic apps subscribe to for a signed-in user: "user_<user_id>".
+const TopicPrefix = "user_"
+
+type fcmPusher struct {
+	client *messaging.Client
+}
+
+func NewFCMPusher(client *messaging.Client) Pusher {
+	return &fcmPusher{
+		client: client,
+	}
+}
+
+func (p *fcmPusher) Push(ctx context.Context, n *Notification) error {
+	data := map[string]string{
+		"notification_id": n.ID.Hex(),
+		"type":            n.Type,
+	}
+	for k, v := range n.Data {
+		data[k] = v
+	}
+
+	_, err := p.client.Send(ctx, &messaging.Message{
+		Topic: TopicPrefix + n.UserID,
+		Notification: &messaging.Notification{
+			Title: n.Title,
+			Body:  n.Body,
+		},
+		Data: data,
+	})
+	return err
+}
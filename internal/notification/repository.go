@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository interface {
+	CreateNotifications(ctx context.Context, notifications []*Notification) error
+	GetNotificationsByUser(ctx context.Context, userID string, unreadOnly bool, limit int64) ([]*Notification, error)
+	MarkAsRead(ctx context.Context, id primitive.ObjectID, userID string, now time.Time) (bool, error)
+	MarkAllAsRead(ctx context.Context, userID string, now time.Time) error
+}
+
+type notificationRepository struct {
+	notificationCollection *mongo.Collection
+}
+
+func NewNotificationRepository(notificationCollection *mongo.Collection) NotificationRepository {
+	return &notificationRepository{
+		notificationCollection: notificationCollection,
+	}
+}
+
+func (r *notificationRepository) CreateNotifications(ctx context.Context, notifications []*Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(notifications))
+	for _, n := range notifications {
+		docs = append(docs, n)
+	}
+
+	_, err := r.notificationCollection.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *notificationRepository) GetNotificationsByUser(ctx context.Context, userID string, unreadOnly bool, limit int64) ([]*Notification, error) {
+
+	var notifications []*Notification
+
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read_at"] = nil
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+
+	cursor, err := r.notificationCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var n Notification
+		if err := cursor.Decode(&n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+
+	return notifications, cursor.Err()
+}
+
+func (r *notificationRepository) MarkAsRead(ctx context.Context, id primitive.ObjectID, userID string, now time.Time) (bool, error) {
+	result, err := r.notificationCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.M{"$set": bson.M{"read_at": now}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *notificationRepository) MarkAllAsRead(ctx context.Context, userID string, now time.Time) error {
+	_, err := r.notificationCollection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "read_at": nil},
+		bson.M{"$set": bson.M{"read_at": now}})
+	return err
+}
@@ -0,0 +1,16 @@
+package notification
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, notificationHandler *NotificationHandler) {
+	notificationGroup := r.Group("/api/v1/notifications", middleware.Secured())
+	{
+		notificationGroup.GET("", notificationHandler.GetMyNotifications)
+		notificationGroup.POST("/read-all", notificationHandler.MarkAllAsRead)
+		notificationGroup.POST("/:id/read", notificationHandler.MarkAsRead)
+	}
+}
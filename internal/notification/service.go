@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultNotificationLimit = 50
+
+type NotificationService interface {
+	// Notify stores one inbox entry per recipient and pushes it when a pusher is configured.
+	Notify(ctx context.Context, msg Message) error
+
+	GetMyNotifications(ctx context.Context, userID string, unreadOnly bool) ([]*Notification, error)
+	MarkAsRead(ctx context.Context, id string, userID string) error
+	MarkAllAsRead(ctx context.Context, userID string) error
+}
+
+type notificationService struct {
+	NotificationRepo NotificationRepository
+	Pusher           Pusher
+}
+
+// NewNotificationService builds the service; pusher may be nil to keep notifications in the inbox only.
+func NewNotificationService(notificationRepo NotificationRepository, pusher Pusher) NotificationService {
+	return &notificationService{
+		NotificationRepo: notificationRepo,
+		Pusher:           pusher,
+	}
+}
+
+func (s *notificationService) Notify(ctx context.Context, msg Message) error {
+
+	if msg.Type == "" {
+		return fmt.Errorf("notification type is required")
+	}
+
+	now := time.Now().UTC()
+	seen := map[string]bool{}
+
+	var notifications []*Notification
+	for _, userID := range msg.UserIDs {
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		notifications = append(notifications, &Notification{
+			ID:             primitive.NewObjectID(),
+			UserID:         userID,
+			OrganizationID: msg.OrganizationID,
+			Type:           msg.Type,
+			Title:          msg.Title,
+			Body:           msg.Body,
+			Data:           msg.Data,
+			CreatedAt:      now,
+		})
+	}
+
+	if err := s.NotificationRepo.CreateNotifications(ctx, notifications); err != nil {
+		return err
+	}
+
+	if s.Pusher != nil {
+		for _, n := range notifications {
+			if err := s.Pusher.Push(ctx, n); err != nil {
+				log.Printf("[WARN] failed to push notification %s to user %s: %v", n.ID.Hex(), n.UserID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationService) GetMyNotifications(ctx context.Context, userID string, unreadOnly bool) ([]*Notification, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	return s.NotificationRepo.GetNotificationsByUser(ctx, userID, unreadOnly, defaultNotificationLimit)
+}
+
+func (s *notificationService) MarkAsRead(ctx context.Context, id string, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	found, err := s.NotificationRepo.MarkAsRead(ctx, objectID, userID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("notification not found")
+	}
+
+	return nil
+}
+
+func (s *notificationService) MarkAllAsRead(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+
+	return s.NotificationRepo.MarkAllAsRead(ctx, userID, time.Now().UTC())
+}
@@ -0,0 +1,277 @@
+package overdue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-service/internal/notification"
+	"todo-service/internal/setting"
+	"todo-service/internal/task"
+	"todo-service/internal/todo"
+)
+
+// Escalation steps, in the order they fire
+const (
+	StepMembers = "members"
+	StepOwner   = "owner"
+	StepAdmin   = "admin"
+)
+
+const DefaultInterval = 10 * time.Minute
+
+// Worker marks todos and tasks that passed their due date and escalates them step by step.
+// Each step is recorded on the item before it is sent, so several instances can run the
+// worker without repeating notifications.
+type Worker struct {
+	TodoRepo            todo.TodoRepository
+	TaskRepo            task.TaskRepository
+	SettingService      setting.SettingService
+	NotificationService notification.NotificationService
+	Interval            time.Duration
+}
+
+func NewWorker(todoRepo todo.TodoRepository, taskRepo task.TaskRepository, settingService setting.SettingService, notificationService notification.NotificationService, interval time.Duration) *Worker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Worker{
+		TodoRepo:            todoRepo,
+		TaskRepo:            taskRepo,
+		SettingService:      settingService,
+		NotificationService: notificationService,
+		Interval:            interval,
+	}
+}
+
+// Start runs the worker until ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) {
+	log.Printf("Overdue worker started, checking every %s", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx, time.Now().UTC()); err != nil {
+			log.Printf("[WARN] overdue worker run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Overdue worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// step is one escalation that is due for an item.
+type step struct {
+	name       string
+	recipients []string
+	title      string
+	body       string
+}
+
+func (w *Worker) RunOnce(ctx context.Context, now time.Time) error {
+
+	settings := map[string]setting.OverdueEscalation{}
+	config := func(organizationID string) setting.OverdueEscalation {
+		if cfg, ok := settings[organizationID]; ok {
+			return cfg
+		}
+		var cfg setting.OverdueEscalation
+		orgSetting, err := w.SettingService.GetOrganizationSetting(ctx, organizationID)
+		if err != nil {
+			log.Printf("[WARN] failed to load escalation settings for %s: %v", organizationID, err)
+		} else {
+			cfg = orgSetting.OverdueEscalation
+		}
+		settings[organizationID] = cfg
+		return cfg
+	}
+
+	if err := w.runTodos(ctx, now, config); err != nil {
+		return fmt.Errorf("todos: %v", err)
+	}
+
+	if err := w.runTasks(ctx, now, config); err != nil {
+		return fmt.Errorf("tasks: %v", err)
+	}
+
+	return nil
+}
+
+func (w *Worker) runTodos(ctx context.Context, now time.Time, config func(string) setting.OverdueEscalation) error {
+
+	if _, err := w.TodoRepo.ClearResolvedOverdue(ctx, now); err != nil {
+		return err
+	}
+
+	marked, err := w.TodoRepo.MarkOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		log.Printf("Marked %d todos overdue", marked)
+	}
+
+	todos, err := w.TodoRepo.GetOverdueTodos(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range todos {
+		cfg := config(item.OrganizationID)
+		if cfg.Disabled || item.OverdueSince == nil {
+			continue
+		}
+
+		// Members who already finished their own part are left alone
+		var members []string
+		for _, id := range append(append([]string{}, item.TaskUsers.Students...), item.TaskUsers.Staffs...) {
+			if !memberDone(item, id) {
+				members = append(members, id)
+			}
+		}
+
+		steps := w.dueSteps(cfg, *item.OverdueSince, now,
+			members,
+			append([]string{item.CreatedBy}, item.TaskUsers.Teachers...),
+			fmt.Sprintf("%q is overdue", item.Name))
+
+		for _, s := range steps {
+			recorded, err := w.TodoRepo.RecordEscalation(ctx, item.ID, *item.OverdueSince, todo.Escalation{Step: s.name, Recipients: s.recipients, At: now})
+			if err != nil {
+				log.Printf("[WARN] failed to record %s escalation of todo %s: %v", s.name, item.ID.Hex(), err)
+				break
+			}
+			if !recorded {
+				continue
+			}
+			w.notify(ctx, item.OrganizationID, "todo.overdue."+s.name, s, map[string]string{"todo_id": item.ID.Hex()})
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) runTasks(ctx context.Context, now time.Time, config func(string) setting.OverdueEscalation) error {
+
+	if _, err := w.TaskRepo.ClearResolvedOverdue(ctx, now); err != nil {
+		return err
+	}
+
+	marked, err := w.TaskRepo.MarkOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+	if marked > 0 {
+		log.Printf("Marked %d tasks overdue", marked)
+	}
+
+	tasks, err := w.TaskRepo.GetOverdueTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range tasks {
+		cfg := config(item.OrganizationID)
+		if cfg.Disabled || item.OverdueSince == nil {
+			continue
+		}
+
+		owners := []string{item.CreatedBy}
+		for _, leader := range item.Leader {
+			owners = append(owners, leader.UserID)
+		}
+
+		steps := w.dueSteps(cfg, *item.OverdueSince, now,
+			item.PendingMembers(),
+			owners,
+			fmt.Sprintf("%q is overdue", item.Title))
+
+		for _, s := range steps {
+			recorded, err := w.TaskRepo.RecordEscalation(ctx, item.ID, *item.OverdueSince, task.Escalation{Step: s.name, Recipients: s.recipients, At: now})
+			if err != nil {
+				log.Printf("[WARN] failed to record %s escalation of task %s: %v", s.name, item.ID.Hex(), err)
+				break
+			}
+			if !recorded {
+				continue
+			}
+			w.notify(ctx, item.OrganizationID, "task.overdue."+s.name, s, map[string]string{"task_id": item.ID.Hex()})
+		}
+	}
+
+	return nil
+}
+
+// dueSteps lists the steps whose delay has passed. Steps without recipients are skipped
+// and not recorded, so they still fire once someone is there to receive them.
+func (w *Worker) dueSteps(cfg setting.OverdueEscalation, since, now time.Time, members, owners []string, title string) []step {
+
+	elapsed := now.Sub(since)
+
+	var steps []step
+	if len(members) > 0 {
+		steps = append(steps, step{
+			name:       StepMembers,
+			recipients: members,
+			title:      title,
+			body:       "The due date has passed and your part is not done yet",
+		})
+	}
+	if elapsed >= cfg.OwnerDelay() && len(owners) > 0 {
+		steps = append(steps, step{
+			name:       StepOwner,
+			recipients: owners,
+			title:      title,
+			body:       fmt.Sprintf("Still not finished after being overdue for %s", formatDuration(elapsed)),
+		})
+	}
+	if elapsed >= cfg.AdminDelay() && len(cfg.AdminUserIDs) > 0 {
+		steps = append(steps, step{
+			name:       StepAdmin,
+			recipients: cfg.AdminUserIDs,
+			title:      title,
+			body:       fmt.Sprintf("Escalated: still not finished after being overdue for %s", formatDuration(elapsed)),
+		})
+	}
+
+	return steps
+}
+
+func (w *Worker) notify(ctx context.Context, organizationID, notificationType string, s step, data map[string]string) {
+	if w.NotificationService == nil {
+		return
+	}
+	err := w.NotificationService.Notify(ctx, notification.Message{
+		UserIDs:        s.recipients,
+		OrganizationID: organizationID,
+		Type:           notificationType,
+		Title:          s.title,
+		Body:           s.body,
+		Data:           data,
+	})
+	if err != nil {
+		log.Printf("[WARN] failed to send %s notification: %v", notificationType, err)
+	}
+}
+
+func memberDone(item *todo.Todo, userID string) bool {
+	for _, entry := range item.MemberProgress {
+		if entry.UserID == userID {
+			return entry.Status == todo.MemberStatusDone
+		}
+	}
+	return false
+}
+
+func formatDuration(d time.Duration) string {
+	hours := int(d.Round(time.Hour) / time.Hour)
+	if hours < 48 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%d days", hours/24)
+}
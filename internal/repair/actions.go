@@ -0,0 +1,94 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"todo-service/internal/authz"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions a caller can take on a repair, as offered to clients that scan its QR code
+const (
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionAssign   = "assign"
+	ActionStart    = "start"
+	ActionHold     = "hold"
+	ActionResume   = "resume"
+	ActionComplete = "complete"
+	ActionVerify   = "verify"
+	ActionReopen   = "reopen"
+	ActionVote     = "vote"
+	ActionCoReport = "co_report"
+	// Preventive repairs only
+	ActionChecklist = "checklist"
+)
+
+// GetRepairActions lists what the user may do with the repair in its current status. The
+// checks are the ones each endpoint makes, run without auditing since nothing is done yet.
+func (s *repairService) GetRepairActions(ctx context.Context, id string, userID string) ([]string, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	probe := authz.Probe(ctx)
+	isMember := repair.ReportBy == userID || s.isOrganizationMember(ctx, userID, repair.OrganizationID)
+
+	checks := []struct {
+		action string
+		err    error
+	}{
+		{ActionUpdate, s.Policy.CanUpdateReport(probe, repair, userID)},
+		{ActionDelete, s.Policy.CanDeleteRepair(probe, repair, userID)},
+		{ActionAssign, s.Policy.CanAssignRepair(probe, repair, userID)},
+		{ActionStart, s.Policy.CanStartRepair(probe, repair, userID)},
+		{ActionHold, s.Policy.CanHoldRepair(probe, repair, userID)},
+		{ActionResume, s.Policy.CanResumeRepair(probe, repair, userID)},
+		{ActionComplete, s.Policy.CanCompleteRepair(probe, repair, userID)},
+		{ActionVerify, s.Policy.CanVerifyRepair(probe, repair, userID)},
+		{ActionReopen, s.Policy.CanReopenRepair(probe, repair, userID)},
+		{ActionVote, s.Policy.CanVoteUrgency(probe, repair, userID, isMember)},
+	}
+
+	actions := []string{}
+	for _, check := range checks {
+		if check.err == nil {
+			actions = append(actions, check.action)
+		}
+	}
+
+	// Co-reporting is voting plus a report of one's own, once per person
+	if s.Policy.CanVoteUrgency(probe, repair, userID, isMember) == nil && canCoReport(repair, userID) {
+		actions = append(actions, ActionCoReport)
+	}
+
+	if s.Policy.CanTickChecklist(probe, repair, userID) == nil && len(repair.Maintenance.Checklist) > 0 {
+		actions = append(actions, ActionChecklist)
+	}
+
+	return actions, nil
+}
+
+func canCoReport(repair *Repair, userID string) bool {
+	if repair.ReportBy == userID {
+		return false
+	}
+	for _, coReport := range repair.CoReporters {
+		if coReport.UserID == userID {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"todo-service/internal/asset"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AssetHistory lets the asset registry show the repairs reported against an asset.
+type AssetHistory struct {
+	RepairRepo RepairRepository
+}
+
+func NewAssetHistory(repairRepo RepairRepository) *AssetHistory {
+	return &AssetHistory{
+		RepairRepo: repairRepo,
+	}
+}
+
+func (h *AssetHistory) GetAssetRepairs(ctx context.Context, assetID primitive.ObjectID) ([]asset.RepairRecord, error) {
+
+	repairs, err := h.RepairRepo.GetRepairs(ctx, RepairFilter{AssetID: &assetID, SortBy: SortByNewest})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]asset.RepairRecord, 0, len(repairs))
+	for _, repair := range repairs {
+		records = append(records, asset.RepairRecord{
+			ID:         repair.ID,
+			JobCode:    repair.JobCode,
+			JobName:    repair.JobName,
+			Status:     repair.Status,
+			Open:       isOpenStatus(repair.Status),
+			Preventive: repair.Preventive,
+			DateReport: repair.DateReport,
+			DateRepair: repair.DateRepair,
+			TotalCost:  repair.TotalCost,
+		})
+	}
+
+	return records, nil
+}
+
+// resolveAsset looks up the asset a repair is reported against, which must belong to the
+// repair's organization.
+func (s *repairService) resolveAsset(ctx context.Context, id, organizationID string) (*asset.Asset, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset_id")
+	}
+
+	found, err := s.AssetRepo.GetAssetByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil || found.OrganizationID != organizationID {
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	return found, nil
+}
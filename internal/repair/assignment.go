@@ -0,0 +1,350 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/notification"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxQueueMembers = 100
+	// maxPickAttempts bounds retries when another report claims the same member first
+	maxPickAttempts = 3
+)
+
+func (s *repairService) GetAssignmentQueue(ctx context.Context, organizationID string, userID string) (*AssignmentQueueResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewQueue(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	queue, err := s.RepairRepo.GetAssignmentQueue(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if queue == nil {
+		return &AssignmentQueueResponse{
+			OrganizationID: organizationID,
+			Strategy:       StrategyRoundRobin,
+			Members:        []QueueMemberResponse{},
+		}, nil
+	}
+
+	return s.buildQueueResponse(ctx, queue, userID)
+}
+
+// UpdateAssignmentQueue replaces the organization's queue. Members must be staff of the
+// organization; those who stay keep their place in the round-robin.
+func (s *repairService) UpdateAssignmentQueue(ctx context.Context, req UpdateAssignmentQueueRequest, userID string) (*AssignmentQueueResponse, error) {
+
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanManageQueue(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = StrategyRoundRobin
+	}
+	if strategy != StrategyRoundRobin && strategy != StrategyLeastOpen {
+		return nil, fmt.Errorf("strategy must be %s or %s", StrategyRoundRobin, StrategyLeastOpen)
+	}
+
+	if len(req.Members) > maxQueueMembers {
+		return nil, fmt.Errorf("a queue cannot have more than %d members", maxQueueMembers)
+	}
+
+	if req.Enabled && len(req.Members) == 0 {
+		return nil, fmt.Errorf("an enabled queue needs at least one member")
+	}
+
+	existing, err := s.RepairRepo.GetAssignmentQueue(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastAssigned := map[string]*time.Time{}
+	if existing != nil {
+		for _, member := range existing.Members {
+			lastAssigned[member.UserID] = member.LastAssignedAt
+		}
+	}
+
+	seen := map[string]bool{}
+	members := make([]QueueMember, 0, len(req.Members))
+	for _, member := range req.Members {
+		if member.UserID == "" {
+			return nil, fmt.Errorf("user_id is required for every member")
+		}
+		if seen[member.UserID] {
+			return nil, fmt.Errorf("user %s is listed more than once", member.UserID)
+		}
+		seen[member.UserID] = true
+
+		if err := s.ensureStaff(ctx, member.UserID, req.OrganizationID); err != nil {
+			return nil, err
+		}
+
+		members = append(members, QueueMember{
+			UserID:         member.UserID,
+			Skills:         normalizeSkills(member.Skills),
+			Locations:      compactStrings(member.Locations),
+			LastAssignedAt: lastAssigned[member.UserID],
+		})
+	}
+
+	now := time.Now().UTC()
+	queue := &AssignmentQueue{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: req.OrganizationID,
+		Enabled:        req.Enabled,
+		Strategy:       strategy,
+		Members:        members,
+		UpdatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.RepairRepo.UpsertAssignmentQueue(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	return s.buildQueueResponse(ctx, queue, userID)
+}
+
+func (s *repairService) buildQueueResponse(ctx context.Context, queue *AssignmentQueue, userID string) (*AssignmentQueueResponse, error) {
+
+	userIDs := make([]string, len(queue.Members))
+	for i, member := range queue.Members {
+		userIDs[i] = member.UserID
+	}
+
+	counts := map[string]int{}
+	if len(userIDs) > 0 {
+		var err error
+		counts, err = s.RepairRepo.CountOpenByAssignee(ctx, queue.OrganizationID, userIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, queue.OrganizationID)
+	updatedAt := queue.UpdatedAt.In(loc)
+
+	response := &AssignmentQueueResponse{
+		OrganizationID: queue.OrganizationID,
+		Enabled:        queue.Enabled,
+		Strategy:       queue.Strategy,
+		Members:        make([]QueueMemberResponse, 0, len(queue.Members)),
+		UpdatedBy:      queue.UpdatedBy,
+		UpdatedAt:      &updatedAt,
+	}
+
+	for _, member := range queue.Members {
+		memberResponse := QueueMemberResponse{
+			Skills:         member.Skills,
+			Locations:      member.Locations,
+			OpenRepairs:    counts[member.UserID],
+			LastAssignedAt: helper.InLocation(member.LastAssignedAt, loc),
+		}
+
+		info, err := s.UserService.GetUserInfor(ctx, member.UserID)
+		if err == nil && info != nil {
+			memberResponse.User = *info
+		} else {
+			memberResponse.User.UserID = member.UserID
+		}
+
+		response.Members = append(response.Members, memberResponse)
+	}
+
+	return response, nil
+}
+
+// ensureStaff rejects anyone who is not staff of the organization, so repairs are never
+// handed to a mistyped or departed user.
+func (s *repairService) ensureStaff(ctx context.Context, userID, organizationID string) error {
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to check staff %s: %v", userID, err)
+	}
+	if staff == nil || staff.UserID == "" {
+		return fmt.Errorf("user %s is not staff of the organization", userID)
+	}
+	return nil
+}
+
+// pickAssignee chooses the queue member to take the repair and records their turn. It returns
+// an empty ID when no enabled queue exists or no member covers the repair.
+func (s *repairService) pickAssignee(ctx context.Context, repair *Repair) (string, error) {
+
+	for attempt := 1; attempt <= maxPickAttempts; attempt++ {
+		queue, err := s.RepairRepo.GetAssignmentQueue(ctx, repair.OrganizationID)
+		if err != nil {
+			return "", err
+		}
+		if queue == nil || !queue.Enabled {
+			return "", nil
+		}
+
+		var eligible []QueueMember
+		for _, member := range queue.Members {
+			if member.covers(repair) {
+				eligible = append(eligible, member)
+			}
+		}
+		if len(eligible) == 0 {
+			return "", nil
+		}
+
+		var counts map[string]int
+		if queue.Strategy == StrategyLeastOpen {
+			userIDs := make([]string, len(eligible))
+			for i, member := range eligible {
+				userIDs[i] = member.UserID
+			}
+			counts, err = s.RepairRepo.CountOpenByAssignee(ctx, repair.OrganizationID, userIDs)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		next := eligible[0]
+		for _, member := range eligible[1:] {
+			if counts != nil && counts[member.UserID] != counts[next.UserID] {
+				if counts[member.UserID] < counts[next.UserID] {
+					next = member
+				}
+				continue
+			}
+			// Round-robin, and ties on open repairs, go to whoever waited longest
+			if assignedBefore(member.LastAssignedAt, next.LastAssignedAt) {
+				next = member
+			}
+		}
+
+		claimed, err := s.RepairRepo.MarkQueueMemberAssigned(ctx, queue.ID, next.UserID, next.LastAssignedAt, time.Now().UTC())
+		if err != nil {
+			return "", err
+		}
+		if claimed {
+			return next.UserID, nil
+		}
+	}
+
+	return "", fmt.Errorf("the assignment queue is busy, try again")
+}
+
+// autoAssign hands a newly reported repair to the queue. The repair is already saved, so a
+// failure leaves it pending for someone to assign by hand.
+func (s *repairService) autoAssign(ctx context.Context, repair *Repair) {
+
+	assignee, err := s.pickAssignee(ctx, repair)
+	if err != nil {
+		log.Printf("[WARN] failed to auto-assign repair %s: %v", repair.ID.Hex(), err)
+		return
+	}
+	if assignee == "" {
+		return
+	}
+
+	now := time.Now()
+	previous := repair.Status
+	repair.AssignedTo = &assignee
+	repair.AssignedAt = &now
+	recordStatus(repair, StatusAssigned, AutoAssigner, "", now)
+	evaluateSLA(repair, now)
+
+	// Someone who assigned the repair by hand in the meantime wins
+	if err := s.saveTransition(ctx, repair, previous); err != nil {
+		log.Printf("[WARN] failed to save auto-assignment of repair %s: %v", repair.ID.Hex(), err)
+		return
+	}
+
+	s.notifyAssignee(ctx, repair, assignee)
+}
+
+// notifyAssignee tells the user a repair was handed to them without them asking for it.
+func (s *repairService) notifyAssignee(ctx context.Context, repair *Repair, assignee string) {
+	if s.NotificationService == nil {
+		return
+	}
+
+	err := s.NotificationService.Notify(ctx, notification.Message{
+		UserIDs:        []string{assignee},
+		OrganizationID: repair.OrganizationID,
+		Type:           "repair.assigned",
+		Title:          "Repair assigned to you",
+		Body:           fmt.Sprintf("%s was assigned to you", repair.JobName),
+		Data: map[string]string{
+			"repair_id": repair.ID.Hex(),
+		},
+	})
+	if err != nil {
+		log.Printf("[WARN] failed to notify %s of repair %s: %v", assignee, repair.ID.Hex(), err)
+	}
+}
+
+func (m QueueMember) covers(repair *Repair) bool {
+	if len(m.Skills) > 0 && !containsString(m.Skills, strings.ToLower(repair.Category)) {
+		return false
+	}
+	if len(m.Locations) > 0 && !containsString(m.Locations, repair.Location) {
+		return false
+	}
+	return true
+}
+
+// assignedBefore orders members by their last assignment, never-assigned first.
+func assignedBefore(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	return a.Before(*b)
+}
+
+func normalizeSkills(skills []string) []string {
+	result := compactStrings(skills)
+	for i, skill := range result {
+		result[i] = strings.ToLower(skill)
+	}
+	return compactStrings(result)
+}
+
+// compactStrings trims the values and drops empty and repeated ones.
+func compactStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
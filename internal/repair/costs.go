@@ -0,0 +1,327 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"todo-service/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxLaborHours caps a single labor entry; longer jobs are logged per day.
+const maxLaborHours = 24
+
+func (s *repairService) AddLabor(ctx context.Context, id string, req AddLaborRequest, userID string) (*CostBreakdownResponse, error) {
+
+	if req.TechnicianID == "" {
+		return nil, fmt.Errorf("technician_id is required")
+	}
+
+	if req.Hours <= 0 || req.Hours > maxLaborHours {
+		return nil, fmt.Errorf("hours must be greater than 0 and at most %d", maxLaborHours)
+	}
+
+	if req.Rate < 0 {
+		return nil, fmt.Errorf("rate cannot be negative")
+	}
+
+	objectID, repair, err := s.getRepairForCosts(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.isOrganizationStaff(ctx, req.TechnicianID, repair.OrganizationID) {
+		return nil, fmt.Errorf("technician %s is not staff of the organization", req.TechnicianID)
+	}
+
+	now := time.Now()
+	workedAt := now
+	if req.WorkedAt != "" {
+		loc := s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID)
+		workedAt, err = helper.ParseDateTime(req.WorkedAt, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid worked_at format: %v", err)
+		}
+		if workedAt.After(now) {
+			return nil, fmt.Errorf("worked_at cannot be in the future")
+		}
+	}
+
+	basis, err := s.costBasis(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.RepairRepo.AddLabor(ctx, objectID, LaborEntry{
+		ID:           primitive.NewObjectID(),
+		TechnicianID: req.TechnicianID,
+		Description:  strings.TrimSpace(req.Description),
+		Hours:        req.Hours,
+		Rate:         req.Rate,
+		WorkedAt:     workedAt,
+		CreatedBy:    userID,
+		CreatedAt:    now,
+	}, basis)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	return s.costBreakdown(ctx, updated)
+}
+
+func (s *repairService) RemoveLabor(ctx context.Context, id, entryID string, userID string) (*CostBreakdownResponse, error) {
+
+	entryObjectID, err := primitive.ObjectIDFromHex(entryID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labor entry id")
+	}
+
+	objectID, repair, err := s.getRepairForCosts(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	basis, err := s.costBasis(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.RepairRepo.RemoveLabor(ctx, objectID, entryObjectID, basis)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("labor entry not found")
+	}
+
+	return s.costBreakdown(ctx, updated)
+}
+
+func (s *repairService) AddExternalCharge(ctx context.Context, id string, req AddExternalChargeRequest, userID string) (*CostBreakdownResponse, error) {
+
+	vendor := strings.TrimSpace(req.Vendor)
+	if vendor == "" {
+		return nil, fmt.Errorf("vendor is required")
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	objectID, repair, err := s.getRepairForCosts(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	basis, err := s.costBasis(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.RepairRepo.AddExternalCharge(ctx, objectID, ExternalCharge{
+		ID:          primitive.NewObjectID(),
+		Vendor:      vendor,
+		Description: strings.TrimSpace(req.Description),
+		Amount:      req.Amount,
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+	}, basis)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	return s.costBreakdown(ctx, updated)
+}
+
+func (s *repairService) RemoveExternalCharge(ctx context.Context, id, chargeID string, userID string) (*CostBreakdownResponse, error) {
+
+	chargeObjectID, err := primitive.ObjectIDFromHex(chargeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external charge id")
+	}
+
+	objectID, repair, err := s.getRepairForCosts(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	basis, err := s.costBasis(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.RepairRepo.RemoveExternalCharge(ctx, objectID, chargeObjectID, basis)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("external charge not found")
+	}
+
+	return s.costBreakdown(ctx, updated)
+}
+
+func (s *repairService) GetCostBreakdown(ctx context.Context, id string, userID string) (*CostBreakdownResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	if err := s.Policy.CanViewCosts(ctx, repair, userID, s.isOrganizationStaff(ctx, userID, repair.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	return s.costBreakdown(ctx, repair)
+}
+
+func (s *repairService) getRepairForCosts(ctx context.Context, id string, userID string) (primitive.ObjectID, *Repair, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	if repair == nil {
+		return primitive.NilObjectID, nil, fmt.Errorf("repair not found")
+	}
+
+	if err := s.Policy.CanManageCosts(ctx, repair, userID); err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	return objectID, repair, nil
+}
+
+// costBasis gathers what the repair is priced from besides its labor and charges, which
+// the repository reads inside the update that changes them.
+func (s *repairService) costBasis(ctx context.Context, repair *Repair) (CostBasis, error) {
+
+	var basis CostBasis
+
+	items, err := s.ShopService.GetRepairItems(ctx, repair.ID)
+	if err != nil {
+		return basis, fmt.Errorf("failed to get parts: %v", err)
+	}
+	if items != nil {
+		for _, item := range items.Items {
+			basis.Parts = roundMoney(basis.Parts + roundMoney(item.SubTotal))
+		}
+	}
+
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, repair.OrganizationID)
+	if err != nil {
+		return basis, err
+	}
+	basis.TaxRate = orgSetting.RepairTaxRate
+
+	return basis, nil
+}
+
+// costBreakdown prices the repair: shop parts at the price they were added for, labor at
+// hours times rate, external charges as billed, and tax on the sum.
+func (s *repairService) costBreakdown(ctx context.Context, repair *Repair) (*CostBreakdownResponse, error) {
+
+	breakdown := &CostBreakdownResponse{
+		RepairID: repair.ID,
+		JobCode:  repair.JobCode,
+		Parts:    CostSection{Lines: []CostLine{}},
+		Labor:    CostSection{Lines: []CostLine{}},
+		External: CostSection{Lines: []CostLine{}},
+	}
+
+	items, err := s.ShopService.GetRepairItems(ctx, repair.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parts: %v", err)
+	}
+	if items != nil {
+		for _, item := range items.Items {
+			breakdown.Parts.add(CostLine{
+				ID:          item.ID,
+				Reference:   item.Product.Name,
+				Description: item.Product.Description,
+				Quantity:    float64(item.Quantity),
+				UnitPrice:   item.Price,
+				Amount:      item.SubTotal,
+			})
+		}
+	}
+
+	names := map[string]string{}
+	for _, entry := range repair.Labor {
+		name, ok := names[entry.TechnicianID]
+		if !ok {
+			name = entry.TechnicianID
+			technician, err := s.UserService.GetUserInfor(ctx, entry.TechnicianID)
+			if err == nil && technician != nil && technician.UserName != "" {
+				name = technician.UserName
+			}
+			names[entry.TechnicianID] = name
+		}
+
+		breakdown.Labor.add(CostLine{
+			ID:          entry.ID,
+			Reference:   name,
+			Description: entry.Description,
+			Quantity:    entry.Hours,
+			UnitPrice:   entry.Rate,
+			Amount:      entry.Hours * entry.Rate,
+		})
+	}
+
+	for _, charge := range repair.ExternalCharges {
+		breakdown.External.add(CostLine{
+			ID:          charge.ID,
+			Reference:   charge.Vendor,
+			Description: charge.Description,
+			Quantity:    1,
+			UnitPrice:   charge.Amount,
+			Amount:      charge.Amount,
+		})
+	}
+
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, repair.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown.Subtotal = roundMoney(breakdown.Parts.Total + breakdown.Labor.Total + breakdown.External.Total)
+	breakdown.TaxRate = orgSetting.RepairTaxRate
+	breakdown.Tax = roundMoney(breakdown.Subtotal * breakdown.TaxRate / 100)
+	breakdown.Total = roundMoney(breakdown.Subtotal + breakdown.Tax)
+
+	return breakdown, nil
+}
+
+func (section *CostSection) add(line CostLine) {
+	line.Amount = roundMoney(line.Amount)
+	section.Lines = append(section.Lines, line)
+	section.Total = roundMoney(section.Total + line.Amount)
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,194 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// duplicateWindow is how far back open repairs are compared with a new report
+	duplicateWindow = 14 * 24 * time.Hour
+	maxDuplicates   = 5
+	// A candidate needs this much word overlap in its job name, or in name and comment together
+	minNameSimilarity = 0.5
+	minTextSimilarity = 0.3
+)
+
+// DuplicateRepairError stops CreateRepair when open repairs look like the one being
+// reported. The client shows Candidates and either co-reports one or retries with confirm_new.
+type DuplicateRepairError struct {
+	Candidates []DuplicateCandidate
+}
+
+func (e *DuplicateRepairError) Error() string {
+	return fmt.Sprintf("found %d similar open repairs at this location", len(e.Candidates))
+}
+
+func (s *repairService) findDuplicates(ctx context.Context, req CreateRepairRequest, now time.Time) ([]DuplicateCandidate, error) {
+
+	repairs, err := s.RepairRepo.GetOpenRepairsAtLocation(ctx, req.OrganizationID, req.Location, now.Add(-duplicateWindow))
+	if err != nil {
+		return nil, err
+	}
+	if len(repairs) == 0 {
+		return nil, nil
+	}
+
+	name := words(req.JobName)
+	text := words(req.JobName + " " + req.Comment)
+
+	var candidates []DuplicateCandidate
+	for _, repair := range repairs {
+		nameSimilarity := jaccard(name, words(repair.JobName))
+		textSimilarity := jaccard(text, words(repair.JobName+" "+repair.Comment))
+		if nameSimilarity < minNameSimilarity && textSimilarity < minTextSimilarity {
+			continue
+		}
+
+		candidates = append(candidates, DuplicateCandidate{
+			ID:         repair.ID.Hex(),
+			JobNumber:  repair.JobNumber,
+			JobCode:    repair.JobCode,
+			JobName:    repair.JobName,
+			Comment:    repair.Comment,
+			Status:     repair.Status,
+			UrgentVote: repair.UrgentVote,
+			DateReport: repair.DateReport,
+			Similarity: math.Round(math.Max(nameSimilarity, textSimilarity)*100) / 100,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	if len(candidates) > maxDuplicates {
+		candidates = candidates[:maxDuplicates]
+	}
+
+	return candidates, nil
+}
+
+// words splits text into its distinct lower-case words, ignoring one-letter ones.
+func words(text string) map[string]bool {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if len([]rune(field)) > 1 {
+			set[field] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// CoReportRepair joins the caller to an existing repair instead of reporting it again. Their
+// photos are kept with the co-report and their urgency counts as a vote.
+func (s *repairService) CoReportRepair(ctx context.Context, id string, req CoReportRepairRequest, userID string) (*UrgencyResponse, error) {
+
+	level := req.UrgentVote
+	if level == 0 {
+		level = MinUrgencyLevel
+	}
+	if level < MinUrgencyLevel || level > MaxUrgencyLevel {
+		return nil, fmt.Errorf("urgent_vote must be between %d and %d", MinUrgencyLevel, MaxUrgencyLevel)
+	}
+
+	objectID, repair, err := s.getRepairForVote(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	added, err := s.RepairRepo.AddCoReporter(ctx, objectID, CoReport{
+		UserID:      userID,
+		Comment:     strings.TrimSpace(req.Comment),
+		ImageReport: req.ImageReport,
+		ReportedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, fmt.Errorf("you have already reported this repair")
+	}
+
+	updated, err := s.RepairRepo.SetUrgencyVote(ctx, objectID, UrgencyVote{
+		UserID:  userID,
+		Level:   level,
+		VotedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	return s.afterVote(ctx, repair.OrganizationID, updated, userID), nil
+}
+
+func (s *repairService) buildCoReports(ctx context.Context, repair *Repair, zone *time.Location) []CoReportResponse {
+
+	result := make([]CoReportResponse, 0, len(repair.CoReporters))
+	for _, coReport := range repair.CoReporters {
+		response := CoReportResponse{
+			Comment:     coReport.Comment,
+			ImageReport: s.imageURLs(ctx, coReport.ImageReport),
+			ReportedAt:  coReport.ReportedAt.In(zone),
+		}
+
+		reporter, err := s.UserService.GetUserInfor(ctx, coReport.UserID)
+		if err == nil && reporter != nil {
+			response.ReportBy = *reporter
+		} else {
+			response.ReportBy.UserID = coReport.UserID
+		}
+
+		result = append(result, response)
+	}
+	return result
+}
+
+func (s *repairService) imageURLs(ctx context.Context, keys []string) []string {
+	urls := make([]string, len(keys))
+	for i, key := range keys {
+		imageKey, err := s.UploaderService.GetImageKey(ctx, key)
+		if err == nil && imageKey != nil {
+			urls[i] = imageKey.Url
+		}
+	}
+	return urls
+}
+
+// deleteCoReportImages removes the co-reporters' photos along with the repair.
+func (s *repairService) deleteCoReportImages(ctx context.Context, repair *Repair) error {
+	for _, coReport := range repair.CoReporters {
+		for _, image := range coReport.ImageReport {
+			if err := s.UploaderService.DeleteImageKey(ctx, image); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-service/helper"
+	"todo-service/internal/location"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupByLocation lists repairs per location instead of as one list.
+const GroupByLocation = "location"
+
+// statusOpen stands for every status that still needs work in a status filter.
+const statusOpen = "open"
+
+var openStatuses = []string{StatusPending, StatusAssigned, StatusInProgress, StatusOnHold, StatusReopened}
+
+func (s *repairService) buildRepairFilter(ctx context.Context, query RepairQuery, userID string) (*RepairFilter, error) {
+
+	filter := &RepairFilter{
+		OrganizationID: query.OrganizationID,
+		AssignedTo:     query.AssignedTo,
+		ReportBy:       query.ReportBy,
+		Location:       query.Location,
+	}
+
+	if query.Sort != "" {
+		if _, ok := repairSorts[query.Sort]; !ok {
+			return nil, fmt.Errorf("sort must be one of %s, %s, %s or %s", SortByScore, SortByNewest, SortByOldest, SortByCost)
+		}
+		filter.SortBy = query.Sort
+	}
+
+	if query.Status != "" {
+		statuses, err := parseStatuses(query.Status)
+		if err != nil {
+			return nil, err
+		}
+		filter.Statuses = statuses
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, query.OrganizationID)
+
+	if query.From != "" {
+		from, err := helper.ParseDateTime(query.From, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		filter.From = &from
+	}
+
+	if query.To != "" {
+		to, err := helper.ParseDateTime(query.To, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	var err error
+	if filter.MinCost, err = parseFloatParam("min_cost", query.MinCost); err != nil {
+		return nil, err
+	}
+	if filter.MaxCost, err = parseFloatParam("max_cost", query.MaxCost); err != nil {
+		return nil, err
+	}
+	if filter.MinCost != nil && filter.MaxCost != nil && *filter.MinCost > *filter.MaxCost {
+		return nil, fmt.Errorf("min_cost cannot be greater than max_cost")
+	}
+
+	if filter.MinUrgency, err = parseIntParam("min_urgency", query.MinUrgency); err != nil {
+		return nil, err
+	}
+	if filter.MaxUrgency, err = parseIntParam("max_urgency", query.MaxUrgency); err != nil {
+		return nil, err
+	}
+	if filter.MinUrgency != nil && filter.MaxUrgency != nil && *filter.MinUrgency > *filter.MaxUrgency {
+		return nil, fmt.Errorf("min_urgency cannot be greater than max_urgency")
+	}
+
+	if query.PlanID != "" {
+		planID, err := primitive.ObjectIDFromHex(query.PlanID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plan_id")
+		}
+		filter.PlanID = &planID
+	}
+
+	if query.AssetID != "" {
+		assetID, err := primitive.ObjectIDFromHex(query.AssetID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset_id")
+		}
+		filter.AssetID = &assetID
+	}
+
+	return filter, nil
+}
+
+func parseStatuses(value string) ([]string, error) {
+
+	seen := map[string]bool{}
+	var statuses []string
+
+	for _, status := range strings.Split(value, ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+
+		expanded := []string{status}
+		if status == statusOpen {
+			expanded = openStatuses
+		} else if _, ok := repairTransitions[status]; !ok {
+			return nil, fmt.Errorf("unknown status %s", status)
+		}
+
+		for _, name := range expanded {
+			if !seen[name] {
+				seen[name] = true
+				statuses = append(statuses, name)
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+func parseFloatParam(name, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a number", name)
+	}
+	return &parsed, nil
+}
+
+func parseIntParam(name, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a whole number", name)
+	}
+	return &parsed, nil
+}
+
+// GetRepairsByLocation lists the filtered repairs per location, busiest backlog first, so
+// staff can see what each building is waiting on.
+func (s *repairService) GetRepairsByLocation(ctx context.Context, query RepairQuery, userID string) ([]*LocationRepairsResponse, error) {
+
+	filter, err := s.buildRepairFilter(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	repairs, err := s.RepairRepo.GetRepairs(ctx, *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.buildRepairs(ctx, repairs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byLocation := map[string]*LocationRepairsResponse{}
+	groups := []*LocationRepairsResponse{}
+
+	for i, repair := range repairs {
+		group, ok := byLocation[repair.Location]
+		if !ok {
+			info := responses[i].Location
+			if info.ID == "" {
+				info = location.LocationInfor{ID: repair.Location}
+			}
+			group = &LocationRepairsResponse{Location: info, Repairs: []*RepairResponse{}}
+			byLocation[repair.Location] = group
+			groups = append(groups, group)
+		}
+
+		group.Total++
+		if isOpenStatus(repair.Status) {
+			group.Open++
+		}
+		group.Repairs = append(group.Repairs, responses[i])
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Open != groups[j].Open {
+			return groups[i].Open > groups[j].Open
+		}
+		return strings.ToLower(groups[i].Location.Name) < strings.ToLower(groups[j].Location.Name)
+	})
+
+	return groups, nil
+}
+
+func isOpenStatus(status string) bool {
+	return containsString(openStatuses, status)
+}
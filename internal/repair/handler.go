@@ -2,7 +2,10 @@ package repair
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 	"todo-service/helper"
 	"todo-service/pkg/constants"
 
@@ -42,6 +45,11 @@ func (h *RepairHandler) CreateRepair(c *gin.Context) {
 
 	data, err := h.RepairService.CreateRepair(ctx, req, userID.(string))
 	if err != nil {
+		var duplicate *DuplicateRepairError
+		if errors.As(err, &duplicate) {
+			helper.SendConflict(c, err, duplicate.Candidates)
+			return
+		}
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
 	}
@@ -50,6 +58,12 @@ func (h *RepairHandler) CreateRepair(c *gin.Context) {
 }
 
 func (h *RepairHandler) GetRepairs(c *gin.Context) {
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -58,18 +72,58 @@ func (h *RepairHandler) GetRepairs(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.RepairService.GetRepairs(ctx)
-	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
-		return
+	query := repairQuery(c)
+
+	switch query.GroupBy {
+	case "":
+		data, err := h.RepairService.GetRepairs(ctx, query, userID.(string))
+		if err != nil {
+			helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+			return
+		}
+		helper.SendSuccess(c, 200, "Get repairs successfully", data, 0)
+	case GroupByLocation:
+		data, err := h.RepairService.GetRepairsByLocation(ctx, query, userID.(string))
+		if err != nil {
+			helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+			return
+		}
+		helper.SendSuccess(c, 200, "Get repairs by location successfully", data, 0)
+	default:
+		helper.SendError(c, 400, fmt.Errorf("group_by must be %s", GroupByLocation), helper.ErrInvalidRequest)
+	}
+}
+
+func repairQuery(c *gin.Context) RepairQuery {
+	return RepairQuery{
+		OrganizationID: c.Query("organization_id"),
+		Status:         c.Query("status"),
+		AssignedTo:     c.Query("assigned_to"),
+		ReportBy:       c.Query("report_by"),
+		Location:       c.Query("location"),
+		From:           c.Query("from"),
+		To:             c.Query("to"),
+		MinCost:        c.Query("min_cost"),
+		MaxCost:        c.Query("max_cost"),
+		MinUrgency:     c.Query("min_urgency"),
+		MaxUrgency:     c.Query("max_urgency"),
+		PlanID:         c.Query("plan_id"),
+		AssetID:        c.Query("asset_id"),
+		Sort:           c.Query("sort"),
+		GroupBy:        c.Query("group_by"),
 	}
-	helper.SendSuccess(c, 200, "Get repairs successfully", data, 0)
 }
 
 func (h *RepairHandler) GetRepairByID(c *gin.Context) {
 
 	id := c.Param("id")
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -78,7 +132,7 @@ func (h *RepairHandler) GetRepairByID(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.RepairService.GetRepairByID(ctx, id)
+	data, err := h.RepairService.GetRepairByID(ctx, id, userID.(string))
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
@@ -116,7 +170,7 @@ func (h *RepairHandler) UpdateRepair(c *gin.Context) {
 
 	err := h.RepairService.UpdateRepair(ctx, req, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 	helper.SendSuccess(c, 200, "Update repair successfully", nil, 0)
@@ -146,7 +200,7 @@ func (h *RepairHandler) DeleteRepair(c *gin.Context) {
 
 	err := h.RepairService.DeleteRepair(ctx, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -183,7 +237,7 @@ func (h *RepairHandler) AssignRepair(c *gin.Context) {
 
 	err := h.RepairService.AssignRepair(ctx, id, userID.(string), req)
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 	helper.SendSuccess(c, 200, "Assign repair successfully", nil, 0)
@@ -219,9 +273,912 @@ func (h *RepairHandler) CompleteRepair(c *gin.Context) {
 
 	err := h.RepairService.CompleteRepair(ctx, id, req, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 	helper.SendSuccess(c, 200, "Complete repair successfully", nil, 0)
 
 }
+
+func (h *RepairHandler) GetQRCode(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	payload, err := h.RepairService.GetRepairQRCode(ctx, id)
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendQRCode(c, payload)
+}
+
+func (h *RepairHandler) StartRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.StartRepair(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Start repair successfully", nil, 0)
+}
+
+func (h *RepairHandler) HoldRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req HoldRepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.HoldRepair(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Put repair on hold successfully", nil, 0)
+}
+
+func (h *RepairHandler) ResumeRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.ResumeRepair(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Resume repair successfully", nil, 0)
+}
+
+func (h *RepairHandler) VerifyRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.VerifyRepair(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Verify repair successfully", nil, 0)
+}
+
+func (h *RepairHandler) ReopenRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req ReopenRepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.ReopenRepair(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Reopen repair successfully", nil, 0)
+}
+
+func (h *RepairHandler) CastUrgencyVote(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req UrgencyVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.CastUrgencyVote(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Vote urgency successfully", data, 0)
+}
+
+func (h *RepairHandler) RemoveUrgencyVote(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.RemoveUrgencyVote(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Remove urgency vote successfully", data, 0)
+}
+
+func (h *RepairHandler) CoReportRepair(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req CoReportRepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.CoReportRepair(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Co-report repair successfully", data, 0)
+}
+
+func (h *RepairHandler) GetSLAPolicy(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetSLAPolicy(ctx, organizationID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get SLA policy successfully", data, 0)
+}
+
+func (h *RepairHandler) UpdateSLAPolicy(c *gin.Context) {
+	var req UpdateSLAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.UpdateSLAPolicy(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update SLA policy successfully", data, 0)
+}
+
+func (h *RepairHandler) GetSLAReport(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetSLAReport(ctx, organizationID, c.Query("from"), c.Query("to"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get SLA report successfully", data, 0)
+}
+
+func (h *RepairHandler) GetRepairReport(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetRepairReport(ctx, organizationID, c.Query("from"), c.Query("to"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get repair report successfully", data, 0)
+}
+
+func (h *RepairHandler) ExportRepairReport(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.ExportRepairReport(ctx, organizationID, c.Query("from"), c.Query("to"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("repair-report-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(200, "text/csv; charset=utf-8", data)
+}
+
+func (h *RepairHandler) GetCostBreakdown(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetCostBreakdown(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get repair costs successfully", data, 0)
+}
+
+func (h *RepairHandler) AddLabor(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req AddLaborRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.AddLabor(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Add labor successfully", data, 0)
+}
+
+func (h *RepairHandler) RemoveLabor(c *gin.Context) {
+
+	id := c.Param("id")
+	entryID := c.Param("entry_id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.RemoveLabor(ctx, id, entryID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Remove labor successfully", data, 0)
+}
+
+func (h *RepairHandler) AddExternalCharge(c *gin.Context) {
+
+	id := c.Param("id")
+
+	var req AddExternalChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.AddExternalCharge(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Add external charge successfully", data, 0)
+}
+
+func (h *RepairHandler) RemoveExternalCharge(c *gin.Context) {
+
+	id := c.Param("id")
+	chargeID := c.Param("charge_id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.RemoveExternalCharge(ctx, id, chargeID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Remove external charge successfully", data, 0)
+}
+
+func (h *RepairHandler) IssueInvoice(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.IssueInvoice(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Issue invoice successfully", data, 0)
+}
+
+func (h *RepairHandler) GetInvoice(c *gin.Context) {
+
+	id := c.Param("id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetInvoice(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get invoice successfully", data, 0)
+}
+
+func (h *RepairHandler) GetAssignmentQueue(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetAssignmentQueue(ctx, organizationID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get assignment queue successfully", data, 0)
+}
+
+func (h *RepairHandler) UpdateAssignmentQueue(c *gin.Context) {
+	var req UpdateAssignmentQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.UpdateAssignmentQueue(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update assignment queue successfully", data, 0)
+}
+
+func (h *RepairHandler) CreateMaintenancePlan(c *gin.Context) {
+	var req CreateMaintenancePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.CreateMaintenancePlan(ctx, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Create maintenance plan successfully", data, 0)
+}
+
+func (h *RepairHandler) GetMaintenancePlans(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetMaintenancePlans(ctx, organizationID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get maintenance plans successfully", data, 0)
+}
+
+func (h *RepairHandler) GetMaintenancePlan(c *gin.Context) {
+
+	planID := c.Param("plan_id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetMaintenancePlan(ctx, planID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get maintenance plan successfully", data, 0)
+}
+
+func (h *RepairHandler) UpdateMaintenancePlan(c *gin.Context) {
+
+	planID := c.Param("plan_id")
+
+	var req UpdateMaintenancePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.UpdateMaintenancePlan(ctx, planID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update maintenance plan successfully", data, 0)
+}
+
+func (h *RepairHandler) DeleteMaintenancePlan(c *gin.Context) {
+
+	planID := c.Param("plan_id")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.RepairService.DeleteMaintenancePlan(ctx, planID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Delete maintenance plan successfully", nil, 0)
+}
+
+func (h *RepairHandler) GetMaintenanceCompliance(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.GetMaintenanceCompliance(ctx, organizationID, c.Query("from"), c.Query("to"), userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get maintenance compliance successfully", data, 0)
+}
+
+func (h *RepairHandler) UpdateChecklistItem(c *gin.Context) {
+
+	id := c.Param("id")
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		helper.SendError(c, 400, fmt.Errorf("index must be a number"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.RepairService.UpdateChecklistItem(ctx, id, index, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update checklist successfully", data, 0)
+}
@@ -0,0 +1,414 @@
+package repair
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"todo-service/helper"
+
+	"github.com/go-pdf/fpdf"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	invoiceFolder    = "repair-invoices"
+	invoiceMargin    = 15.0
+	maxInvoicePhotos = 4
+	invoicePhotoBox  = 42.0 // mm, each photo fits a square this size
+	maxPhotoBytes    = 5 << 20
+)
+
+var photoClient = &http.Client{Timeout: 10 * time.Second}
+
+// invoice is what renderInvoice prints, gathered so rendering does no lookups.
+type invoice struct {
+	Repair     *Repair
+	Location   string
+	ReportedBy string
+	RepairedBy string
+	Breakdown  *CostBreakdownResponse
+	Photos     []invoicePhoto
+	Zone       *time.Location
+	IssuedAt   time.Time
+}
+
+type invoicePhoto struct {
+	Data []byte
+	Type string
+}
+
+// IssueInvoice renders the repair's invoice, stores it through the uploader and replaces
+// any invoice issued before.
+func (s *repairService) IssueInvoice(ctx context.Context, id string, userID string) (*InvoiceResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	if err := s.Policy.CanIssueInvoice(ctx, repair, userID); err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.costBreakdown(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	data := invoice{
+		Repair:    repair,
+		Location:  repair.Location,
+		Breakdown: breakdown,
+		Photos:    s.invoicePhotos(ctx, repair),
+		Zone:      s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID),
+		IssuedAt:  now,
+	}
+
+	location, err := s.LocationService.GetLocationByID(ctx, repair.Location)
+	if err == nil && location != nil && location.Name != "" {
+		data.Location = location.Name
+	}
+
+	data.ReportedBy = s.userName(ctx, repair.ReportBy)
+	if repair.RepairBy != nil {
+		data.RepairedBy = s.userName(ctx, *repair.RepairBy)
+	}
+
+	pdf, err := renderInvoice(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render invoice: %v", err)
+	}
+
+	reference := repair.JobCode
+	if reference == "" {
+		reference = repair.ID.Hex()
+	}
+
+	uploaded, err := s.UploaderService.UploadPDF(ctx, fmt.Sprintf("invoice-%s.pdf", reference), invoiceFolder, pdf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store invoice: %v", err)
+	}
+
+	if err := s.RepairRepo.SetInvoice(ctx, objectID, uploaded.Key, now); err != nil {
+		return nil, err
+	}
+
+	// Bring the repair's total in line with the invoice, repricing whatever labor and charges
+	// it has by now rather than overwriting a newer total
+	basis := CostBasis{Parts: breakdown.Parts.Total, TaxRate: breakdown.TaxRate}
+	if err := s.RepairRepo.RefreshTotalCost(ctx, objectID, basis); err != nil {
+		log.Printf("[WARN] failed to update total cost of repair %s: %v", id, err)
+	}
+
+	if repair.InvoiceKey != "" && repair.InvoiceKey != uploaded.Key {
+		if err := s.UploaderService.DeletePDFKey(ctx, repair.InvoiceKey); err != nil {
+			log.Printf("[WARN] failed to delete previous invoice %s of repair %s: %v", repair.InvoiceKey, id, err)
+		}
+	}
+
+	url := uploaded.Url
+	if url == "" {
+		if file, err := s.UploaderService.GetPrivatePDFKey(ctx, uploaded.Key); err == nil && file != nil {
+			url = file.Url
+		}
+	}
+
+	return &InvoiceResponse{
+		Key:      uploaded.Key,
+		URL:      url,
+		IssuedAt: now.In(data.Zone),
+	}, nil
+}
+
+func (s *repairService) GetInvoice(ctx context.Context, id string, userID string) (*InvoiceResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	if err := s.Policy.CanViewCosts(ctx, repair, userID, s.isOrganizationStaff(ctx, userID, repair.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	if repair.InvoiceKey == "" || repair.InvoiceAt == nil {
+		return nil, fmt.Errorf("no invoice has been issued for this repair")
+	}
+
+	file, err := s.UploaderService.GetPrivatePDFKey(ctx, repair.InvoiceKey)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, fmt.Errorf("invoice file not found")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID)
+
+	return &InvoiceResponse{
+		Key:      repair.InvoiceKey,
+		URL:      file.Url,
+		IssuedAt: repair.InvoiceAt.In(loc),
+	}, nil
+}
+
+func (s *repairService) userName(ctx context.Context, userID string) string {
+	info, err := s.UserService.GetUserInfor(ctx, userID)
+	if err != nil || info == nil || info.UserName == "" {
+		return userID
+	}
+	return info.UserName
+}
+
+// invoicePhotos downloads the first few report and repair photos. Photos that cannot be
+// fetched or decoded are left out rather than failing the invoice.
+func (s *repairService) invoicePhotos(ctx context.Context, repair *Repair) []invoicePhoto {
+
+	keys := append([]string{}, repair.ImageReport...)
+	for _, image := range repair.ImageRepair {
+		if image != nil {
+			keys = append(keys, *image)
+		}
+	}
+
+	var photos []invoicePhoto
+	for _, url := range s.imageURLs(ctx, keys) {
+		if len(photos) == maxInvoicePhotos {
+			break
+		}
+		if url == "" {
+			continue
+		}
+
+		photo, err := fetchPhoto(ctx, url)
+		if err != nil {
+			log.Printf("[WARN] leaving photo of repair %s out of its invoice: %v", repair.ID.Hex(), err)
+			continue
+		}
+		photos = append(photos, *photo)
+	}
+
+	return photos
+}
+
+func fetchPhoto(ctx context.Context, url string) (*invoicePhoto, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := photoClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPhotoBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPhotoBytes {
+		return nil, fmt.Errorf("photo is larger than %d bytes", maxPhotoBytes)
+	}
+
+	// fpdf stops the whole document on an image it cannot read, so check it first
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "jpeg":
+		return &invoicePhoto{Data: data, Type: "JPG"}, nil
+	case "png":
+		return &invoicePhoto{Data: data, Type: "PNG"}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported photo format %s", format)
+}
+
+// renderInvoice prints an A4 invoice: the job, its location and people, photos, then the
+// parts, labor and external charges with the totals. Text uses the TTF in INVOICE_FONT_PATH,
+// or LABEL_FONT_PATH when only that is set.
+func renderInvoice(data invoice) ([]byte, error) {
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(invoiceMargin, invoiceMargin, invoiceMargin)
+	pdf.SetAutoPageBreak(true, invoiceMargin)
+
+	fontPath := os.Getenv("INVOICE_FONT_PATH")
+	if fontPath == "" {
+		fontPath = os.Getenv("LABEL_FONT_PATH")
+	}
+
+	family, translate, err := helper.LoadPDFFont(pdf, fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load invoice font: %v", err)
+	}
+
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 2*invoiceMargin
+	repair := data.Repair
+
+	pdf.AddPage()
+
+	pdf.SetFont(family, "B", 18)
+	pdf.CellFormat(width, 10, translate("Repair invoice"), "", 1, "L", false, 0, "")
+
+	title := repair.JobName
+	if repair.JobCode != "" {
+		title = repair.JobCode + " - " + repair.JobName
+	}
+	pdf.SetFont(family, "B", 12)
+	pdf.CellFormat(width, 7, helper.FitPDFText(pdf, translate, title, width), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont(family, "", 10)
+	details := [][2]string{
+		{"Location", data.Location},
+		{"Reported", fmt.Sprintf("%s by %s", repair.DateReport.In(data.Zone).Format("2006-01-02 15:04"), data.ReportedBy)},
+	}
+	if repair.DateRepair != nil {
+		details = append(details, [2]string{"Completed", fmt.Sprintf("%s by %s", repair.DateRepair.In(data.Zone).Format("2006-01-02 15:04"), data.RepairedBy)})
+	}
+	details = append(details, [2]string{"Issued", data.IssuedAt.In(data.Zone).Format("2006-01-02 15:04")})
+
+	for _, detail := range details {
+		pdf.CellFormat(30, 6, translate(detail[0]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(width-30, 6, helper.FitPDFText(pdf, translate, detail[1], width-30), "", 1, "L", false, 0, "")
+	}
+
+	if len(data.Photos) > 0 {
+		pdf.Ln(4)
+		y := pdf.GetY()
+		for i, photo := range data.Photos {
+			name := fmt.Sprintf("photo-%d", i)
+			options := fpdf.ImageOptions{ImageType: photo.Type}
+			info := pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(photo.Data))
+			if info == nil {
+				continue
+			}
+
+			// Fit the photo in its box without stretching it
+			w, h := invoicePhotoBox, invoicePhotoBox
+			if info.Width() > info.Height() {
+				h = invoicePhotoBox * info.Height() / info.Width()
+			} else {
+				w = invoicePhotoBox * info.Width() / info.Height()
+			}
+			x := invoiceMargin + float64(i)*(invoicePhotoBox+3)
+			pdf.ImageOptions(name, x, y, w, h, false, options, 0, "")
+		}
+		pdf.SetY(y + invoicePhotoBox + 2)
+	}
+
+	breakdown := data.Breakdown
+	renderCostSection(pdf, family, translate, width, "Parts", "Qty", breakdown.Parts)
+	renderCostSection(pdf, family, translate, width, "Labor", "Hours", breakdown.Labor)
+	renderCostSection(pdf, family, translate, width, "External charges", "Qty", breakdown.External)
+
+	pdf.Ln(4)
+	totals := [][2]string{
+		{"Subtotal", formatMoney(breakdown.Subtotal)},
+		{fmt.Sprintf("Tax (%g%%)", breakdown.TaxRate), formatMoney(breakdown.Tax)},
+		{"Total", formatMoney(breakdown.Total)},
+	}
+	for i, total := range totals {
+		style := ""
+		if i == len(totals)-1 {
+			style = "B"
+		}
+		pdf.SetFont(family, style, 11)
+		pdf.CellFormat(width-35, 7, translate(total[0]), "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, total[1], "", 1, "R", false, 0, "")
+	}
+
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderCostSection prints one table of line items with its total. Empty sections are
+// skipped so an invoice only lists what was billed.
+func renderCostSection(pdf *fpdf.Fpdf, family string, translate func(string) string, width float64, title, quantity string, section CostSection) {
+
+	if len(section.Lines) == 0 {
+		return
+	}
+
+	columns := []float64{width - 85, 20, 30, 35}
+
+	pdf.Ln(4)
+	pdf.SetFont(family, "B", 11)
+	pdf.CellFormat(width, 7, translate(title), "", 1, "L", false, 0, "")
+
+	pdf.SetFont(family, "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	for i, header := range []string{"Item", quantity, "Unit price", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(columns[i], 6, translate(header), "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(family, "", 9)
+	for _, line := range section.Lines {
+		item := line.Reference
+		if line.Description != "" {
+			item += ": " + line.Description
+		}
+		pdf.CellFormat(columns[0], 6, helper.FitPDFText(pdf, translate, item, columns[0]-1), "", 0, "L", false, 0, "")
+		pdf.CellFormat(columns[1], 6, fmt.Sprintf("%g", line.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[2], 6, formatMoney(line.UnitPrice), "", 0, "R", false, 0, "")
+		pdf.CellFormat(columns[3], 6, formatMoney(line.Amount), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont(family, "B", 9)
+	pdf.CellFormat(width-columns[3], 6, translate(title+" total"), "T", 0, "R", false, 0, "")
+	pdf.CellFormat(columns[3], 6, formatMoney(section.Total), "T", 1, "R", false, 0, "")
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,735 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/location"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxRecurrenceEvery = 100
+	maxLeadDays        = 90
+	maxChecklistItems  = 50
+	maxExpectedParts   = 50
+	// maxServicesPerRun bounds how many services of one plan a run generates, so a scheduler
+	// that was down for a while catches up gradually instead of flooding the repair list
+	maxServicesPerRun        = 10
+	defaultComplianceDays    = 90
+	maxComplianceRangeDays   = 366
+	defaultMaintenanceReason = "Scheduled maintenance"
+)
+
+// Outcomes of a planned service in the compliance view
+const (
+	OutcomeOnTime   = "on_time"
+	OutcomeLate     = "late"
+	OutcomeMissed   = "missed"
+	OutcomeUpcoming = "upcoming"
+)
+
+func (s *repairService) CreateMaintenancePlan(ctx context.Context, req CreateMaintenancePlanRequest, userID string) (*MaintenancePlanResponse, error) {
+
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanManagePlans(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if req.StartAt == "" {
+		return nil, fmt.Errorf("start_at is required")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, req.OrganizationID)
+	startAt, err := helper.ParseDateTime(req.StartAt, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_at format: %v", err)
+	}
+
+	now := time.Now().UTC()
+	plan := &MaintenancePlan{
+		ID:              primitive.NewObjectID(),
+		OrganizationID:  req.OrganizationID,
+		Name:            strings.TrimSpace(req.Name),
+		Description:     strings.TrimSpace(req.Description),
+		Location:        req.Location,
+		Category:        strings.ToLower(strings.TrimSpace(req.Category)),
+		Recurrence:      req.Recurrence,
+		StartAt:         startAt.UTC(),
+		LeadDays:        req.LeadDays,
+		Checklist:       req.Checklist,
+		DefaultAssignee: req.DefaultAssignee,
+		ExpectedParts:   req.ExpectedParts,
+		Active:          true,
+		CreatedBy:       userID,
+		UpdatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.validatePlan(ctx, plan, true, true); err != nil {
+		return nil, err
+	}
+
+	orgLoc := s.SettingService.GetOrganizationLocation(ctx, plan.OrganizationID)
+	skipPastServices(plan, now, orgLoc)
+
+	if err := s.RepairRepo.CreateMaintenancePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return s.buildPlanResponse(ctx, plan, loc, map[string]*location.LocationInfor{}), nil
+}
+
+func (s *repairService) GetMaintenancePlans(ctx context.Context, organizationID string, userID string) ([]*MaintenancePlanResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewPlans(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	plans, err := s.RepairRepo.GetMaintenancePlans(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+	locations := map[string]*location.LocationInfor{}
+
+	results := make([]*MaintenancePlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		results = append(results, s.buildPlanResponse(ctx, plan, loc, locations))
+	}
+
+	return results, nil
+}
+
+func (s *repairService) GetMaintenancePlan(ctx context.Context, id string, userID string) (*MaintenancePlanResponse, error) {
+
+	plan, err := s.getMaintenancePlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanViewPlans(ctx, userID, s.isOrganizationStaff(ctx, userID, plan.OrganizationID)); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, plan.OrganizationID)
+
+	return s.buildPlanResponse(ctx, plan, loc, map[string]*location.LocationInfor{}), nil
+}
+
+// UpdateMaintenancePlan changes a plan. A new recurrence or start date reschedules it from
+// today, and so does resuming it, so services missed while paused are not generated late.
+func (s *repairService) UpdateMaintenancePlan(ctx context.Context, id string, req UpdateMaintenancePlanRequest, userID string) (*MaintenancePlanResponse, error) {
+
+	plan, err := s.getMaintenancePlan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanManagePlans(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, plan.OrganizationID)
+	reschedule := false
+	locationChanged := false
+	assigneeChanged := false
+
+	if req.Name != nil {
+		plan.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		plan.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Location != nil && *req.Location != plan.Location {
+		plan.Location = *req.Location
+		locationChanged = true
+	}
+	if req.Category != nil {
+		plan.Category = strings.ToLower(strings.TrimSpace(*req.Category))
+	}
+	if req.Recurrence != nil && *req.Recurrence != plan.Recurrence {
+		plan.Recurrence = *req.Recurrence
+		plan.Occurrence = 0
+		reschedule = true
+	}
+	if req.StartAt != nil {
+		startAt, err := helper.ParseDateTime(*req.StartAt, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_at format: %v", err)
+		}
+		if !startAt.Equal(plan.StartAt) {
+			plan.StartAt = startAt.UTC()
+			plan.Occurrence = 0
+			reschedule = true
+		}
+	}
+	if req.LeadDays != nil {
+		plan.LeadDays = *req.LeadDays
+	}
+	if req.Checklist != nil {
+		plan.Checklist = req.Checklist
+	}
+	if req.DefaultAssignee != nil {
+		if *req.DefaultAssignee == "" {
+			plan.DefaultAssignee = nil
+		} else {
+			plan.DefaultAssignee = req.DefaultAssignee
+			assigneeChanged = true
+		}
+	}
+	if req.ExpectedParts != nil {
+		plan.ExpectedParts = *req.ExpectedParts
+	}
+	if req.Active != nil {
+		if *req.Active && !plan.Active {
+			reschedule = true
+		}
+		plan.Active = *req.Active
+	}
+
+	if err := s.validatePlan(ctx, plan, locationChanged, assigneeChanged); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	orgLoc := s.SettingService.GetOrganizationLocation(ctx, plan.OrganizationID)
+	if reschedule {
+		skipPastServices(plan, now, orgLoc)
+	} else {
+		setNextService(plan, orgLoc)
+	}
+
+	plan.UpdatedBy = userID
+	plan.UpdatedAt = now
+
+	if err := s.RepairRepo.UpdateMaintenancePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return s.buildPlanResponse(ctx, plan, loc, map[string]*location.LocationInfor{}), nil
+}
+
+// DeleteMaintenancePlan removes the plan. Repairs it already generated stay, as work in
+// progress and as its compliance history.
+func (s *repairService) DeleteMaintenancePlan(ctx context.Context, id string, userID string) error {
+
+	plan, err := s.getMaintenancePlan(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Policy.CanManagePlans(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.RepairRepo.DeleteMaintenancePlan(ctx, plan.ID)
+}
+
+func (s *repairService) getMaintenancePlan(ctx context.Context, id string) (*MaintenancePlan, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan id")
+	}
+
+	plan, err := s.RepairRepo.GetMaintenancePlanByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, fmt.Errorf("maintenance plan not found")
+	}
+
+	return plan, nil
+}
+
+// validatePlan checks and tidies the plan. The location and default assignee are looked up
+// only when they are new, since both calls go to other services.
+func (s *repairService) validatePlan(ctx context.Context, plan *MaintenancePlan, checkLocation, checkAssignee bool) error {
+
+	if plan.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if plan.Location == "" {
+		return fmt.Errorf("location is required")
+	}
+
+	switch plan.Recurrence.Unit {
+	case RecurrenceDay, RecurrenceWeek, RecurrenceMonth, RecurrenceYear:
+	default:
+		return fmt.Errorf("recurrence unit must be one of %s, %s, %s or %s", RecurrenceDay, RecurrenceWeek, RecurrenceMonth, RecurrenceYear)
+	}
+
+	if plan.Recurrence.Every < 1 || plan.Recurrence.Every > maxRecurrenceEvery {
+		return fmt.Errorf("recurrence every must be between 1 and %d", maxRecurrenceEvery)
+	}
+
+	if plan.LeadDays < 0 || plan.LeadDays > maxLeadDays {
+		return fmt.Errorf("lead_days must be between 0 and %d", maxLeadDays)
+	}
+
+	plan.Checklist = compactStrings(plan.Checklist)
+	if len(plan.Checklist) > maxChecklistItems {
+		return fmt.Errorf("a checklist cannot have more than %d items", maxChecklistItems)
+	}
+
+	if len(plan.ExpectedParts) > maxExpectedParts {
+		return fmt.Errorf("a plan cannot expect more than %d parts", maxExpectedParts)
+	}
+	parts := make([]ExpectedPart, 0, len(plan.ExpectedParts))
+	for _, part := range plan.ExpectedParts {
+		part.Name = strings.TrimSpace(part.Name)
+		if part.Name == "" {
+			return fmt.Errorf("name is required for every expected part")
+		}
+		if part.Quantity < 1 {
+			return fmt.Errorf("quantity of %s must be at least 1", part.Name)
+		}
+		if part.ProductID != "" && !primitive.IsValidObjectID(part.ProductID) {
+			return fmt.Errorf("invalid product_id of %s", part.Name)
+		}
+		parts = append(parts, part)
+	}
+	plan.ExpectedParts = parts
+
+	if checkLocation {
+		if _, err := s.LocationService.GetLocationByID(ctx, plan.Location); err != nil {
+			return fmt.Errorf("location %s not found: %v", plan.Location, err)
+		}
+	}
+
+	if checkAssignee && plan.DefaultAssignee != nil {
+		if err := s.ensureStaff(ctx, *plan.DefaultAssignee, plan.OrganizationID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// serviceDueAt is the due date of the plan's nth service, counted in the organization's zone
+// so services keep their time of day across daylight saving changes. A monthly plan that
+// starts on the 31st is due on the last day of shorter months.
+func serviceDueAt(plan *MaintenancePlan, n int, loc *time.Location) time.Time {
+
+	start := plan.StartAt.In(loc)
+	every := plan.Recurrence.Every * n
+
+	switch plan.Recurrence.Unit {
+	case RecurrenceDay:
+		return start.AddDate(0, 0, every)
+	case RecurrenceWeek:
+		return start.AddDate(0, 0, 7*every)
+	case RecurrenceYear:
+		every *= 12
+	}
+
+	year, month, day := start.Date()
+	first := time.Date(year, month+time.Month(every), 1, start.Hour(), start.Minute(), start.Second(), 0, loc)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, start.Hour(), start.Minute(), start.Second(), 0, loc)
+}
+
+// setNextService points the plan at the due date of its current occurrence.
+func setNextService(plan *MaintenancePlan, loc *time.Location) {
+	dueAt := serviceDueAt(plan, plan.Occurrence, loc)
+	plan.NextDueAt = dueAt.UTC()
+	plan.NextGenerateAt = dueAt.AddDate(0, 0, -plan.LeadDays).UTC()
+}
+
+// skipPastServices moves the plan on to its first service due from now on.
+func skipPastServices(plan *MaintenancePlan, now time.Time, loc *time.Location) {
+	for serviceDueAt(plan, plan.Occurrence, loc).Before(now) {
+		plan.Occurrence++
+	}
+	setNextService(plan, loc)
+}
+
+// GenerateMaintenanceRepairs creates the preventive repairs whose lead time has come. Each
+// plan is moved on only after its service exists, and only from the occurrence it was read
+// at, so concurrent runs neither skip nor repeat a service.
+func (s *repairService) GenerateMaintenanceRepairs(ctx context.Context, now time.Time) (int, error) {
+
+	plans, err := s.RepairRepo.GetDueMaintenancePlans(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, plan := range plans {
+		loc := s.SettingService.GetOrganizationLocation(ctx, plan.OrganizationID)
+
+		for i := 0; i < maxServicesPerRun && !plan.NextGenerateAt.After(now); i++ {
+			created, err := s.generateService(ctx, plan, now)
+			if err != nil {
+				log.Printf("[WARN] failed to generate service of maintenance plan %s due %s: %v", plan.ID.Hex(), plan.NextDueAt.Format(time.RFC3339), err)
+				break
+			}
+			if created {
+				generated++
+			}
+
+			previous := plan.Occurrence
+			plan.Occurrence++
+			setNextService(plan, loc)
+
+			advanced, err := s.RepairRepo.AdvanceMaintenancePlan(ctx, plan, previous)
+			if err != nil {
+				log.Printf("[WARN] failed to advance maintenance plan %s: %v", plan.ID.Hex(), err)
+				break
+			}
+			// Another run or an edit moved the plan on; the next run starts from there
+			if !advanced {
+				break
+			}
+		}
+	}
+
+	return generated, nil
+}
+
+// generateService creates the repair for the plan's next service. It reports false when the
+// service already has one.
+func (s *repairService) generateService(ctx context.Context, plan *MaintenancePlan, now time.Time) (bool, error) {
+
+	exists, err := s.RepairRepo.HasPreventiveRepair(ctx, plan.ID, plan.NextDueAt)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	checklist := make([]ChecklistItem, len(plan.Checklist))
+	for i, item := range plan.Checklist {
+		checklist[i] = ChecklistItem{Item: item}
+	}
+
+	comment := plan.Description
+	if comment == "" {
+		comment = defaultMaintenanceReason
+	}
+
+	id := primitive.NewObjectID()
+	repair := &Repair{
+		ID:             id,
+		OrganizationID: plan.OrganizationID,
+		QRCode:         fmt.Sprintf("SENBOX.ORG[REPAIR]:%s", id.Hex()),
+		JobName:        plan.Name,
+		Location:       plan.Location,
+		Category:       plan.Category,
+		Preventive:     true,
+		Status:         StatusPending,
+		Comment:        comment,
+		DateReport:     now,
+		ReportBy:       plan.CreatedBy,
+		ImageReport:    []string{},
+		StatusHistory:  []StatusChange{{To: StatusPending, By: MaintenanceScheduler, At: now}},
+		Maintenance: &MaintenanceInfo{
+			PlanID:        plan.ID,
+			DueAt:         plan.NextDueAt,
+			Checklist:     checklist,
+			ExpectedParts: plan.ExpectedParts,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if plan.DefaultAssignee != nil {
+		assignee := *plan.DefaultAssignee
+		repair.AssignedTo = &assignee
+		repair.AssignedAt = &now
+		recordStatus(repair, StatusAssigned, MaintenanceScheduler, "", now)
+	}
+
+	applySLA(repair, nil, now)
+
+	if err := s.insertRepair(ctx, repair); err != nil {
+		// Another run generated the service between the check and the insert
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if repair.AssignedTo != nil {
+		s.notifyAssignee(ctx, repair, *repair.AssignedTo)
+	} else {
+		s.autoAssign(ctx, repair)
+	}
+
+	return true, nil
+}
+
+// GetMaintenanceCompliance reports, per plan, the services due within the range that were
+// done late or missed. It defaults to the last 90 days.
+func (s *repairService) GetMaintenanceCompliance(ctx context.Context, organizationID, from, to string, userID string) (*MaintenanceComplianceResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewPlans(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+	now := time.Now().UTC()
+
+	toTime := now
+	if to != "" {
+		parsed, err := helper.ParseDateTime(to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		toTime = parsed
+	}
+
+	fromTime := helper.StartOfDay(toTime.AddDate(0, 0, -defaultComplianceDays), loc).UTC()
+	if from != "" {
+		parsed, err := helper.ParseDateTime(from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		fromTime = parsed
+	}
+
+	if !fromTime.Before(toTime) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	if toTime.Sub(fromTime) > maxComplianceRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("the range cannot be longer than %d days", maxComplianceRangeDays)
+	}
+
+	plans, err := s.RepairRepo.GetMaintenancePlans(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	repairs, err := s.RepairRepo.GetPreventiveRepairs(ctx, organizationID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every plan is listed, even without services in the range, then plans deleted since
+	byPlan := map[primitive.ObjectID]*PlanComplianceResponse{}
+	var order []primitive.ObjectID
+	for _, plan := range plans {
+		byPlan[plan.ID] = &PlanComplianceResponse{
+			PlanID:     plan.ID,
+			Name:       plan.Name,
+			Location:   plan.Location,
+			Active:     plan.Active,
+			Exceptions: []ServiceResponse{},
+		}
+		order = append(order, plan.ID)
+	}
+
+	response := &MaintenanceComplianceResponse{
+		OrganizationID: organizationID,
+		From:           fromTime.In(loc),
+		To:             toTime.In(loc),
+	}
+
+	for _, repair := range repairs {
+		if repair.Maintenance == nil {
+			continue
+		}
+
+		entry, ok := byPlan[repair.Maintenance.PlanID]
+		if !ok {
+			entry = &PlanComplianceResponse{
+				PlanID:     repair.Maintenance.PlanID,
+				Name:       repair.JobName,
+				Location:   repair.Location,
+				Exceptions: []ServiceResponse{},
+			}
+			byPlan[repair.Maintenance.PlanID] = entry
+			order = append(order, repair.Maintenance.PlanID)
+		}
+
+		outcome := serviceOutcome(repair, now)
+		entry.Total++
+		response.Total++
+
+		switch outcome {
+		case OutcomeOnTime:
+			entry.OnTime++
+			response.OnTime++
+			continue
+		case OutcomeUpcoming:
+			entry.Upcoming++
+			response.Upcoming++
+			continue
+		case OutcomeLate:
+			entry.Late++
+			response.Late++
+		case OutcomeMissed:
+			entry.Missed++
+			response.Missed++
+		}
+
+		entry.Exceptions = append(entry.Exceptions, ServiceResponse{
+			RepairID:    repair.ID,
+			JobCode:     repair.JobCode,
+			Status:      repair.Status,
+			Outcome:     outcome,
+			AssignedTo:  repair.AssignedTo,
+			DueAt:       repair.Maintenance.DueAt.In(loc),
+			CompletedAt: helper.InLocation(repair.DateRepair, loc),
+		})
+	}
+
+	response.Plans = make([]PlanComplianceResponse, 0, len(order))
+	for _, planID := range order {
+		entry := byPlan[planID]
+		entry.CompliancePercent = compliancePercent(entry.OnTime, entry.Late+entry.Missed)
+		response.Plans = append(response.Plans, *entry)
+	}
+	response.CompliancePercent = compliancePercent(response.OnTime, response.Late+response.Missed)
+
+	// Plans with the most missed services first, as they need attention soonest
+	sort.SliceStable(response.Plans, func(i, j int) bool {
+		if response.Plans[i].Missed != response.Plans[j].Missed {
+			return response.Plans[i].Missed > response.Plans[j].Missed
+		}
+		return response.Plans[i].Late > response.Plans[j].Late
+	})
+
+	return response, nil
+}
+
+// serviceOutcome compares when a preventive repair was done with when it was due. One that
+// is not done yet is missed once its due date passed.
+func serviceOutcome(repair *Repair, now time.Time) string {
+	dueAt := repair.Maintenance.DueAt
+	switch {
+	case repair.DateRepair != nil && repair.DateRepair.After(dueAt):
+		return OutcomeLate
+	case repair.DateRepair != nil:
+		return OutcomeOnTime
+	case now.After(dueAt):
+		return OutcomeMissed
+	default:
+		return OutcomeUpcoming
+	}
+}
+
+func compliancePercent(met, breached int) float64 {
+	if met+breached == 0 {
+		return 0
+	}
+	return math.Round(float64(met)*1000/float64(met+breached)) / 10
+}
+
+// UpdateChecklistItem ticks one step of a preventive repair's checklist on or off.
+func (s *repairService) UpdateChecklistItem(ctx context.Context, id string, index int, req ChecklistItemRequest, userID string) (*MaintenanceResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanTickChecklist(ctx, repair, userID); err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(repair.Maintenance.Checklist) {
+		return nil, fmt.Errorf("checklist item %d not found", index)
+	}
+
+	item := ChecklistItem{Item: repair.Maintenance.Checklist[index].Item, Done: req.Done}
+	if req.Done {
+		now := time.Now()
+		item.DoneBy = &userID
+		item.DoneAt = &now
+	}
+
+	updated, err := s.RepairRepo.SetChecklistItem(ctx, objectID, index, item)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("checklist item %d not found", index)
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID)
+
+	return buildMaintenance(updated.Maintenance, loc), nil
+}
+
+func (s *repairService) buildPlanResponse(ctx context.Context, plan *MaintenancePlan, loc *time.Location, locations map[string]*location.LocationInfor) *MaintenancePlanResponse {
+
+	response := &MaintenancePlanResponse{
+		ID:             plan.ID,
+		OrganizationID: plan.OrganizationID,
+		Name:           plan.Name,
+		Description:    plan.Description,
+		Category:       plan.Category,
+		Recurrence:     plan.Recurrence,
+		StartAt:        plan.StartAt.In(loc),
+		LeadDays:       plan.LeadDays,
+		Checklist:      plan.Checklist,
+		ExpectedParts:  plan.ExpectedParts,
+		Active:         plan.Active,
+		CreatedBy:      plan.CreatedBy,
+		UpdatedBy:      plan.UpdatedBy,
+		CreatedAt:      plan.CreatedAt.In(loc),
+		UpdatedAt:      plan.UpdatedAt.In(loc),
+	}
+
+	// A paused plan has no next service until it is resumed
+	if plan.Active {
+		response.NextDueAt = helper.InLocation(&plan.NextDueAt, loc)
+		response.NextGenerateAt = helper.InLocation(&plan.NextGenerateAt, loc)
+	}
+
+	info, ok := locations[plan.Location]
+	if !ok {
+		var err error
+		info, err = s.LocationService.GetLocationByID(ctx, plan.Location)
+		if err != nil {
+			log.Printf("[WARN] failed to get location %s of maintenance plan %s: %v", plan.Location, plan.ID.Hex(), err)
+			info = nil
+		}
+		locations[plan.Location] = info
+	}
+	if info != nil {
+		response.Location = *info
+	}
+
+	if plan.DefaultAssignee != nil {
+		assignee, err := s.UserService.GetUserInfor(ctx, *plan.DefaultAssignee)
+		if err == nil && assignee != nil {
+			response.DefaultAssignee = assignee
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,58 @@
+package repair
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const DefaultMaintenanceWorkerInterval = 30 * time.Minute
+
+// MaintenanceWorker generates the preventive repairs of maintenance plans as their services
+// come within the plan's lead time. A service generates one repair however many instances run.
+type MaintenanceWorker struct {
+	RepairService RepairService
+	Interval      time.Duration
+}
+
+func NewMaintenanceWorker(repairService RepairService, interval time.Duration) *MaintenanceWorker {
+	if interval <= 0 {
+		interval = DefaultMaintenanceWorkerInterval
+	}
+	return &MaintenanceWorker{
+		RepairService: repairService,
+		Interval:      interval,
+	}
+}
+
+// Start runs the worker until ctx is cancelled.
+func (w *MaintenanceWorker) Start(ctx context.Context) {
+	log.Printf("Maintenance worker started, checking every %s", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx, time.Now().UTC()); err != nil {
+			log.Printf("[WARN] maintenance worker run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Maintenance worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *MaintenanceWorker) RunOnce(ctx context.Context, now time.Time) error {
+	generated, err := w.RepairService.GenerateMaintenanceRepairs(ctx, now)
+	if err != nil {
+		return err
+	}
+	if generated > 0 {
+		log.Printf("Generated %d preventive repairs", generated)
+	}
+	return nil
+}
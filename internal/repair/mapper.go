@@ -2,12 +2,14 @@ package repair
 
 import (
 	"log"
+	"time"
+	"todo-service/helper"
 	"todo-service/internal/location"
 	"todo-service/internal/shop"
 	"todo-service/internal/user"
 )
 
-func buildRepairResponse(repair *Repair, reportBy interface{}, repairBy interface{}, locationInfor interface{}, imageReport []string, imageRepair []string, shopItems *shop.RepairItemsSummary) *RepairResponse {
+func buildRepairResponse(repair *Repair, reportBy interface{}, repairBy interface{}, locationInfor interface{}, imageReport []string, imageRepair []string, shopItems *shop.RepairItemsSummary, zone *time.Location, userID string) *RepairResponse {
 
 	var loc location.LocationInfor
 
@@ -53,26 +55,116 @@ func buildRepairResponse(repair *Repair, reportBy interface{}, repairBy interfac
 		}
 	}
 
-	return &RepairResponse{
+	response := &RepairResponse{
 		ID:             repair.ID,
 		OrganizationID: repair.OrganizationID,
 		JobNumber:      repair.JobNumber,
+		JobCode:        repair.JobCode,
 		JobName:        repair.JobName,
 		QRCode:         repair.QRCode,
 		Location:       loc,
+		AssetID:        repair.AssetID,
+		Category:       repair.Category,
+		Preventive:     repair.Preventive,
 		Status:         repair.Status,
-		DateReport:     repair.DateReport,
+		DateReport:     repair.DateReport.In(zone),
 		ReportBy:       reporter,
 		UrgentVote:     repair.UrgentVote,
+		VoteCount:      len(repair.Votes),
+		MyVote:         myVote(repair, userID),
+		EscalatedAt:    helper.InLocation(repair.EscalatedAt, zone),
 		Comment:        repair.Comment,
 		ImageReport:    imageReport,
-		DateRepair:     repair.DateRepair,
+		DateRepair:     helper.InLocation(repair.DateRepair, zone),
 		RepairBy:       repairer,
 		CommentRepair:  repair.CommentRepair,
 		ImageRepair:    imageRepairPtrs,
 		ShopItems:      shopItems,
 		TotalCost:      repair.TotalCost,
-		CreatedAt:      repair.CreatedAt,
-		UpdatedAt:      repair.UpdatedAt,
+		SLA:            buildSLAResponse(repair, zone),
+		StatusHistory:  buildStatusHistory(repair.StatusHistory, zone),
+		Labor:          buildLabor(repair.Labor, zone),
+		InvoiceAt:      helper.InLocation(repair.InvoiceAt, zone),
+		Maintenance:    buildMaintenance(repair.Maintenance, zone),
+		CreatedAt:      repair.CreatedAt.In(zone),
+		UpdatedAt:      repair.UpdatedAt.In(zone),
 	}
+	response.ExternalCharges = buildExternalCharges(repair.ExternalCharges, zone)
+
+	return response
+}
+
+func buildSLAResponse(repair *Repair, zone *time.Location) *SLAResponse {
+	if repair.SLABand == "" {
+		return nil
+	}
+
+	return &SLAResponse{
+		Band:               repair.SLABand,
+		AssignDueBy:        helper.InLocation(repair.AssignDueBy, zone),
+		CompleteDueBy:      helper.InLocation(repair.CompleteDueBy, zone),
+		AssignedAt:         helper.InLocation(repair.AssignedAt, zone),
+		AssignBreached:     repair.AssignBreachedAt != nil,
+		AssignBreachedAt:   helper.InLocation(repair.AssignBreachedAt, zone),
+		CompleteBreached:   repair.CompleteBreachedAt != nil,
+		CompleteBreachedAt: helper.InLocation(repair.CompleteBreachedAt, zone),
+	}
+}
+
+func buildStatusHistory(history []StatusChange, zone *time.Location) []StatusChange {
+	result := make([]StatusChange, len(history))
+	for i, change := range history {
+		change.At = change.At.In(zone)
+		result[i] = change
+	}
+	return result
+}
+
+func myVote(repair *Repair, userID string) int {
+	for _, vote := range repair.Votes {
+		if vote.UserID == userID {
+			return vote.Level
+		}
+	}
+	return 0
+}
+
+func buildLabor(entries []LaborEntry, zone *time.Location) []LaborEntry {
+	result := make([]LaborEntry, len(entries))
+	for i, entry := range entries {
+		entry.WorkedAt = entry.WorkedAt.In(zone)
+		entry.CreatedAt = entry.CreatedAt.In(zone)
+		result[i] = entry
+	}
+	return result
+}
+
+func buildExternalCharges(charges []ExternalCharge, zone *time.Location) []ExternalCharge {
+	result := make([]ExternalCharge, len(charges))
+	for i, charge := range charges {
+		charge.CreatedAt = charge.CreatedAt.In(zone)
+		result[i] = charge
+	}
+	return result
+}
+
+func buildMaintenance(info *MaintenanceInfo, zone *time.Location) *MaintenanceResponse {
+	if info == nil {
+		return nil
+	}
+
+	response := &MaintenanceResponse{
+		PlanID:        info.PlanID,
+		DueAt:         info.DueAt.In(zone),
+		Checklist:     make([]ChecklistItem, len(info.Checklist)),
+		ExpectedParts: info.ExpectedParts,
+	}
+	for i, item := range info.Checklist {
+		item.DoneAt = helper.InLocation(item.DoneAt, zone)
+		response.Checklist[i] = item
+		if item.Done {
+			response.ChecklistDone++
+		}
+	}
+	return response
 }
@@ -0,0 +1,93 @@
+package repair
+
+import (
+	"context"
+	"log"
+	"todo-service/helper"
+	"todo-service/internal/setting"
+)
+
+// JobNumberMigration counts what MigrateJobNumbers changed.
+type JobNumberMigration struct {
+	Organizations int
+	Renumbered    int
+	Coded         int
+}
+
+// MigrateJobNumbers fixes numbers handed out before the job counter existed. Within each
+// organization the oldest repair keeps a duplicated number and later ones get new numbers
+// from the job counter, after moving it past every number in use, so they cannot collide
+// with repairs reported while the migration runs. Repairs without a job code get one. With
+// dryRun nothing is written and the new numbers are only predicted.
+func MigrateJobNumbers(ctx context.Context, repo RepairRepository, settingService setting.SettingService, dryRun bool) (*JobNumberMigration, error) {
+
+	organizationIDs, err := repo.GetOrganizationIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &JobNumberMigration{}
+	for _, organizationID := range organizationIDs {
+		repairs, err := repo.GetRepairsForNumbering(ctx, organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		format := setting.DefaultRepairNumberFormat
+		if orgSetting, err := settingService.GetOrganizationSetting(ctx, organizationID); err == nil {
+			format = orgSetting.JobNumberFormat()
+		}
+		loc := settingService.GetOrganizationLocation(ctx, organizationID)
+
+		highest := 0
+		for _, repair := range repairs {
+			if repair.JobNumber > highest {
+				highest = repair.JobNumber
+			}
+		}
+
+		if !dryRun {
+			if err := repo.RaiseJobCounter(ctx, organizationID, highest); err != nil {
+				return nil, err
+			}
+		}
+
+		taken := make(map[int]bool, len(repairs))
+		for _, repair := range repairs {
+			number := repair.JobNumber
+			renumbered := number <= 0 || taken[number]
+			if renumbered {
+				if dryRun {
+					highest++
+					number = highest
+				} else if number, err = repo.NextJobNumber(ctx, organizationID); err != nil {
+					return nil, err
+				}
+			}
+			taken[number] = true
+
+			if !renumbered && repair.JobCode != "" {
+				continue
+			}
+
+			code := helper.FormatSequence(format, number, repair.DateReport.In(loc))
+			if renumbered {
+				log.Printf("Repair %s in %s: job number %d -> %d (%s)", repair.ID.Hex(), organizationID, repair.JobNumber, number, code)
+				result.Renumbered++
+			} else {
+				result.Coded++
+			}
+
+			if dryRun {
+				continue
+			}
+			if err := repo.SetJobNumber(ctx, repair.ID, number, code); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Organizations++
+	}
+
+	return result, nil
+}
@@ -6,13 +6,33 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Repair statuses. See repairTransitions for the moves allowed between them.
+const (
+	StatusPending    = "pending"
+	StatusAssigned   = "assigned"
+	StatusInProgress = "in_progress"
+	StatusOnHold     = "on_hold"
+	StatusCompleted  = "completed"
+	StatusVerified   = "verified"
+	StatusReopened   = "reopened"
+)
+
+// Levels a member can vote for a repair's urgency
+const (
+	MinUrgencyLevel = 1
+	MaxUrgencyLevel = 5
+)
+
 type Repair struct {
 	ID             primitive.ObjectID `json:"id" bson:"_id"`
 	OrganizationID string             `json:"organization_id" bson:"organization_id"`
 	JobNumber      int                `json:"job_number" bson:"job_number"`
+	JobCode        string             `json:"job_code" bson:"job_code"`
 	JobName        string             `json:"job_name" bson:"job_name"`
 	QRCode         string             `json:"qrcode" bson:"qrcode"`
 	Location       string             `json:"location" bson:"location"`
+	Category       string             `json:"category" bson:"category"`
+	Preventive     bool               `json:"preventive" bson:"preventive"`
 	Status         string             `json:"status" bson:"status"`
 	AssignedTo     *string            `json:"assigned_to" bson:"assigned_to"`
 	// Report by
@@ -21,13 +41,264 @@ type Repair struct {
 	UrgentVote  int       `json:"urgent_vote" bson:"urgent_vote"`
 	Comment     string    `json:"comment" bson:"comment"`
 	ImageReport []string  `json:"image_report" bson:"image_report"`
+	// Urgency votes. UrgentVote is the sum of their levels; repairs reported before
+	// voting keep their old number as a base score without voters.
+	Votes       []UrgencyVote `json:"votes" bson:"votes"`
+	EscalatedAt *time.Time    `json:"escalated_at" bson:"escalated_at"`
+	CoReporters []CoReport    `json:"co_reporters" bson:"co_reporters"`
 	// Repair by
 	DateRepair    *time.Time `json:"date_repair" bson:"date_repair"`
 	RepairBy      *string    `json:"repair_by" bson:"repair_by"`
 	CommentRepair *string    `json:"comment_repair" bson:"comment_repair"`
 	ImageRepair   []*string  `json:"image_repair" bson:"image_repair"`
 	TotalCost     *float64   `json:"total_cost" bson:"total_cost"`
+	// SLA, computed from the organization's policy when the repair is reported
+	AssignedAt         *time.Time     `json:"assigned_at" bson:"assigned_at"`
+	SLABand            string         `json:"sla_band" bson:"sla_band"`
+	AssignDueBy        *time.Time     `json:"assign_due_by" bson:"assign_due_by"`
+	CompleteDueBy      *time.Time     `json:"complete_due_by" bson:"complete_due_by"`
+	AssignBreachedAt   *time.Time     `json:"assign_breached_at" bson:"assign_breached_at"`
+	CompleteBreachedAt *time.Time     `json:"complete_breached_at" bson:"complete_breached_at"`
+	StatusHistory      []StatusChange `json:"status_history" bson:"status_history"`
+	// Costs beyond the shop parts. TotalCost adds them up with the organization's tax.
+	Labor           []LaborEntry     `json:"labor" bson:"labor"`
+	ExternalCharges []ExternalCharge `json:"external_charges" bson:"external_charges"`
+	InvoiceKey      string           `json:"invoice_key" bson:"invoice_key"`
+	InvoiceAt       *time.Time       `json:"invoice_at" bson:"invoice_at"`
+	// Set on preventive repairs, generated from a maintenance plan ahead of the due date
+	Maintenance *MaintenanceInfo `json:"maintenance" bson:"maintenance"`
+	// The asset the repair is about, when it was reported against one
+	AssetID *primitive.ObjectID `json:"asset_id" bson:"asset_id"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// UrgencyVote is one member's opinion of how urgent a repair is.
+type UrgencyVote struct {
+	UserID  string    `json:"user_id" bson:"user_id"`
+	Level   int       `json:"level" bson:"level"`
+	VotedAt time.Time `json:"voted_at" bson:"voted_at"`
+}
+
+// CoReport is a later report of the same problem, joined to this repair instead of
+// creating a duplicate.
+type CoReport struct {
+	UserID      string    `json:"user_id" bson:"user_id"`
+	Comment     string    `json:"comment" bson:"comment"`
+	ImageReport []string  `json:"image_report" bson:"image_report"`
+	ReportedAt  time.Time `json:"reported_at" bson:"reported_at"`
+}
+
+// StatusChange records one transition of a repair. Reason is set when putting on hold
+// and reopening.
+type StatusChange struct {
+	From   string    `json:"from" bson:"from"`
+	To     string    `json:"to" bson:"to"`
+	By     string    `json:"by" bson:"by"`
+	Reason string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	At     time.Time `json:"at" bson:"at"`
+}
+
+// SLAPolicy maps urgency bands to response targets for one organization.
+type SLAPolicy struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Bands          []SLABand          `json:"bands" bson:"bands"`
+	UpdatedBy      string             `json:"updated_by" bson:"updated_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// SLABand applies to repairs with at least MinUrgentVote urgency votes. The band with the
+// highest MinUrgentVote that a repair reaches wins.
+type SLABand struct {
+	Name                  string `json:"name" bson:"name"`
+	MinUrgentVote         int    `json:"min_urgent_vote" bson:"min_urgent_vote"`
+	AssignWithinMinutes   int    `json:"assign_within_minutes" bson:"assign_within_minutes"`
+	CompleteWithinMinutes int    `json:"complete_within_minutes" bson:"complete_within_minutes"`
+}
+
+// Assignment strategies of a queue
+const (
+	StrategyRoundRobin = "round_robin"
+	StrategyLeastOpen  = "least_open"
+)
+
+// AutoAssigner stands in for the person in the status history of repairs a queue assigned.
+const AutoAssigner = "queue"
+
+// AssignmentQueue hands an organization's new repairs to its maintenance staff.
+type AssignmentQueue struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Enabled        bool               `json:"enabled" bson:"enabled"`
+	Strategy       string             `json:"strategy" bson:"strategy"`
+	Members        []QueueMember      `json:"members" bson:"members"`
+	UpdatedBy      string             `json:"updated_by" bson:"updated_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
 
+// QueueMember takes repairs whose category is one of Skills and whose location is one of
+// Locations. An empty list matches every repair.
+type QueueMember struct {
+	UserID         string     `json:"user_id" bson:"user_id"`
+	Skills         []string   `json:"skills" bson:"skills"`
+	Locations      []string   `json:"locations" bson:"locations"`
+	LastAssignedAt *time.Time `json:"last_assigned_at" bson:"last_assigned_at"`
+}
+
+// LaborEntry is time a technician spent on the repair, billed at their hourly rate.
+type LaborEntry struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	TechnicianID string             `json:"technician_id" bson:"technician_id"`
+	Description  string             `json:"description" bson:"description"`
+	Hours        float64            `json:"hours" bson:"hours"`
+	Rate         float64            `json:"rate" bson:"rate"`
+	WorkedAt     time.Time          `json:"worked_at" bson:"worked_at"`
+	CreatedBy    string             `json:"created_by" bson:"created_by"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ExternalCharge is a bill from outside the organization, such as a contractor or a rental.
+type ExternalCharge struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Vendor      string             `json:"vendor" bson:"vendor"`
+	Description string             `json:"description" bson:"description"`
+	Amount      float64            `json:"amount" bson:"amount"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CostBasis is what a repair's total is priced from besides its own labor and external
+// charges: the shop parts, which live in the shop service, and the organization's tax rate.
+type CostBasis struct {
+	Parts   float64
+	TaxRate float64
+}
+
+// RepairFilter narrows repair listings. Empty fields match everything and ranges are inclusive.
+type RepairFilter struct {
+	OrganizationID string
+	Statuses       []string
+	AssignedTo     string
+	ReportBy       string
+	Location       string
+	From           *time.Time
+	To             *time.Time
+	MinCost        *float64
+	MaxCost        *float64
+	MinUrgency     *int
+	MaxUrgency     *int
+	PlanID         *primitive.ObjectID
+	AssetID        *primitive.ObjectID
+	SortBy         string
+}
+
+// SLABandStats is one band of the compliance report aggregation.
+type SLABandStats struct {
+	Band              string  `bson:"_id"`
+	Total             int     `bson:"total"`
+	Assigned          int     `bson:"assigned"`
+	Completed         int     `bson:"completed"`
+	AssignMet         int     `bson:"assign_met"`
+	AssignBreached    int     `bson:"assign_breached"`
+	CompleteMet       int     `bson:"complete_met"`
+	CompleteBreached  int     `bson:"complete_breached"`
+	AverageAssignMs   float64 `bson:"average_assign_ms"`
+	AverageCompleteMs float64 `bson:"average_complete_ms"`
+}
+
+// RepairReportKey is what the repair report aggregation groups by. Month is YYYY-MM in the
+// report's time zone.
+type RepairReportKey struct {
+	Location string `bson:"location"`
+	Month    string `bson:"month"`
+	Category string `bson:"category"`
+}
+
+// RepairReportStats is one group of the repair report aggregation. The elapsed times are
+// sums in milliseconds, so groups can be added up before averaging.
+type RepairReportStats struct {
+	Key        RepairReportKey `bson:"_id"`
+	Total      int             `bson:"total"`
+	Assigned   int             `bson:"assigned"`
+	Completed  int             `bson:"completed"`
+	AssignMs   float64         `bson:"assign_ms"`
+	CompleteMs float64         `bson:"complete_ms"`
+	TotalCost  float64         `bson:"total_cost"`
+}
+
+// Recurrence units of a maintenance plan
+const (
+	RecurrenceDay   = "day"
+	RecurrenceWeek  = "week"
+	RecurrenceMonth = "month"
+	RecurrenceYear  = "year"
+)
+
+// PreventiveSLABand is the band of preventive repairs, which are due when their plan says
+// rather than by urgency.
+const PreventiveSLABand = "preventive"
+
+// MaintenanceScheduler stands in for the person in the status history of preventive repairs.
+const MaintenanceScheduler = "scheduler"
+
+// MaintenancePlan services a location on a schedule, such as filters, fire extinguishers
+// or AC units. The scheduler generates a preventive repair LeadDays before each due date.
+type MaintenancePlan struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID  string             `json:"organization_id" bson:"organization_id"`
+	Name            string             `json:"name" bson:"name"`
+	Description     string             `json:"description" bson:"description"`
+	Location        string             `json:"location" bson:"location"`
+	Category        string             `json:"category" bson:"category"`
+	Recurrence      Recurrence         `json:"recurrence" bson:"recurrence"`
+	StartAt         time.Time          `json:"start_at" bson:"start_at"`
+	LeadDays        int                `json:"lead_days" bson:"lead_days"`
+	Checklist       []string           `json:"checklist" bson:"checklist"`
+	DefaultAssignee *string            `json:"default_assignee" bson:"default_assignee"`
+	ExpectedParts   []ExpectedPart     `json:"expected_parts" bson:"expected_parts"`
+	Active          bool               `json:"active" bson:"active"`
+	// The next service the scheduler has yet to generate. Occurrence counts from StartAt,
+	// so due dates do not drift when a month is shorter than the start day.
+	Occurrence     int       `json:"occurrence" bson:"occurrence"`
+	NextDueAt      time.Time `json:"next_due_at" bson:"next_due_at"`
+	NextGenerateAt time.Time `json:"next_generate_at" bson:"next_generate_at"`
+
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+	UpdatedBy string    `json:"updated_by" bson:"updated_by"`
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
+
+// Recurrence repeats a plan every Every units, for example every 3 months.
+type Recurrence struct {
+	Unit  string `json:"unit" bson:"unit"`
+	Every int    `json:"every" bson:"every"`
+}
+
+// ExpectedPart is a part a service usually needs, so it can be stocked beforehand.
+// ProductID optionally points to the shop product.
+type ExpectedPart struct {
+	ProductID string `json:"product_id,omitempty" bson:"product_id,omitempty"`
+	Name      string `json:"name" bson:"name"`
+	Quantity  int    `json:"quantity" bson:"quantity"`
+}
+
+// MaintenanceInfo links a preventive repair back to its plan and the service it stands for.
+type MaintenanceInfo struct {
+	PlanID        primitive.ObjectID `json:"plan_id" bson:"plan_id"`
+	DueAt         time.Time          `json:"due_at" bson:"due_at"`
+	Checklist     []ChecklistItem    `json:"checklist" bson:"checklist"`
+	ExpectedParts []ExpectedPart     `json:"expected_parts" bson:"expected_parts"`
+}
+
+// ChecklistItem is one step of a service, ticked off by the technician.
+type ChecklistItem struct {
+	Item   string     `json:"item" bson:"item"`
+	Done   bool       `json:"done" bson:"done"`
+	DoneBy *string    `json:"done_by" bson:"done_by"`
+	DoneAt *time.Time `json:"done_at" bson:"done_at"`
+}
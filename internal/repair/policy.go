@@ -3,64 +3,195 @@ package repair
 import (
 	"context"
 	"fmt"
-	"strings"
-	"todo-service/pkg/constants"
-
-	"github.com/golang-jwt/jwt/v5"
+	"todo-service/internal/authz"
 )
 
+// repairTransitions lists the statuses each status may move to. Assigning again from an
+// active status hands the repair to someone else.
+var repairTransitions = map[string][]string{
+	StatusPending:    {StatusAssigned, StatusCompleted},
+	StatusAssigned:   {StatusAssigned, StatusInProgress, StatusOnHold, StatusCompleted},
+	StatusInProgress: {StatusAssigned, StatusOnHold, StatusCompleted},
+	StatusOnHold:     {StatusAssigned, StatusInProgress},
+	StatusCompleted:  {StatusVerified, StatusReopened},
+	StatusVerified:   {StatusReopened},
+	StatusReopened:   {StatusAssigned, StatusInProgress, StatusOnHold, StatusCompleted},
+}
+
 type Policy struct{}
 
 func NewPolicy() *Policy {
 	return &Policy{}
 }
 
+// checkTransition rejects moves the workflow does not allow, whoever asks for them.
+func checkTransition(repair *Repair, to string) error {
+	for _, next := range repairTransitions[repair.Status] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("a %s repair cannot be moved to %s", repair.Status, to)
+}
+
 func (p *Policy) CanUpdateReport(ctx context.Context, repair *Repair, userID string) error {
-	if repair.ReportBy != userID {
-		return fmt.Errorf("user does not have permission to update this report")
+	return authz.Authorize(ctx, authz.RepairUpdate, userID, authz.Owner("being the reporter", repair.ReportBy))
+}
+
+func (p *Policy) CanAssignRepair(ctx context.Context, repair *Repair, userID string) error {
+	if err := checkTransition(repair, StatusAssigned); err != nil {
+		return err
 	}
-	return nil
+	return authz.Authorize(ctx, authz.RepairAssign, userID)
 }
 
-func (p *Policy) hasAdminRole(tokenString string) bool {
-	token, _, _ := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+func (p *Policy) CanDeleteRepair(ctx context.Context, repair *Repair, userID string) error {
+	return authz.Authorize(ctx, authz.RepairDelete, userID, authz.Owner("being the reporter", repair.ReportBy))
+}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if roles, ok := claims["roles"].(string); ok {
-			roleList := strings.Split(roles, ", ")
-			for _, role := range roleList {
-				if strings.TrimSpace(role) == "Admin" {
-					return true
-				}
-			}
-		}
+func (p *Policy) CanCompleteRepair(ctx context.Context, repair *Repair, userID string) error {
+	if err := checkTransition(repair, StatusCompleted); err != nil {
+		return err
+	}
+	if repair.AssignedTo == nil {
+		return authz.Authorize(ctx, authz.RepairComplete, userID, authz.When("the repair being unassigned", true))
 	}
-	return false
+	return authz.Authorize(ctx, authz.RepairComplete, userID, authz.Owner("being the assignee", *repair.AssignedTo))
 }
 
-func (p *Policy) CanAssignRepair(ctx context.Context, repair *Repair, userID string) error {
-	token, ok := ctx.Value(constants.TokenKey).(string)
-	if !ok {
-		return fmt.Errorf("token not found in context")
+func (p *Policy) CanStartRepair(ctx context.Context, repair *Repair, userID string) error {
+	if repair.Status == StatusOnHold {
+		return fmt.Errorf("the repair is on hold, resume it instead")
+	}
+	if err := checkTransition(repair, StatusInProgress); err != nil {
+		return err
 	}
+	return p.canWork(ctx, repair, userID)
+}
 
-	if !p.hasAdminRole(token) {
-		return fmt.Errorf("user does not have admin permission to assign repairs")
+func (p *Policy) CanHoldRepair(ctx context.Context, repair *Repair, userID string) error {
+	if err := checkTransition(repair, StatusOnHold); err != nil {
+		return err
 	}
+	return p.canWork(ctx, repair, userID)
+}
 
-	return nil
+func (p *Policy) CanResumeRepair(ctx context.Context, repair *Repair, userID string) error {
+	if repair.Status != StatusOnHold {
+		return fmt.Errorf("only a repair on hold can be resumed")
+	}
+	return p.canWork(ctx, repair, userID)
 }
 
-func (p *Policy) CanDeleteRepair(ctx context.Context, repair *Repair, userID string) error {
-	if repair.ReportBy != userID {
-		return fmt.Errorf("user does not have permission to delete this repair")
+// canWork allows the assignee to drive the repair while it is being worked on.
+func (p *Policy) canWork(ctx context.Context, repair *Repair, userID string) error {
+	if repair.AssignedTo == nil {
+		return fmt.Errorf("the repair must be assigned first")
 	}
-	return nil
+	return authz.Authorize(ctx, authz.RepairWork, userID, authz.Owner("being the assignee", *repair.AssignedTo))
 }
 
-func (p *Policy) CanCompleteRepair(ctx context.Context, repair *Repair, userID string) error {
-	if repair.AssignedTo != nil && *repair.AssignedTo != userID {
-		return fmt.Errorf("user does not have permission to complete this repair")
+func (p *Policy) CanVerifyRepair(ctx context.Context, repair *Repair, userID string) error {
+	if err := checkTransition(repair, StatusVerified); err != nil {
+		return err
+	}
+	return authz.Authorize(ctx, authz.RepairVerify, userID, authz.Owner("being the reporter", repair.ReportBy))
+}
+
+func (p *Policy) CanReopenRepair(ctx context.Context, repair *Repair, userID string) error {
+	if err := checkTransition(repair, StatusReopened); err != nil {
+		return err
+	}
+	return authz.Authorize(ctx, authz.RepairReopen, userID, authz.Owner("being the reporter", repair.ReportBy))
+}
+
+// CanVoteUrgency lets the organization's members, and the reporter, weigh in while the
+// repair is still open.
+func (p *Policy) CanVoteUrgency(ctx context.Context, repair *Repair, userID string, isMember bool) error {
+	if repair.Status == StatusCompleted || repair.Status == StatusVerified {
+		return fmt.Errorf("votes are closed once a repair is completed")
+	}
+	return authz.Authorize(ctx, authz.RepairVote, userID,
+		authz.Owner("being the reporter", repair.ReportBy),
+		authz.When("being a member of the organization", isMember))
+}
+
+func (p *Policy) CanManageSLA(ctx context.Context, userID string) error {
+	return authz.Authorize(ctx, authz.RepairManageSLA, userID)
+}
+
+// CanViewSLA allows the organization's staff to read its SLA policy and compliance report.
+func (p *Policy) CanViewSLA(ctx context.Context, userID string, isStaff bool) error {
+	return authz.Authorize(ctx, authz.RepairViewSLA, userID, authz.When("being staff of the organization", isStaff))
+}
+
+// CanViewReport allows the organization's staff to read the repair report per location and month.
+func (p *Policy) CanViewReport(ctx context.Context, userID string, isStaff bool) error {
+	return authz.Authorize(ctx, authz.RepairReport, userID, authz.When("being staff of the organization", isStaff))
+}
+
+// CanManageCosts lets the assignee record labor and external charges until the repair is
+// verified, after which its costs are final.
+func (p *Policy) CanManageCosts(ctx context.Context, repair *Repair, userID string) error {
+	if repair.Status == StatusVerified {
+		return fmt.Errorf("costs are locked once a repair is verified")
+	}
+	return authz.Authorize(ctx, authz.RepairCosts, userID, costOwners(repair)...)
+}
+
+// CanViewCosts lets the reporter, whoever may manage the costs and the organization's staff
+// read the cost breakdown and the invoice.
+func (p *Policy) CanViewCosts(ctx context.Context, repair *Repair, userID string, isStaff bool) error {
+	rules := append(costOwners(repair),
+		authz.Owner("being the reporter", repair.ReportBy),
+		authz.When("being staff of the organization", isStaff))
+	return authz.Authorize(ctx, authz.RepairCosts, userID, rules...)
+}
+
+// CanIssueInvoice allows an invoice once the work is done, for whoever may manage the costs.
+func (p *Policy) CanIssueInvoice(ctx context.Context, repair *Repair, userID string) error {
+	if repair.Status != StatusCompleted && repair.Status != StatusVerified {
+		return fmt.Errorf("an invoice can only be issued for a completed repair")
+	}
+	return authz.Authorize(ctx, authz.RepairCosts, userID, costOwners(repair)...)
+}
+
+func costOwners(repair *Repair) []authz.Rule {
+	var rules []authz.Rule
+	if repair.AssignedTo != nil {
+		rules = append(rules, authz.Owner("being the assignee", *repair.AssignedTo))
+	}
+	if repair.RepairBy != nil {
+		rules = append(rules, authz.Owner("having completed the repair", *repair.RepairBy))
+	}
+	return rules
+}
+
+func (p *Policy) CanManageQueue(ctx context.Context, userID string) error {
+	return authz.Authorize(ctx, authz.RepairAssign, userID)
+}
+
+// CanViewQueue lets the organization's staff see who takes new repairs and how loaded they are.
+func (p *Policy) CanViewQueue(ctx context.Context, userID string, isStaff bool) error {
+	return authz.Authorize(ctx, authz.RepairAssign, userID, authz.When("being staff of the organization", isStaff))
+}
+
+func (p *Policy) CanManagePlans(ctx context.Context, userID string) error {
+	return authz.Authorize(ctx, authz.RepairPlans, userID)
+}
+
+// CanViewPlans lets the organization's staff see the maintenance plans and how well they are kept.
+func (p *Policy) CanViewPlans(ctx context.Context, userID string, isStaff bool) error {
+	return authz.Authorize(ctx, authz.RepairPlans, userID, authz.When("being staff of the organization", isStaff))
+}
+
+// CanTickChecklist lets whoever works on a preventive repair tick off its checklist until it is done.
+func (p *Policy) CanTickChecklist(ctx context.Context, repair *Repair, userID string) error {
+	if repair.Maintenance == nil {
+		return fmt.Errorf("only preventive repairs have a checklist")
+	}
+	if repair.Status == StatusCompleted || repair.Status == StatusVerified {
+		return fmt.Errorf("the checklist is closed once a repair is completed")
 	}
-	return nil
+	return p.canWork(ctx, repair, userID)
 }
@@ -0,0 +1,283 @@
+package repair
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/location"
+)
+
+const (
+	defaultRepairReportMonths = 12
+	maxRepairReportRangeDays  = 731
+	topReportCategories       = 3
+)
+
+// GetRepairReport totals the repairs reported in the range per location and per month, with
+// the categories that came up most. Months are cut in the caller's time zone. Without a
+// range it covers the last twelve months, this one included.
+func (s *repairService) GetRepairReport(ctx context.Context, organizationID, from, to string, userID string) (*RepairReportResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewReport(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+
+	toTime := time.Now().UTC()
+	if to != "" {
+		parsed, err := helper.ParseDateTime(to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		toTime = parsed
+	}
+
+	localTo := toTime.In(loc)
+	fromTime := time.Date(localTo.Year(), localTo.Month()-defaultRepairReportMonths+1, 1, 0, 0, 0, 0, loc).UTC()
+	if from != "" {
+		parsed, err := helper.ParseDateTime(from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		fromTime = parsed
+	}
+
+	if !fromTime.Before(toTime) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	if toTime.Sub(fromTime) > maxRepairReportRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("the range cannot be longer than %d days", maxRepairReportRangeDays)
+	}
+
+	stats, err := s.RepairRepo.GetReportStats(ctx, organizationID, fromTime, toTime, loc.String())
+	if err != nil {
+		return nil, err
+	}
+
+	overall := newReportTotals()
+	months := map[string]*reportTotals{}
+	locations := map[string]*reportTotals{}
+	locationMonths := map[string]map[string]*reportTotals{}
+
+	for _, stat := range stats {
+		overall.add(stat)
+		totalsFor(months, stat.Key.Month).add(stat)
+		totalsFor(locations, stat.Key.Location).add(stat)
+
+		if locationMonths[stat.Key.Location] == nil {
+			locationMonths[stat.Key.Location] = map[string]*reportTotals{}
+		}
+		totalsFor(locationMonths[stat.Key.Location], stat.Key.Month).add(stat)
+	}
+
+	result := &RepairReportResponse{
+		OrganizationID: organizationID,
+		From:           fromTime.In(loc),
+		To:             toTime.In(loc),
+		Overall:        overall.row(),
+		Months:         monthReports(months),
+		Locations:      make([]LocationReport, 0, len(locations)),
+	}
+
+	for id, totals := range locations {
+		result.Locations = append(result.Locations, LocationReport{
+			Location:        s.reportLocation(ctx, id),
+			RepairReportRow: totals.row(),
+			Months:          monthReports(locationMonths[id]),
+		})
+	}
+
+	// The locations that cost the most come first
+	sort.Slice(result.Locations, func(i, j int) bool {
+		a, b := result.Locations[i], result.Locations[j]
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return strings.ToLower(a.Location.Name) < strings.ToLower(b.Location.Name)
+	})
+
+	return result, nil
+}
+
+// ExportRepairReport renders GetRepairReport as CSV, one row per location and month. Each
+// location closes with its total over the range, and the file with the organization's.
+func (s *repairService) ExportRepairReport(ctx context.Context, organizationID, from, to string, userID string) ([]byte, error) {
+
+	report, err := s.GetRepairReport(ctx, organizationID, from, to, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{
+		"location_id", "location", "month", "repairs", "assigned", "completed",
+		"mean_assign_minutes", "mean_complete_minutes", "total_cost", "top_categories",
+	}}
+	for _, entry := range report.Locations {
+		for _, month := range entry.Months {
+			records = append(records, reportRecord(entry.Location.ID, entry.Location.Name, month.Month, month.RepairReportRow))
+		}
+		records = append(records, reportRecord(entry.Location.ID, entry.Location.Name, "total", entry.RepairReportRow))
+	}
+	records = append(records, reportRecord(
+		"total",
+		fmt.Sprintf("%s to %s", report.From.Format("2006-01-02"), report.To.Format("2006-01-02")),
+		"total",
+		report.Overall,
+	))
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// reportLocation resolves a location's name, falling back to its bare ID when the location
+// service cannot.
+func (s *repairService) reportLocation(ctx context.Context, id string) location.LocationInfor {
+	info, err := s.LocationService.GetLocationByID(ctx, id)
+	if err != nil || info == nil {
+		log.Printf("[WARN] failed to get location %s for the repair report: %v", id, err)
+		return location.LocationInfor{ID: id}
+	}
+	return *info
+}
+
+func reportRecord(id, name, month string, row RepairReportRow) []string {
+	categories := make([]string, 0, len(row.TopCategories))
+	for _, category := range row.TopCategories {
+		categories = append(categories, fmt.Sprintf("%s (%d)", category.Category, category.Total))
+	}
+
+	// Names come from users, so keep spreadsheets from running them as formulas
+	return []string{
+		helper.CSVText(id),
+		helper.CSVText(name),
+		month,
+		strconv.Itoa(row.Total),
+		strconv.Itoa(row.Assigned),
+		strconv.Itoa(row.Completed),
+		strconv.FormatFloat(row.MeanAssignMinutes, 'f', 1, 64),
+		strconv.FormatFloat(row.MeanCompleteMinutes, 'f', 1, 64),
+		strconv.FormatFloat(row.TotalCost, 'f', 2, 64),
+		helper.CSVText(strings.Join(categories, "; ")),
+	}
+}
+
+// reportTotals adds up aggregation groups before the means are taken.
+type reportTotals struct {
+	total      int
+	assigned   int
+	completed  int
+	assignMs   float64
+	completeMs float64
+	cost       float64
+	categories map[string]*CategoryReport
+}
+
+func newReportTotals() *reportTotals {
+	return &reportTotals{categories: map[string]*CategoryReport{}}
+}
+
+func totalsFor(totals map[string]*reportTotals, key string) *reportTotals {
+	if totals[key] == nil {
+		totals[key] = newReportTotals()
+	}
+	return totals[key]
+}
+
+func (t *reportTotals) add(stat RepairReportStats) {
+	t.total += stat.Total
+	t.assigned += stat.Assigned
+	t.completed += stat.Completed
+	t.assignMs += stat.AssignMs
+	t.completeMs += stat.CompleteMs
+	t.cost += stat.TotalCost
+
+	name := stat.Key.Category
+	if name == "" {
+		name = "uncategorized"
+	}
+	category, ok := t.categories[name]
+	if !ok {
+		category = &CategoryReport{Category: name}
+		t.categories[name] = category
+	}
+	category.Total += stat.Total
+	category.TotalCost += stat.TotalCost
+}
+
+func (t *reportTotals) row() RepairReportRow {
+	row := RepairReportRow{
+		Total:               t.total,
+		Assigned:            t.assigned,
+		Completed:           t.completed,
+		MeanAssignMinutes:   meanMinutes(t.assignMs, t.assigned),
+		MeanCompleteMinutes: meanMinutes(t.completeMs, t.completed),
+		TotalCost:           roundMoney(t.cost),
+		TopCategories:       make([]CategoryReport, 0, len(t.categories)),
+	}
+
+	for _, category := range t.categories {
+		row.TopCategories = append(row.TopCategories, CategoryReport{
+			Category:  category.Category,
+			Total:     category.Total,
+			TotalCost: roundMoney(category.TotalCost),
+		})
+	}
+
+	// Most repairs first, then the costliest
+	sort.Slice(row.TopCategories, func(i, j int) bool {
+		a, b := row.TopCategories[i], row.TopCategories[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		return a.Category < b.Category
+	})
+	if len(row.TopCategories) > topReportCategories {
+		row.TopCategories = row.TopCategories[:topReportCategories]
+	}
+
+	return row
+}
+
+func monthReports(months map[string]*reportTotals) []MonthReport {
+	reports := make([]MonthReport, 0, len(months))
+	for month, totals := range months {
+		reports = append(reports, MonthReport{Month: month, RepairReportRow: totals.row()})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Month < reports[j].Month
+	})
+	return reports
+}
+
+func meanMinutes(totalMs float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(totalMs/float64(count)/float64(time.Minute/time.Millisecond)*10) / 10
+}
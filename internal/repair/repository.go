@@ -2,31 +2,131 @@ package repair
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type RepairRepository interface {
 	CreateRepair(ctx context.Context, repair *Repair) error
-	GetRepairs(ctx context.Context) ([]*Repair, error)
-	GetJobCount(ctx context.Context, organizationID string) (int, error)
+	GetRepairs(ctx context.Context, filter RepairFilter) ([]*Repair, error)
+	NextJobNumber(ctx context.Context, organizationID string) (int, error)
 	GetRepairByID(ctx context.Context, id primitive.ObjectID) (*Repair, error)
 	UpdateRepair(ctx context.Context, id primitive.ObjectID, repair *Repair) error
+	UpdateRepairFromStatus(ctx context.Context, id primitive.ObjectID, status string, repair *Repair) (bool, error)
 	DeleteRepair(ctx context.Context, id primitive.ObjectID) error
+
+	SetUrgencyVote(ctx context.Context, id primitive.ObjectID, vote UrgencyVote) (*Repair, error)
+	RemoveUrgencyVote(ctx context.Context, id primitive.ObjectID, userID string) (*Repair, error)
+	UpdateRepairSLA(ctx context.Context, id primitive.ObjectID, score int, repair *Repair) error
+	MarkEscalated(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error)
+
+	GetOpenRepairsAtLocation(ctx context.Context, organizationID, location string, since time.Time) ([]*Repair, error)
+	AddCoReporter(ctx context.Context, id primitive.ObjectID, coReport CoReport) (bool, error)
+
+	AddLabor(ctx context.Context, id primitive.ObjectID, entry LaborEntry, basis CostBasis) (*Repair, error)
+	RemoveLabor(ctx context.Context, id, entryID primitive.ObjectID, basis CostBasis) (*Repair, error)
+	AddExternalCharge(ctx context.Context, id primitive.ObjectID, charge ExternalCharge, basis CostBasis) (*Repair, error)
+	RemoveExternalCharge(ctx context.Context, id, chargeID primitive.ObjectID, basis CostBasis) (*Repair, error)
+	RefreshTotalCost(ctx context.Context, id primitive.ObjectID, basis CostBasis) error
+	SetInvoice(ctx context.Context, id primitive.ObjectID, key string, at time.Time) error
+
+	GetSLAPolicy(ctx context.Context, organizationID string) (*SLAPolicy, error)
+	UpsertSLAPolicy(ctx context.Context, policy *SLAPolicy) error
+	MarkSLABreaches(ctx context.Context, now time.Time) (int64, error)
+	GetSLAStats(ctx context.Context, organizationID string, from, to time.Time) ([]SLABandStats, error)
+	GetReportStats(ctx context.Context, organizationID string, from, to time.Time, timezone string) ([]RepairReportStats, error)
+
+	EnsureIndexes(ctx context.Context) error
+	GetOrganizationIDs(ctx context.Context) ([]string, error)
+	GetRepairsForNumbering(ctx context.Context, organizationID string) ([]*Repair, error)
+	SetJobNumber(ctx context.Context, id primitive.ObjectID, jobNumber int, jobCode string) error
+	RaiseJobCounter(ctx context.Context, organizationID string, jobNumber int) error
+
+	GetAssignmentQueue(ctx context.Context, organizationID string) (*AssignmentQueue, error)
+	UpsertAssignmentQueue(ctx context.Context, queue *AssignmentQueue) error
+	MarkQueueMemberAssigned(ctx context.Context, queueID primitive.ObjectID, userID string, previous *time.Time, at time.Time) (bool, error)
+	CountOpenByAssignee(ctx context.Context, organizationID string, userIDs []string) (map[string]int, error)
+
+	CreateMaintenancePlan(ctx context.Context, plan *MaintenancePlan) error
+	GetMaintenancePlans(ctx context.Context, organizationID string) ([]*MaintenancePlan, error)
+	GetMaintenancePlanByID(ctx context.Context, id primitive.ObjectID) (*MaintenancePlan, error)
+	UpdateMaintenancePlan(ctx context.Context, plan *MaintenancePlan) error
+	DeleteMaintenancePlan(ctx context.Context, id primitive.ObjectID) error
+	GetDueMaintenancePlans(ctx context.Context, now time.Time) ([]*MaintenancePlan, error)
+	AdvanceMaintenancePlan(ctx context.Context, plan *MaintenancePlan, previous int) (bool, error)
+	HasPreventiveRepair(ctx context.Context, planID primitive.ObjectID, dueAt time.Time) (bool, error)
+	GetPreventiveRepairs(ctx context.Context, organizationID string, from, to time.Time) ([]*Repair, error)
+	SetChecklistItem(ctx context.Context, id primitive.ObjectID, index int, item ChecklistItem) (*Repair, error)
 }
 
 type repairRepository struct {
-	repairCollection *mongo.Collection
+	repairCollection    *mongo.Collection
+	slaPolicyCollection *mongo.Collection
+	counterCollection   *mongo.Collection
+	queueCollection     *mongo.Collection
+	planCollection      *mongo.Collection
 }
 
-func NewRepairRepository(repairCollection *mongo.Collection) RepairRepository {
+func NewRepairRepository(repairCollection, slaPolicyCollection, counterCollection, queueCollection, planCollection *mongo.Collection) RepairRepository {
 	return &repairRepository{
-		repairCollection: repairCollection,
+		repairCollection:    repairCollection,
+		slaPolicyCollection: slaPolicyCollection,
+		counterCollection:   counterCollection,
+		queueCollection:     queueCollection,
+		planCollection:      planCollection,
 	}
 }
 
+// EnsureIndexes makes job numbers unique per organization and each planned service generate
+// one repair, plus the indexes listings filter through. The job number index fails while
+// duplicates from before the job counter exist; run cmd/migrate to renumber them. Each index
+// is created on its own so that one failing does not hold back the others.
+func (r *repairRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "job_number", Value: 1}},
+			Options: options.Index().SetName("organization_job_number").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "status", Value: 1}, {Key: "date_report", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "location", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "asset_id", Value: 1}},
+			Options: options.Index().SetName("asset_id").
+				SetPartialFilterExpression(bson.M{"asset_id": bson.M{"$type": "objectId"}}),
+		},
+		{
+			Keys: bson.D{{Key: "maintenance.plan_id", Value: 1}, {Key: "maintenance.due_at", Value: 1}},
+			Options: options.Index().SetName("maintenance_plan_due_at").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"preventive": true}),
+		},
+	}
+
+	var errs []error
+	for _, index := range indexes {
+		if _, err := r.repairCollection.Indexes().CreateOne(ctx, index); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	_, err := r.planCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "active", Value: 1}, {Key: "next_generate_at", Value: 1}},
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
 func (r *repairRepository) CreateRepair(ctx context.Context, repair *Repair) error {
 	_, err := r.repairCollection.InsertOne(ctx, repair)
 	if err != nil {
@@ -35,9 +135,82 @@ func (r *repairRepository) CreateRepair(ctx context.Context, repair *Repair) err
 	return nil
 }
 
-func (r *repairRepository) GetRepairs(ctx context.Context) ([]*Repair, error) {
+// Sort keys accepted by GetRepairs. Without one, repairs come back in storage order.
+const (
+	SortByScore  = "score"
+	SortByNewest = "newest"
+	SortByOldest = "oldest"
+	SortByCost   = "cost"
+)
+
+var repairSorts = map[string]bson.D{
+	SortByScore:  {{Key: "urgent_vote", Value: -1}, {Key: "date_report", Value: 1}},
+	SortByNewest: {{Key: "date_report", Value: -1}, {Key: "_id", Value: -1}},
+	SortByOldest: {{Key: "date_report", Value: 1}, {Key: "_id", Value: 1}},
+	SortByCost:   {{Key: "total_cost", Value: -1}, {Key: "date_report", Value: 1}},
+}
+
+func (r *repairRepository) GetRepairs(ctx context.Context, filter RepairFilter) ([]*Repair, error) {
+
+	query := bson.M{}
+	if filter.OrganizationID != "" {
+		query["organization_id"] = filter.OrganizationID
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.AssignedTo != "" {
+		query["assigned_to"] = filter.AssignedTo
+	}
+	if filter.ReportBy != "" {
+		query["report_by"] = filter.ReportBy
+	}
+	if filter.Location != "" {
+		query["location"] = filter.Location
+	}
+	if filter.PlanID != nil {
+		query["maintenance.plan_id"] = *filter.PlanID
+	}
+	if filter.AssetID != nil {
+		query["asset_id"] = *filter.AssetID
+	}
+	if filter.From != nil || filter.To != nil {
+		dateReport := bson.M{}
+		if filter.From != nil {
+			dateReport["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			dateReport["$lte"] = *filter.To
+		}
+		query["date_report"] = dateReport
+	}
+	if filter.MinCost != nil || filter.MaxCost != nil {
+		totalCost := bson.M{}
+		if filter.MinCost != nil {
+			totalCost["$gte"] = *filter.MinCost
+		}
+		if filter.MaxCost != nil {
+			totalCost["$lte"] = *filter.MaxCost
+		}
+		query["total_cost"] = totalCost
+	}
+	if filter.MinUrgency != nil || filter.MaxUrgency != nil {
+		urgentVote := bson.M{}
+		if filter.MinUrgency != nil {
+			urgentVote["$gte"] = *filter.MinUrgency
+		}
+		if filter.MaxUrgency != nil {
+			urgentVote["$lte"] = *filter.MaxUrgency
+		}
+		query["urgent_vote"] = urgentVote
+	}
+
+	opts := options.Find()
+	if sort, ok := repairSorts[filter.SortBy]; ok {
+		opts.SetSort(sort)
+	}
 
-	cursor, err := r.repairCollection.Find(ctx, bson.M{})
+	cursor, err := r.repairCollection.Find(ctx, query, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +233,109 @@ func (r *repairRepository) GetRepairs(ctx context.Context) ([]*Repair, error) {
 	return repairs, nil
 }
 
-func (r *repairRepository) GetJobCount(ctx context.Context, organizationID string) (int, error) {
-	count, err := r.repairCollection.CountDocuments(ctx, bson.M{"organization_id": organizationID})
+type jobCounter struct {
+	OrganizationID string `bson:"_id"`
+	Seq            int    `bson:"seq"`
+}
+
+// NextJobNumber hands out the organization's next job number. Numbers are never reused,
+// even after a repair is deleted.
+func (r *repairRepository) NextJobNumber(ctx context.Context, organizationID string) (int, error) {
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var counter jobCounter
+		err := r.counterCollection.FindOneAndUpdate(ctx,
+			bson.M{"_id": organizationID},
+			bson.M{"$inc": bson.M{"seq": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&counter)
+		if err == nil {
+			return counter.Seq, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, err
+		}
+
+		// First repair since the counter was introduced: continue after the highest number in use
+		highest, err := r.highestJobNumber(ctx, organizationID)
+		if err != nil {
+			return 0, err
+		}
+		_, err = r.counterCollection.InsertOne(ctx, jobCounter{OrganizationID: organizationID, Seq: highest})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return 0, err
+		}
+	}
+
+	return 0, fmt.Errorf("failed to allocate a job number for organization %s", organizationID)
+}
+
+func (r *repairRepository) highestJobNumber(ctx context.Context, organizationID string) (int, error) {
+	var repair Repair
+	err := r.repairCollection.FindOne(ctx,
+		bson.M{"organization_id": organizationID},
+		options.FindOne().SetSort(bson.M{"job_number": -1}).SetProjection(bson.M{"job_number": 1}),
+	).Decode(&repair)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
 		return 0, err
 	}
-	return int(count), nil
+	return repair.JobNumber, nil
+}
+
+// RaiseJobCounter moves the counter up to jobNumber; it never moves it down.
+func (r *repairRepository) RaiseJobCounter(ctx context.Context, organizationID string, jobNumber int) error {
+	_, err := r.counterCollection.UpdateOne(ctx,
+		bson.M{"_id": organizationID},
+		bson.M{"$max": bson.M{"seq": jobNumber}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *repairRepository) GetOrganizationIDs(ctx context.Context) ([]string, error) {
+	values, err := r.repairCollection.Distinct(ctx, "organization_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetRepairsForNumbering lists the organization's repairs oldest first, so that the first
+// holder of a duplicated number keeps it.
+func (r *repairRepository) GetRepairsForNumbering(ctx context.Context, organizationID string) ([]*Repair, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "date_report", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"job_number": 1, "job_code": 1, "date_report": 1, "organization_id": 1})
+
+	cursor, err := r.repairCollection.Find(ctx, bson.M{"organization_id": organizationID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var repairs []*Repair
+	if err := cursor.All(ctx, &repairs); err != nil {
+		return nil, err
+	}
+	return repairs, nil
+}
+
+func (r *repairRepository) SetJobNumber(ctx context.Context, id primitive.ObjectID, jobNumber int, jobCode string) error {
+	_, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"job_number": jobNumber, "job_code": jobCode}},
+	)
+	return err
 }
 
 func (r *repairRepository) GetRepairByID(ctx context.Context, id primitive.ObjectID) (*Repair, error) {
@@ -77,12 +347,40 @@ func (r *repairRepository) GetRepairByID(ctx context.Context, id primitive.Objec
 	return &repair, nil
 }
 
+// atomicFields only change through their own targeted updates (votes, co-reports, costs,
+// checklist), so saving a repair that was read before one of those does not drop it.
+var atomicFields = []string{"urgent_vote", "votes", "escalated_at", "co_reporters", "labor", "external_charges", "total_cost", "invoice_key", "invoice_at", "maintenance"}
+
 func (r *repairRepository) UpdateRepair(ctx context.Context, id primitive.ObjectID, repair *Repair) error {
-	_, err := r.repairCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": repair})
+	_, err := r.saveRepair(ctx, bson.M{"_id": id}, repair)
+	return err
+}
+
+// UpdateRepairFromStatus saves the repair only while it is still in status, so that of two
+// concurrent transitions only the first lands. It reports false when the status moved on.
+func (r *repairRepository) UpdateRepairFromStatus(ctx context.Context, id primitive.ObjectID, status string, repair *Repair) (bool, error) {
+	return r.saveRepair(ctx, bson.M{"_id": id, "status": status}, repair)
+}
+
+func (r *repairRepository) saveRepair(ctx context.Context, filter bson.M, repair *Repair) (bool, error) {
+	data, err := bson.Marshal(repair)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+
+	var set bson.M
+	if err := bson.Unmarshal(data, &set); err != nil {
+		return false, err
+	}
+	for _, field := range atomicFields {
+		delete(set, field)
+	}
+
+	result, err := r.repairCollection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
 }
 
 func (r *repairRepository) DeleteRepair(ctx context.Context, id primitive.ObjectID) error {
@@ -91,4 +389,614 @@ func (r *repairRepository) DeleteRepair(ctx context.Context, id primitive.Object
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+func (r *repairRepository) GetSLAPolicy(ctx context.Context, organizationID string) (*SLAPolicy, error) {
+	var policy SLAPolicy
+	err := r.slaPolicyCollection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&policy)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *repairRepository) UpsertSLAPolicy(ctx context.Context, policy *SLAPolicy) error {
+	filter := bson.M{"organization_id": policy.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"bands":      policy.Bands,
+			"updated_by": policy.UpdatedBy,
+			"updated_at": policy.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        policy.ID,
+			"created_at": policy.CreatedAt,
+		},
+	}
+	_, err := r.slaPolicyCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// MarkSLABreaches stamps the targets that passed without being met. The stamp is the due-by
+// time itself, so running late or twice gives the same result.
+func (r *repairRepository) MarkSLABreaches(ctx context.Context, now time.Time) (int64, error) {
+
+	assign, err := r.repairCollection.UpdateMany(ctx,
+		bson.M{
+			"assign_due_by":      bson.M{"$lt": now},
+			"assign_breached_at": nil,
+			"assigned_at":        nil,
+			"status":             StatusPending,
+		},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"assign_breached_at": "$assign_due_by"}}},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	complete, err := r.repairCollection.UpdateMany(ctx,
+		bson.M{
+			"complete_due_by":      bson.M{"$lt": now},
+			"complete_breached_at": nil,
+			"status":               bson.M{"$nin": bson.A{StatusCompleted, StatusVerified}},
+		},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"complete_breached_at": "$complete_due_by"}}},
+		},
+	)
+	if err != nil {
+		return assign.ModifiedCount, err
+	}
+
+	return assign.ModifiedCount + complete.ModifiedCount, nil
+}
+
+func (r *repairRepository) GetSLAStats(ctx context.Context, organizationID string, from, to time.Time) ([]SLABandStats, error) {
+
+	countWhen := func(cond bson.M) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+	isSet := func(field string) bson.M {
+		return bson.M{"$gt": bson.A{field, nil}}
+	}
+	notSet := func(field string) bson.M {
+		return bson.M{"$lte": bson.A{field, nil}}
+	}
+	// $avg skips nulls, so unmet targets do not pull the averages down
+	elapsed := func(field string) bson.M {
+		return bson.M{"$avg": bson.M{"$cond": bson.A{
+			isSet(field),
+			bson.M{"$subtract": bson.A{field, "$date_report"}},
+			nil,
+		}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"organization_id": organizationID,
+			"date_report":     bson.M{"$gte": from, "$lt": to},
+			"sla_band":        bson.M{"$nin": bson.A{nil, ""}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$sla_band",
+			"total":     bson.M{"$sum": 1},
+			"assigned":  countWhen(isSet("$assigned_at")),
+			"completed": countWhen(isSet("$date_repair")),
+			"assign_met": countWhen(bson.M{"$and": bson.A{
+				isSet("$assigned_at"), notSet("$assign_breached_at"),
+			}}),
+			"assign_breached": countWhen(isSet("$assign_breached_at")),
+			"complete_met": countWhen(bson.M{"$and": bson.A{
+				isSet("$date_repair"), notSet("$complete_breached_at"),
+			}}),
+			"complete_breached":   countWhen(isSet("$complete_breached_at")),
+			"average_assign_ms":   elapsed("$assigned_at"),
+			"average_complete_ms": elapsed("$date_repair"),
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := r.repairCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []SLABandStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetReportStats totals the repairs reported in the range per location, month and category.
+func (r *repairRepository) GetReportStats(ctx context.Context, organizationID string, from, to time.Time, timezone string) ([]RepairReportStats, error) {
+
+	isSet := func(field string) bson.M {
+		return bson.M{"$gt": bson.A{field, nil}}
+	}
+	countWhen := func(cond bson.M) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+	elapsed := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{
+			isSet(field),
+			bson.M{"$subtract": bson.A{field, "$date_report"}},
+			0,
+		}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"organization_id": organizationID,
+			"date_report":     bson.M{"$gte": from, "$lt": to},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"location": "$location",
+				"month": bson.M{"$dateToString": bson.M{
+					"format":   "%Y-%m",
+					"date":     "$date_report",
+					"timezone": timezone,
+				}},
+				"category": "$category",
+			},
+			"total":       bson.M{"$sum": 1},
+			"assigned":    countWhen(isSet("$assigned_at")),
+			"completed":   countWhen(isSet("$date_repair")),
+			"assign_ms":   elapsed("$assigned_at"),
+			"complete_ms": elapsed("$date_repair"),
+			"total_cost":  bson.M{"$sum": "$total_cost"},
+		}}},
+	}
+
+	cursor, err := r.repairCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []RepairReportStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// SetUrgencyVote adds or replaces the user's vote and moves the score by the difference, in
+// one update so that concurrent votes cannot be lost or counted twice.
+func (r *repairRepository) SetUrgencyVote(ctx context.Context, id primitive.ObjectID, vote UrgencyVote) (*Repair, error) {
+	return r.updateVotes(ctx, id, vote.UserID, bson.A{bson.M{"$literal": vote}}, vote.Level)
+}
+
+func (r *repairRepository) RemoveUrgencyVote(ctx context.Context, id primitive.ObjectID, userID string) (*Repair, error) {
+	return r.updateVotes(ctx, id, userID, bson.A{}, 0)
+}
+
+func (r *repairRepository) updateVotes(ctx context.Context, id primitive.ObjectID, userID string, add bson.A, level int) (*Repair, error) {
+
+	votes := bson.M{"$ifNull": bson.A{"$votes", bson.A{}}}
+	voter := bson.M{"$literal": userID}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"_previous_level": bson.M{"$sum": bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{
+					"input": votes,
+					"cond":  bson.M{"$eq": bson.A{"$$this.user_id", voter}},
+				}},
+				"in": "$$this.level",
+			}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"votes": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": votes,
+					"cond":  bson.M{"$ne": bson.A{"$$this.user_id", voter}},
+				}},
+				add,
+			}},
+			"urgent_vote": bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{
+				bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$urgent_vote", 0}}, "$_previous_level"}},
+				level,
+			}}}},
+		}}},
+		{{Key: "$unset", Value: "_previous_level"}},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var repair Repair
+	err := r.repairCollection.FindOneAndUpdate(ctx, bson.M{"_id": id}, pipeline, opts).Decode(&repair)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &repair, nil
+}
+
+// UpdateRepairSLA saves the SLA fields worked out for the given score. It does nothing when
+// another vote has changed the score since, as that vote saves its own.
+func (r *repairRepository) UpdateRepairSLA(ctx context.Context, id primitive.ObjectID, score int, repair *Repair) error {
+	_, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "urgent_vote": score},
+		bson.M{"$set": bson.M{
+			"sla_band":             repair.SLABand,
+			"assign_due_by":        repair.AssignDueBy,
+			"complete_due_by":      repair.CompleteDueBy,
+			"assign_breached_at":   repair.AssignBreachedAt,
+			"complete_breached_at": repair.CompleteBreachedAt,
+		}},
+	)
+	return err
+}
+
+// MarkEscalated records the escalation once; it reports false when it was already recorded.
+func (r *repairRepository) MarkEscalated(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error) {
+	result, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "escalated_at": nil},
+		bson.M{"$set": bson.M{"escalated_at": at}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *repairRepository) GetOpenRepairsAtLocation(ctx context.Context, organizationID, location string, since time.Time) ([]*Repair, error) {
+
+	filter := bson.M{
+		"organization_id": organizationID,
+		"location":        location,
+		"status":          bson.M{"$nin": bson.A{StatusCompleted, StatusVerified}},
+		"date_report":     bson.M{"$gte": since},
+	}
+
+	cursor, err := r.repairCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"date_report": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var repairs []*Repair
+	if err := cursor.All(ctx, &repairs); err != nil {
+		return nil, err
+	}
+
+	return repairs, nil
+}
+
+// AddCoReporter appends the co-report unless the user already reported this repair, in
+// either role. It reports false when nothing was added.
+func (r *repairRepository) AddCoReporter(ctx context.Context, id primitive.ObjectID, coReport CoReport) (bool, error) {
+	result, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{
+			"_id":                  id,
+			"report_by":            bson.M{"$ne": coReport.UserID},
+			"co_reporters.user_id": bson.M{"$ne": coReport.UserID},
+		},
+		bson.M{"$push": bson.M{"co_reporters": coReport}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *repairRepository) AddLabor(ctx context.Context, id primitive.ObjectID, entry LaborEntry, basis CostBasis) (*Repair, error) {
+	return r.updateCosts(ctx, bson.M{"_id": id}, appendLine("labor", entry), basis)
+}
+
+func (r *repairRepository) RemoveLabor(ctx context.Context, id, entryID primitive.ObjectID, basis CostBasis) (*Repair, error) {
+	return r.updateCosts(ctx, bson.M{"_id": id, "labor._id": entryID}, removeLine("labor", entryID), basis)
+}
+
+func (r *repairRepository) AddExternalCharge(ctx context.Context, id primitive.ObjectID, charge ExternalCharge, basis CostBasis) (*Repair, error) {
+	return r.updateCosts(ctx, bson.M{"_id": id}, appendLine("external_charges", charge), basis)
+}
+
+func (r *repairRepository) RemoveExternalCharge(ctx context.Context, id, chargeID primitive.ObjectID, basis CostBasis) (*Repair, error) {
+	return r.updateCosts(ctx, bson.M{"_id": id, "external_charges._id": chargeID}, removeLine("external_charges", chargeID), basis)
+}
+
+func appendLine(field string, line interface{}) bson.M {
+	return bson.M{field: bson.M{"$concatArrays": bson.A{
+		bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+		bson.A{bson.M{"$literal": line}},
+	}}}
+}
+
+func removeLine(field string, lineID primitive.ObjectID) bson.M {
+	return bson.M{field: bson.M{"$filter": bson.M{
+		"input": "$" + field,
+		"cond":  bson.M{"$ne": bson.A{"$$this._id", lineID}},
+	}}}
+}
+
+// updateCosts applies a cost change and reprices the repair in the same update, so that
+// concurrent changes cannot leave a total that misses one of them. It returns the repair
+// as it is afterwards, or nil when nothing matched.
+func (r *repairRepository) updateCosts(ctx context.Context, filter, change bson.M, basis CostBasis) (*Repair, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: change}},
+		{{Key: "$set", Value: bson.M{"total_cost": totalCostExpr(basis)}}},
+	}
+
+	var repair Repair
+	err := r.repairCollection.FindOneAndUpdate(ctx, filter, pipeline,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&repair)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &repair, nil
+}
+
+// RefreshTotalCost reprices the repair from the labor and charges it has at the time of the
+// update.
+func (r *repairRepository) RefreshTotalCost(ctx context.Context, id primitive.ObjectID, basis CostBasis) error {
+	_, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"total_cost": totalCostExpr(basis)}}},
+		},
+	)
+	return err
+}
+
+// totalCostExpr prices a repair the way the cost breakdown does: every line rounded to the
+// cent, the subtotal, then the tax on it.
+func totalCostExpr(basis CostBasis) bson.M {
+	lines := func(field string, amount interface{}) bson.M {
+		return bson.M{"$sum": bson.M{"$map": bson.M{
+			"input": bson.M{"$ifNull": bson.A{field, bson.A{}}},
+			"in":    bson.M{"$round": bson.A{amount, 2}},
+		}}}
+	}
+
+	subtotal := bson.M{"$round": bson.A{bson.M{"$add": bson.A{
+		basis.Parts,
+		lines("$labor", bson.M{"$multiply": bson.A{"$$this.hours", "$$this.rate"}}),
+		lines("$external_charges", "$$this.amount"),
+	}}, 2}}
+
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"subtotal": subtotal},
+		"in": bson.M{"$round": bson.A{bson.M{"$add": bson.A{
+			"$$subtotal",
+			bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{"$$subtotal", basis.TaxRate / 100}}, 2}},
+		}}, 2}},
+	}}
+}
+
+func (r *repairRepository) SetInvoice(ctx context.Context, id primitive.ObjectID, key string, at time.Time) error {
+	_, err := r.repairCollection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"invoice_key": key, "invoice_at": at}},
+	)
+	return err
+}
+
+func (r *repairRepository) GetAssignmentQueue(ctx context.Context, organizationID string) (*AssignmentQueue, error) {
+	var queue AssignmentQueue
+	err := r.queueCollection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&queue)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &queue, nil
+}
+
+func (r *repairRepository) UpsertAssignmentQueue(ctx context.Context, queue *AssignmentQueue) error {
+	filter := bson.M{"organization_id": queue.OrganizationID}
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":    queue.Enabled,
+			"strategy":   queue.Strategy,
+			"members":    queue.Members,
+			"updated_by": queue.UpdatedBy,
+			"updated_at": queue.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        queue.ID,
+			"created_at": queue.CreatedAt,
+		},
+	}
+	_, err := r.queueCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// MarkQueueMemberAssigned records that the member was handed a repair. It only succeeds while
+// the member's last assignment is still previous, so two repairs reported at once do not both
+// go to the member whose turn it was.
+func (r *repairRepository) MarkQueueMemberAssigned(ctx context.Context, queueID primitive.ObjectID, userID string, previous *time.Time, at time.Time) (bool, error) {
+	result, err := r.queueCollection.UpdateOne(ctx,
+		bson.M{
+			"_id": queueID,
+			"members": bson.M{"$elemMatch": bson.M{
+				"user_id":          userID,
+				"last_assigned_at": previous,
+			}},
+		},
+		bson.M{"$set": bson.M{"members.$.last_assigned_at": at}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// CountOpenByAssignee counts the repairs each user is assigned that still need work. Users
+// without any are left out of the map.
+func (r *repairRepository) CountOpenByAssignee(ctx context.Context, organizationID string, userIDs []string) (map[string]int, error) {
+	cursor, err := r.repairCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"organization_id": organizationID,
+			"assigned_to":     bson.M{"$in": userIDs},
+			"status":          bson.M{"$in": openStatuses},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$assigned_to", "open": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		UserID string `bson:"_id"`
+		Open   int    `bson:"open"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Open
+	}
+	return counts, nil
+}
+
+func (r *repairRepository) CreateMaintenancePlan(ctx context.Context, plan *MaintenancePlan) error {
+	_, err := r.planCollection.InsertOne(ctx, plan)
+	return err
+}
+
+func (r *repairRepository) GetMaintenancePlans(ctx context.Context, organizationID string) ([]*MaintenancePlan, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "next_due_at", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := r.planCollection.Find(ctx, bson.M{"organization_id": organizationID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var plans []*MaintenancePlan
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *repairRepository) GetMaintenancePlanByID(ctx context.Context, id primitive.ObjectID) (*MaintenancePlan, error) {
+	var plan MaintenancePlan
+	err := r.planCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&plan)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *repairRepository) UpdateMaintenancePlan(ctx context.Context, plan *MaintenancePlan) error {
+	_, err := r.planCollection.ReplaceOne(ctx, bson.M{"_id": plan.ID}, plan)
+	return err
+}
+
+func (r *repairRepository) DeleteMaintenancePlan(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.planCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// GetDueMaintenancePlans lists the active plans whose next service should be generated by now.
+func (r *repairRepository) GetDueMaintenancePlans(ctx context.Context, now time.Time) ([]*MaintenancePlan, error) {
+	cursor, err := r.planCollection.Find(ctx, bson.M{
+		"active":           true,
+		"next_generate_at": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var plans []*MaintenancePlan
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// AdvanceMaintenancePlan moves the plan on to its next service. It only succeeds while the
+// plan is still at the previous occurrence, so two schedulers do not skip a service.
+func (r *repairRepository) AdvanceMaintenancePlan(ctx context.Context, plan *MaintenancePlan, previous int) (bool, error) {
+	result, err := r.planCollection.UpdateOne(ctx,
+		bson.M{"_id": plan.ID, "occurrence": previous},
+		bson.M{"$set": bson.M{
+			"occurrence":       plan.Occurrence,
+			"next_due_at":      plan.NextDueAt,
+			"next_generate_at": plan.NextGenerateAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *repairRepository) HasPreventiveRepair(ctx context.Context, planID primitive.ObjectID, dueAt time.Time) (bool, error) {
+	count, err := r.repairCollection.CountDocuments(ctx, bson.M{
+		"preventive":          true,
+		"maintenance.plan_id": planID,
+		"maintenance.due_at":  dueAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetPreventiveRepairs lists the organization's preventive repairs due within the range.
+func (r *repairRepository) GetPreventiveRepairs(ctx context.Context, organizationID string, from, to time.Time) ([]*Repair, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "maintenance.due_at", Value: 1}})
+
+	cursor, err := r.repairCollection.Find(ctx, bson.M{
+		"organization_id":    organizationID,
+		"preventive":         true,
+		"maintenance.due_at": bson.M{"$gte": from, "$lte": to},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var repairs []*Repair
+	if err := cursor.All(ctx, &repairs); err != nil {
+		return nil, err
+	}
+	return repairs, nil
+}
+
+// SetChecklistItem replaces one checklist item and returns the repair as it is afterwards, or
+// nil when the repair has no such item.
+func (r *repairRepository) SetChecklistItem(ctx context.Context, id primitive.ObjectID, index int, item ChecklistItem) (*Repair, error) {
+	path := fmt.Sprintf("maintenance.checklist.%d", index)
+
+	var repair Repair
+	err := r.repairCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, path: bson.M{"$exists": true}},
+		bson.M{"$set": bson.M{path: item}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&repair)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &repair, nil
+}
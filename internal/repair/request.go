@@ -4,19 +4,35 @@ type CreateRepairRequest struct {
 	OrganizationID string   `json:"organization_id"`
 	JobName        string   `json:"job_name"`
 	Location       string   `json:"location"`
+	Category       string   `json:"category"`
 	UrgentVote     int      `json:"urgent_vote"`
 	Comment        string   `json:"comment"`
 	ImageReport    []string `json:"image_report"`
+	// AssetID optionally names the asset that needs repair; location defaults to where it is
+	AssetID string `json:"asset_id"`
+	// ConfirmNew skips the duplicate check after the client was shown the candidates
+	ConfirmNew bool `json:"confirm_new"`
 }
 
+type CoReportRepairRequest struct {
+	UrgentVote  int      `json:"urgent_vote"`
+	Comment     string   `json:"comment"`
+	ImageReport []string `json:"image_report"`
+}
+
+// UpdateRepairRequest changes the report. An empty asset_id unlinks the asset.
 type UpdateRepairRequest struct {
 	JobName     string   `json:"job_name"`
 	Location    string   `json:"location"`
+	Category    *string  `json:"category"`
+	AssetID     *string  `json:"asset_id"`
 	UrgentVote  int      `json:"urgent_vote"`
 	Comment     string   `json:"comment"`
 	ImageReport []string `json:"image_report"`
 }
 
+// AssignRepairRequest names the assignee. Leaving assigned_to empty lets the organization's
+// assignment queue pick one.
 type AssignRepairRequest struct {
 	AssignedTo string `json:"assigned_to"`
 }
@@ -25,3 +41,103 @@ type CompleteRepairRequest struct {
 	CommentRepair *string  `json:"comment_repair"`
 	ImageRepair   []string `json:"image_repair"`
 }
+
+type UrgencyVoteRequest struct {
+	Level int `json:"level"`
+}
+
+type HoldRepairRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReopenRepairRequest struct {
+	Reason string `json:"reason"`
+}
+
+type UpdateSLAPolicyRequest struct {
+	OrganizationID string    `json:"organization_id"`
+	Bands          []SLABand `json:"bands"`
+}
+
+// RepairQuery filters repair listings. Status takes a comma-separated list, or "open" for
+// every status that still needs work; from and to bound the report date.
+type RepairQuery struct {
+	OrganizationID string
+	Status         string
+	AssignedTo     string
+	ReportBy       string
+	Location       string
+	From           string
+	To             string
+	MinCost        string
+	MaxCost        string
+	MinUrgency     string
+	MaxUrgency     string
+	PlanID         string
+	AssetID        string
+	Sort           string
+	GroupBy        string
+}
+
+// AddLaborRequest records hours a technician worked. worked_at defaults to now.
+type AddLaborRequest struct {
+	TechnicianID string  `json:"technician_id"`
+	Description  string  `json:"description"`
+	Hours        float64 `json:"hours"`
+	Rate         float64 `json:"rate"`
+	WorkedAt     string  `json:"worked_at"`
+}
+
+type AddExternalChargeRequest struct {
+	Vendor      string  `json:"vendor"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+type UpdateAssignmentQueueRequest struct {
+	OrganizationID string               `json:"organization_id"`
+	Enabled        bool                 `json:"enabled"`
+	Strategy       string               `json:"strategy"`
+	Members        []QueueMemberRequest `json:"members"`
+}
+
+type QueueMemberRequest struct {
+	UserID    string   `json:"user_id"`
+	Skills    []string `json:"skills"`
+	Locations []string `json:"locations"`
+}
+
+// CreateMaintenancePlanRequest sets up a plan. start_at is the first due date and lead_days
+// how many days before each due date its repair is generated.
+type CreateMaintenancePlanRequest struct {
+	OrganizationID  string         `json:"organization_id"`
+	Name            string         `json:"name"`
+	Description     string         `json:"description"`
+	Location        string         `json:"location"`
+	Category        string         `json:"category"`
+	Recurrence      Recurrence     `json:"recurrence"`
+	StartAt         string         `json:"start_at"`
+	LeadDays        int            `json:"lead_days"`
+	Checklist       []string       `json:"checklist"`
+	DefaultAssignee *string        `json:"default_assignee"`
+	ExpectedParts   []ExpectedPart `json:"expected_parts"`
+}
+
+// UpdateMaintenancePlanRequest changes the given fields. An empty default_assignee clears it.
+type UpdateMaintenancePlanRequest struct {
+	Name            *string         `json:"name"`
+	Description     *string         `json:"description"`
+	Location        *string         `json:"location"`
+	Category        *string         `json:"category"`
+	Recurrence      *Recurrence     `json:"recurrence"`
+	StartAt         *string         `json:"start_at"`
+	LeadDays        *int            `json:"lead_days"`
+	Checklist       []string        `json:"checklist"`
+	DefaultAssignee *string         `json:"default_assignee"`
+	ExpectedParts   *[]ExpectedPart `json:"expected_parts"`
+	Active          *bool           `json:"active"`
+}
+
+type ChecklistItemRequest struct {
+	Done bool `json:"done"`
+}
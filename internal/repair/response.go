@@ -13,16 +13,25 @@ type RepairResponse struct {
 	ID             primitive.ObjectID     `json:"id" bson:"_id"`
 	OrganizationID string                 `json:"organization_id" bson:"organization_id"`
 	JobNumber      int                    `json:"job_number" bson:"job_number"`
+	JobCode        string                 `json:"job_code" bson:"job_code"`
 	JobName        string                 `json:"job_name" bson:"job_name"`
 	QRCode         string                 `json:"qrcode" bson:"qrcode"`
 	Location       location.LocationInfor `json:"location" bson:"location"`
+	AssetID        *primitive.ObjectID    `json:"asset_id" bson:"asset_id"`
+	Category       string                 `json:"category" bson:"category"`
+	Preventive     bool                   `json:"preventive" bson:"preventive"`
 	Status         string                 `json:"status" bson:"status"`
 	// Report by
 	DateReport  time.Time      `json:"date_report" bson:"date_report"`
 	ReportBy    user.UserInfor `json:"report_by" bson:"report_by"`
 	UrgentVote  int            `json:"urgent_vote" bson:"urgent_vote"`
+	VoteCount   int            `json:"vote_count" bson:"vote_count"`
+	MyVote      int            `json:"my_vote" bson:"my_vote"`
+	EscalatedAt *time.Time     `json:"escalated_at" bson:"escalated_at"`
 	Comment     string         `json:"comment" bson:"comment"`
 	ImageReport []string       `json:"image_report" bson:"image_report"`
+	// Co-reported by
+	CoReporters []CoReportResponse `json:"co_reporters" bson:"co_reporters"`
 	// Repair by
 	DateRepair    *time.Time               `json:"date_repair" bson:"date_repair"`
 	RepairBy      *user.UserInfor          `json:"repair_by" bson:"repair_by"`
@@ -30,7 +39,260 @@ type RepairResponse struct {
 	ImageRepair   []*string                `json:"image_repair" bson:"image_repair"`
 	ShopItems     *shop.RepairItemsSummary `json:"shop_items,omitempty" bson:"shop_items,omitempty"`
 	TotalCost     *float64                 `json:"total_cost" bson:"total_cost"`
+	SLA           *SLAResponse             `json:"sla" bson:"sla"`
+	StatusHistory []StatusChange           `json:"status_history" bson:"status_history"`
+	// Costs beyond the shop parts; GET /repairs/:id/costs has the full breakdown
+	Labor           []LaborEntry     `json:"labor" bson:"labor"`
+	ExternalCharges []ExternalCharge `json:"external_charges" bson:"external_charges"`
+	InvoiceAt       *time.Time       `json:"invoice_at" bson:"invoice_at"`
+	// Preventive repairs only
+	Maintenance *MaintenanceResponse `json:"maintenance,omitempty" bson:"maintenance,omitempty"`
 
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 }
+
+type CoReportResponse struct {
+	ReportBy    user.UserInfor `json:"report_by"`
+	Comment     string         `json:"comment"`
+	ImageReport []string       `json:"image_report"`
+	ReportedAt  time.Time      `json:"reported_at"`
+}
+
+// DuplicateCandidate is an open repair that looks like the one being reported.
+type DuplicateCandidate struct {
+	ID         string    `json:"id"`
+	JobNumber  int       `json:"job_number"`
+	JobCode    string    `json:"job_code"`
+	JobName    string    `json:"job_name"`
+	Comment    string    `json:"comment"`
+	Status     string    `json:"status"`
+	UrgentVote int       `json:"urgent_vote"`
+	DateReport time.Time `json:"date_report"`
+	Similarity float64   `json:"similarity"`
+}
+
+type UrgencyResponse struct {
+	RepairID    string     `json:"repair_id"`
+	Score       int        `json:"score"`
+	VoteCount   int        `json:"vote_count"`
+	MyVote      int        `json:"my_vote"`
+	Escalated   bool       `json:"escalated"`
+	EscalatedAt *time.Time `json:"escalated_at"`
+}
+
+type SLAResponse struct {
+	Band               string     `json:"band"`
+	AssignDueBy        *time.Time `json:"assign_due_by"`
+	CompleteDueBy      *time.Time `json:"complete_due_by"`
+	AssignedAt         *time.Time `json:"assigned_at"`
+	AssignBreached     bool       `json:"assign_breached"`
+	AssignBreachedAt   *time.Time `json:"assign_breached_at"`
+	CompleteBreached   bool       `json:"complete_breached"`
+	CompleteBreachedAt *time.Time `json:"complete_breached_at"`
+}
+
+type SLAPolicyResponse struct {
+	OrganizationID string     `json:"organization_id"`
+	Bands          []SLABand  `json:"bands"`
+	IsDefault      bool       `json:"is_default"`
+	UpdatedBy      string     `json:"updated_by,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+type SLAReportResponse struct {
+	OrganizationID string          `json:"organization_id"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Overall        SLABandReport   `json:"overall"`
+	Bands          []SLABandReport `json:"bands"`
+}
+
+type SLABandReport struct {
+	Band     string          `json:"band"`
+	Total    int             `json:"total"`
+	Assign   SLATargetReport `json:"assign"`
+	Complete SLATargetReport `json:"complete"`
+}
+
+// SLATargetReport counts one target. Pending repairs have not reached it yet and are still in time.
+type SLATargetReport struct {
+	Met               int     `json:"met"`
+	Breached          int     `json:"breached"`
+	Pending           int     `json:"pending"`
+	CompliancePercent float64 `json:"compliance_percent"`
+	AverageMinutes    float64 `json:"average_minutes"`
+}
+
+// RepairReportResponse shows what repairs cost and how quickly they were handled, per
+// location and per month.
+type RepairReportResponse struct {
+	OrganizationID string           `json:"organization_id"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	Overall        RepairReportRow  `json:"overall"`
+	Months         []MonthReport    `json:"months"`
+	Locations      []LocationReport `json:"locations"`
+}
+
+// RepairReportRow sums up a set of repairs. Mean times run from the report to the assignment
+// and to the completion, over the repairs that got that far.
+type RepairReportRow struct {
+	Total               int              `json:"total"`
+	Assigned            int              `json:"assigned"`
+	Completed           int              `json:"completed"`
+	MeanAssignMinutes   float64          `json:"mean_assign_minutes"`
+	MeanCompleteMinutes float64          `json:"mean_complete_minutes"`
+	TotalCost           float64          `json:"total_cost"`
+	TopCategories       []CategoryReport `json:"top_categories"`
+}
+
+type CategoryReport struct {
+	Category  string  `json:"category"`
+	Total     int     `json:"total"`
+	TotalCost float64 `json:"total_cost"`
+}
+
+type MonthReport struct {
+	Month string `json:"month"`
+	RepairReportRow
+}
+
+type LocationReport struct {
+	Location location.LocationInfor `json:"location"`
+	RepairReportRow
+	Months []MonthReport `json:"months"`
+}
+
+// LocationRepairsResponse is one location's share of a grouped repair listing.
+type LocationRepairsResponse struct {
+	Location location.LocationInfor `json:"location"`
+	Total    int                    `json:"total"`
+	Open     int                    `json:"open"`
+	Repairs  []*RepairResponse      `json:"repairs"`
+}
+
+// CostBreakdownResponse splits a repair's cost into parts, labor and external charges, with
+// tax at the organization's rate on top.
+type CostBreakdownResponse struct {
+	RepairID primitive.ObjectID `json:"repair_id"`
+	JobCode  string             `json:"job_code"`
+	Parts    CostSection        `json:"parts"`
+	Labor    CostSection        `json:"labor"`
+	External CostSection        `json:"external"`
+	Subtotal float64            `json:"subtotal"`
+	TaxRate  float64            `json:"tax_rate"`
+	Tax      float64            `json:"tax"`
+	Total    float64            `json:"total"`
+}
+
+type CostSection struct {
+	Lines []CostLine `json:"lines"`
+	Total float64    `json:"total"`
+}
+
+// CostLine is one billed item. Reference names the product, technician or vendor.
+type CostLine struct {
+	ID          primitive.ObjectID `json:"id"`
+	Reference   string             `json:"reference"`
+	Description string             `json:"description"`
+	Quantity    float64            `json:"quantity"`
+	UnitPrice   float64            `json:"unit_price"`
+	Amount      float64            `json:"amount"`
+}
+
+type InvoiceResponse struct {
+	Key      string    `json:"key"`
+	URL      string    `json:"url"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+type AssignmentQueueResponse struct {
+	OrganizationID string                `json:"organization_id"`
+	Enabled        bool                  `json:"enabled"`
+	Strategy       string                `json:"strategy"`
+	Members        []QueueMemberResponse `json:"members"`
+	UpdatedBy      string                `json:"updated_by,omitempty"`
+	UpdatedAt      *time.Time            `json:"updated_at,omitempty"`
+}
+
+type QueueMemberResponse struct {
+	User           user.UserInfor `json:"user"`
+	Skills         []string       `json:"skills"`
+	Locations      []string       `json:"locations"`
+	OpenRepairs    int            `json:"open_repairs"`
+	LastAssignedAt *time.Time     `json:"last_assigned_at"`
+}
+
+// MaintenanceResponse is the service a preventive repair stands for.
+type MaintenanceResponse struct {
+	PlanID        primitive.ObjectID `json:"plan_id"`
+	DueAt         time.Time          `json:"due_at"`
+	Checklist     []ChecklistItem    `json:"checklist"`
+	ChecklistDone int                `json:"checklist_done"`
+	ExpectedParts []ExpectedPart     `json:"expected_parts"`
+}
+
+type MaintenancePlanResponse struct {
+	ID              primitive.ObjectID     `json:"id"`
+	OrganizationID  string                 `json:"organization_id"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	Location        location.LocationInfor `json:"location"`
+	Category        string                 `json:"category"`
+	Recurrence      Recurrence             `json:"recurrence"`
+	StartAt         time.Time              `json:"start_at"`
+	LeadDays        int                    `json:"lead_days"`
+	Checklist       []string               `json:"checklist"`
+	DefaultAssignee *user.UserInfor        `json:"default_assignee"`
+	ExpectedParts   []ExpectedPart         `json:"expected_parts"`
+	Active          bool                   `json:"active"`
+	NextDueAt       *time.Time             `json:"next_due_at"`
+	NextGenerateAt  *time.Time             `json:"next_generate_at"`
+	CreatedBy       string                 `json:"created_by"`
+	UpdatedBy       string                 `json:"updated_by"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// MaintenanceComplianceResponse tells how well the organization kept its maintenance plans
+// for the services due within the range. The percentage counts services that are due, so
+// upcoming ones do not lower it.
+type MaintenanceComplianceResponse struct {
+	OrganizationID    string                   `json:"organization_id"`
+	From              time.Time                `json:"from"`
+	To                time.Time                `json:"to"`
+	Total             int                      `json:"total"`
+	OnTime            int                      `json:"on_time"`
+	Late              int                      `json:"late"`
+	Missed            int                      `json:"missed"`
+	Upcoming          int                      `json:"upcoming"`
+	CompliancePercent float64                  `json:"compliance_percent"`
+	Plans             []PlanComplianceResponse `json:"plans"`
+}
+
+// PlanComplianceResponse lists one plan's late and missed services; on-time ones are only counted.
+type PlanComplianceResponse struct {
+	PlanID            primitive.ObjectID `json:"plan_id"`
+	Name              string             `json:"name"`
+	Location          string             `json:"location"`
+	Active            bool               `json:"active"`
+	Total             int                `json:"total"`
+	OnTime            int                `json:"on_time"`
+	Late              int                `json:"late"`
+	Missed            int                `json:"missed"`
+	Upcoming          int                `json:"upcoming"`
+	CompliancePercent float64            `json:"compliance_percent"`
+	Exceptions        []ServiceResponse  `json:"exceptions"`
+}
+
+// ServiceResponse is one planned service and the repair generated for it.
+type ServiceResponse struct {
+	RepairID    primitive.ObjectID `json:"repair_id"`
+	JobCode     string             `json:"job_code"`
+	Status      string             `json:"status"`
+	Outcome     string             `json:"outcome"`
+	AssignedTo  *string            `json:"assigned_to"`
+	DueAt       time.Time          `json:"due_at"`
+	CompletedAt *time.Time         `json:"completed_at"`
+}
@@ -11,11 +11,44 @@ func RegisterRoutes(r *gin.Engine, repairHandler *RepairHandler) {
 	{
 		repairGroup.POST("", repairHandler.CreateRepair)
 		repairGroup.GET("", repairHandler.GetRepairs)
+		repairGroup.GET("/sla/policy", repairHandler.GetSLAPolicy)
+		repairGroup.PUT("/sla/policy", repairHandler.UpdateSLAPolicy)
+		repairGroup.GET("/sla/report", repairHandler.GetSLAReport)
+		repairGroup.GET("/report", repairHandler.GetRepairReport)
+		repairGroup.GET("/report/export", repairHandler.ExportRepairReport)
+		repairGroup.GET("/queue", repairHandler.GetAssignmentQueue)
+		repairGroup.PUT("/queue", repairHandler.UpdateAssignmentQueue)
+		repairGroup.POST("/maintenance/plans", repairHandler.CreateMaintenancePlan)
+		repairGroup.GET("/maintenance/plans", repairHandler.GetMaintenancePlans)
+		repairGroup.GET("/maintenance/plans/:plan_id", repairHandler.GetMaintenancePlan)
+		repairGroup.PUT("/maintenance/plans/:plan_id", repairHandler.UpdateMaintenancePlan)
+		repairGroup.DELETE("/maintenance/plans/:plan_id", repairHandler.DeleteMaintenancePlan)
+		repairGroup.GET("/maintenance/compliance", repairHandler.GetMaintenanceCompliance)
 		repairGroup.GET("/:id", repairHandler.GetRepairByID)
 		repairGroup.PUT("/:id", repairHandler.UpdateRepair)
 		repairGroup.DELETE("/:id", repairHandler.DeleteRepair)
 
 		repairGroup.POST("/:id/assign", repairHandler.AssignRepair)
 		repairGroup.POST("/:id/complete", repairHandler.CompleteRepair)
+		repairGroup.POST("/:id/start", repairHandler.StartRepair)
+		repairGroup.POST("/:id/hold", repairHandler.HoldRepair)
+		repairGroup.POST("/:id/resume", repairHandler.ResumeRepair)
+		repairGroup.POST("/:id/verify", repairHandler.VerifyRepair)
+		repairGroup.POST("/:id/reopen", repairHandler.ReopenRepair)
+		repairGroup.GET("/:id/qrcode", repairHandler.GetQRCode)
+
+		repairGroup.PUT("/:id/vote", repairHandler.CastUrgencyVote)
+		repairGroup.DELETE("/:id/vote", repairHandler.RemoveUrgencyVote)
+		repairGroup.POST("/:id/co-report", repairHandler.CoReportRepair)
+
+		repairGroup.GET("/:id/costs", repairHandler.GetCostBreakdown)
+		repairGroup.POST("/:id/labor", repairHandler.AddLabor)
+		repairGroup.DELETE("/:id/labor/:entry_id", repairHandler.RemoveLabor)
+		repairGroup.POST("/:id/charges", repairHandler.AddExternalCharge)
+		repairGroup.DELETE("/:id/charges/:charge_id", repairHandler.RemoveExternalCharge)
+		repairGroup.POST("/:id/invoice", repairHandler.IssueInvoice)
+		repairGroup.GET("/:id/invoice", repairHandler.GetInvoice)
+
+		repairGroup.PUT("/:id/checklist/:index", repairHandler.UpdateChecklistItem)
 	}
 }
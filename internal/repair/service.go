@@ -4,33 +4,83 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
+	"todo-service/helper"
+	"todo-service/internal/asset"
 	"todo-service/internal/location"
+	"todo-service/internal/notification"
+	"todo-service/internal/setting"
 	"todo-service/internal/shop"
 	"todo-service/internal/uploader"
 	"todo-service/internal/user"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const maxJobNumberAttempts = 3
+
 type RepairService interface {
 	CreateRepair(ctx context.Context, req CreateRepairRequest, userID string) (*string, error)
-	GetRepairs(ctx context.Context) ([]*RepairResponse, error)
-	GetRepairByID(ctx context.Context, id string) (*RepairResponse, error)
+	GetRepairs(ctx context.Context, query RepairQuery, userID string) ([]*RepairResponse, error)
+	GetRepairsByLocation(ctx context.Context, query RepairQuery, userID string) ([]*LocationRepairsResponse, error)
+	GetRepairByID(ctx context.Context, id string, userID string) (*RepairResponse, error)
 	UpdateRepair(ctx context.Context, req UpdateRepairRequest, id string, userID string) error
 	DeleteRepair(ctx context.Context, id string, userID string) error
 
 	AssignRepair(ctx context.Context, id string, userID string, req AssignRepairRequest) error
 	CompleteRepair(ctx context.Context, id string, req CompleteRepairRequest, userID string) error
+	StartRepair(ctx context.Context, id string, userID string) error
+	HoldRepair(ctx context.Context, id string, req HoldRepairRequest, userID string) error
+	ResumeRepair(ctx context.Context, id string, userID string) error
+	VerifyRepair(ctx context.Context, id string, userID string) error
+	ReopenRepair(ctx context.Context, id string, req ReopenRepairRequest, userID string) error
+
+	CastUrgencyVote(ctx context.Context, id string, req UrgencyVoteRequest, userID string) (*UrgencyResponse, error)
+	RemoveUrgencyVote(ctx context.Context, id string, userID string) (*UrgencyResponse, error)
+	CoReportRepair(ctx context.Context, id string, req CoReportRepairRequest, userID string) (*UrgencyResponse, error)
+
+	GetRepairQRCode(ctx context.Context, id string) (string, error)
+	GetRepairActions(ctx context.Context, id string, userID string) ([]string, error)
+
+	AddLabor(ctx context.Context, id string, req AddLaborRequest, userID string) (*CostBreakdownResponse, error)
+	RemoveLabor(ctx context.Context, id, entryID string, userID string) (*CostBreakdownResponse, error)
+	AddExternalCharge(ctx context.Context, id string, req AddExternalChargeRequest, userID string) (*CostBreakdownResponse, error)
+	RemoveExternalCharge(ctx context.Context, id, chargeID string, userID string) (*CostBreakdownResponse, error)
+	GetCostBreakdown(ctx context.Context, id string, userID string) (*CostBreakdownResponse, error)
+	IssueInvoice(ctx context.Context, id string, userID string) (*InvoiceResponse, error)
+	GetInvoice(ctx context.Context, id string, userID string) (*InvoiceResponse, error)
+
+	GetSLAPolicy(ctx context.Context, organizationID string, userID string) (*SLAPolicyResponse, error)
+	UpdateSLAPolicy(ctx context.Context, req UpdateSLAPolicyRequest, userID string) (*SLAPolicyResponse, error)
+	GetSLAReport(ctx context.Context, organizationID, from, to string, userID string) (*SLAReportResponse, error)
+	GetRepairReport(ctx context.Context, organizationID, from, to string, userID string) (*RepairReportResponse, error)
+	ExportRepairReport(ctx context.Context, organizationID, from, to string, userID string) ([]byte, error)
+
+	GetAssignmentQueue(ctx context.Context, organizationID string, userID string) (*AssignmentQueueResponse, error)
+	UpdateAssignmentQueue(ctx context.Context, req UpdateAssignmentQueueRequest, userID string) (*AssignmentQueueResponse, error)
+
+	CreateMaintenancePlan(ctx context.Context, req CreateMaintenancePlanRequest, userID string) (*MaintenancePlanResponse, error)
+	GetMaintenancePlans(ctx context.Context, organizationID string, userID string) ([]*MaintenancePlanResponse, error)
+	GetMaintenancePlan(ctx context.Context, id string, userID string) (*MaintenancePlanResponse, error)
+	UpdateMaintenancePlan(ctx context.Context, id string, req UpdateMaintenancePlanRequest, userID string) (*MaintenancePlanResponse, error)
+	DeleteMaintenancePlan(ctx context.Context, id string, userID string) error
+	GenerateMaintenanceRepairs(ctx context.Context, now time.Time) (int, error)
+	GetMaintenanceCompliance(ctx context.Context, organizationID, from, to string, userID string) (*MaintenanceComplianceResponse, error)
+	UpdateChecklistItem(ctx context.Context, id string, index int, req ChecklistItemRequest, userID string) (*MaintenanceResponse, error)
 }
 
 type repairService struct {
-	RepairRepo      RepairRepository
-	LocationService location.LocationService
-	UserService     user.UserService
-	UploaderService uploader.ImageService
-	ShopService     shop.ShopService
-	Policy          *Policy
+	RepairRepo          RepairRepository
+	LocationService     location.LocationService
+	UserService         user.UserService
+	UploaderService     uploader.ImageService
+	ShopService         shop.ShopService
+	SettingService      setting.SettingService
+	NotificationService notification.NotificationService
+	AssetRepo           asset.AssetRepository
+	Policy              *Policy
 }
 
 func NewRepairService(
@@ -39,14 +89,20 @@ func NewRepairService(
 	UserService user.UserService,
 	UploaderService uploader.ImageService,
 	ShopService shop.ShopService,
+	SettingService setting.SettingService,
+	NotificationService notification.NotificationService,
+	AssetRepo asset.AssetRepository,
 ) RepairService {
 	return &repairService{
-		RepairRepo:      RepairRepo,
-		LocationService: LocationService,
-		UserService:     UserService,
-		UploaderService: UploaderService,
-		ShopService:     ShopService,
-		Policy:          NewPolicy(),
+		RepairRepo:          RepairRepo,
+		LocationService:     LocationService,
+		UserService:         UserService,
+		UploaderService:     UploaderService,
+		ShopService:         ShopService,
+		SettingService:      SettingService,
+		NotificationService: NotificationService,
+		AssetRepo:           AssetRepo,
+		Policy:              NewPolicy(),
 	}
 }
 
@@ -60,12 +116,24 @@ func (s *repairService) CreateRepair(ctx context.Context, req CreateRepairReques
 		return nil, fmt.Errorf("job_name is required")
 	}
 
+	var assetID *primitive.ObjectID
+	if req.AssetID != "" {
+		reported, err := s.resolveAsset(ctx, req.AssetID, req.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		assetID = &reported.ID
+		if req.Location == "" {
+			req.Location = reported.Location
+		}
+	}
+
 	if req.Location == "" {
 		return nil, fmt.Errorf("location is required")
 	}
 
-	if req.UrgentVote < 0 {
-		return nil, fmt.Errorf("urgent_vote must be greater than 0")
+	if req.UrgentVote < 0 || req.UrgentVote > MaxUrgencyLevel {
+		return nil, fmt.Errorf("urgent_vote must be between 0 and %d", MaxUrgencyLevel)
 	}
 
 	if req.Comment == "" {
@@ -76,48 +144,121 @@ func (s *repairService) CreateRepair(ctx context.Context, req CreateRepairReques
 		return nil, fmt.Errorf("image_report is required")
 	}
 
-	jobCount, err := s.RepairRepo.GetJobCount(ctx, req.OrganizationID)
-	if err != nil {
-		return nil, err
+	if !req.ConfirmNew {
+		candidates, err := s.findDuplicates(ctx, req, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			loc := s.SettingService.GetUserLocation(ctx, userID, req.OrganizationID)
+			for i := range candidates {
+				candidates[i].DateReport = candidates[i].DateReport.In(loc)
+			}
+			return nil, &DuplicateRepairError{Candidates: candidates}
+		}
 	}
 
 	id := primitive.NewObjectID()
 	qrCode := fmt.Sprintf("SENBOX.ORG[REPAIR]:%s", id.Hex())
+	now := time.Now()
+
+	// The reporter's urgency counts as the first vote
+	var votes []UrgencyVote
+	if req.UrgentVote > 0 {
+		votes = append(votes, UrgencyVote{UserID: userID, Level: req.UrgentVote, VotedAt: now})
+	}
 
 	repair := &Repair{
 		ID:             id,
 		OrganizationID: req.OrganizationID,
-		JobNumber:      jobCount + 1,
 		QRCode:         qrCode,
 		JobName:        req.JobName,
 		Location:       req.Location,
+		Category:       strings.ToLower(strings.TrimSpace(req.Category)),
+		AssetID:        assetID,
 		AssignedTo:     nil,
-		Status:         "pending",
+		Status:         StatusPending,
 		UrgentVote:     req.UrgentVote,
+		Votes:          votes,
 		Comment:        req.Comment,
-		DateReport:     time.Now(),
+		DateReport:     now,
 		ReportBy:       userID,
 		ImageReport:    req.ImageReport,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		StatusHistory:  []StatusChange{{To: StatusPending, By: userID, At: now}},
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 
-	err = s.RepairRepo.CreateRepair(ctx, repair)
-	if err != nil {
+	applySLA(repair, s.slaBands(ctx, req.OrganizationID), now)
+
+	if err := s.insertRepair(ctx, repair); err != nil {
 		return nil, err
 	}
 
+	s.autoAssign(ctx, repair)
+
 	repairID := id.Hex()
 
 	return &repairID, nil
 }
 
-func (s *repairService) GetRepairs(ctx context.Context) ([]*RepairResponse, error) {
-	repairs, err := s.RepairRepo.GetRepairs(ctx)
+// insertRepair numbers the repair from the organization's counter and saves it.
+func (s *repairService) insertRepair(ctx context.Context, repair *Repair) error {
+
+	format := s.jobNumberFormat(ctx, repair.OrganizationID)
+	reportedAt := repair.DateReport.In(s.SettingService.GetOrganizationLocation(ctx, repair.OrganizationID))
+
+	for attempt := 1; ; attempt++ {
+		number, err := s.RepairRepo.NextJobNumber(ctx, repair.OrganizationID)
+		if err != nil {
+			return err
+		}
+		repair.JobNumber = number
+		repair.JobCode = helper.FormatSequence(format, number, reportedAt)
+
+		err = s.RepairRepo.CreateRepair(ctx, repair)
+		if err == nil {
+			return nil
+		}
+		// The number was taken before the counter existed; the counter has moved past it
+		if !mongo.IsDuplicateKeyError(err) || attempt == maxJobNumberAttempts {
+			return err
+		}
+	}
+}
+
+// jobNumberFormat falls back to the default format when the settings cannot be read.
+func (s *repairService) jobNumberFormat(ctx context.Context, organizationID string) string {
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to load repair number format for %s: %v", organizationID, err)
+		return setting.DefaultRepairNumberFormat
+	}
+	return orgSetting.JobNumberFormat()
+}
+
+func (s *repairService) GetRepairs(ctx context.Context, query RepairQuery, userID string) ([]*RepairResponse, error) {
+
+	filter, err := s.buildRepairFilter(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	repairs, err := s.RepairRepo.GetRepairs(ctx, *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildRepairs(ctx, repairs, userID)
+}
+
+// buildRepairs maps repairs to responses. Locations and time zones are looked up once
+// each, since a listing usually holds several repairs of the same building.
+func (s *repairService) buildRepairs(ctx context.Context, repairs []*Repair, userID string) ([]*RepairResponse, error) {
+
+	locations := map[string]*location.LocationInfor{}
+	zones := map[string]*time.Location{}
+
 	var results []*RepairResponse
 	for _, repair := range repairs {
 		reportBy, err := s.UserService.GetUserInfor(ctx, repair.ReportBy)
@@ -133,9 +274,13 @@ func (s *repairService) GetRepairs(ctx context.Context) ([]*RepairResponse, erro
 			}
 		}
 
-		location, err := s.LocationService.GetLocationByID(ctx, repair.Location)
-		if err != nil {
-			return nil, err
+		location, ok := locations[repair.Location]
+		if !ok {
+			location, err = s.LocationService.GetLocationByID(ctx, repair.Location)
+			if err != nil {
+				return nil, err
+			}
+			locations[repair.Location] = location
 		}
 
 		imageReport := make([]string, len(repair.ImageReport))
@@ -178,13 +323,22 @@ func (s *repairService) GetRepairs(ctx context.Context) ([]*RepairResponse, erro
 			shopItems = nil
 		}
 
-		results = append(results, buildRepairResponse(repair, reportBy, repairBy, location, imageReport, imageRepair, shopItems))
+		loc, ok := zones[repair.OrganizationID]
+		if !ok {
+			loc = s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID)
+			zones[repair.OrganizationID] = loc
+		}
+
+		result := buildRepairResponse(repair, reportBy, repairBy, location, imageReport, imageRepair, shopItems, loc, userID)
+		result.CoReporters = s.buildCoReports(ctx, repair, loc)
+
+		results = append(results, result)
 	}
 
 	return results, nil
 }
 
-func (s *repairService) GetRepairByID(ctx context.Context, id string) (*RepairResponse, error) {
+func (s *repairService) GetRepairByID(ctx context.Context, id string, userID string) (*RepairResponse, error) {
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -257,7 +411,10 @@ func (s *repairService) GetRepairByID(ctx context.Context, id string) (*RepairRe
 		shopItems = nil
 	}
 
-	result := buildRepairResponse(repair, reportBy, repairBy, location, imageReport, imageRepair, shopItems)
+	loc := s.SettingService.GetUserLocation(ctx, userID, repair.OrganizationID)
+
+	result := buildRepairResponse(repair, reportBy, repairBy, location, imageReport, imageRepair, shopItems, loc, userID)
+	result.CoReporters = s.buildCoReports(ctx, repair, loc)
 
 	return result, nil
 }
@@ -286,8 +443,20 @@ func (s *repairService) UpdateRepair(ctx context.Context, req UpdateRepairReques
 		existingRepair.Location = req.Location
 	}
 
-	if req.UrgentVote > 0 {
-		existingRepair.UrgentVote = req.UrgentVote
+	if req.Category != nil {
+		existingRepair.Category = strings.ToLower(strings.TrimSpace(*req.Category))
+	}
+
+	if req.AssetID != nil {
+		if *req.AssetID == "" {
+			existingRepair.AssetID = nil
+		} else {
+			reported, err := s.resolveAsset(ctx, *req.AssetID, existingRepair.OrganizationID)
+			if err != nil {
+				return err
+			}
+			existingRepair.AssetID = &reported.ID
+		}
 	}
 
 	if req.Comment != "" {
@@ -311,6 +480,13 @@ func (s *repairService) UpdateRepair(ctx context.Context, req UpdateRepairReques
 		return err
 	}
 
+	// urgent_vote on an update changes the caller's own vote rather than the score
+	if req.UrgentVote > 0 {
+		if _, err := s.CastUrgencyVote(ctx, id, UrgencyVoteRequest{Level: req.UrgentVote}, userID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -348,6 +524,16 @@ func (s *repairService) DeleteRepair(ctx context.Context, id string, userID stri
 		}
 	}
 
+	if err := s.deleteCoReportImages(ctx, existingRepair); err != nil {
+		return err
+	}
+
+	if existingRepair.InvoiceKey != "" {
+		if err := s.UploaderService.DeletePDFKey(ctx, existingRepair.InvoiceKey); err != nil {
+			return err
+		}
+	}
+
 	err = s.RepairRepo.DeleteRepair(ctx, objectID)
 	if err != nil {
 		return err
@@ -368,21 +554,38 @@ func (s *repairService) AssignRepair(ctx context.Context, id string, userID stri
 		return err
 	}
 
+	if existingRepair == nil {
+		return fmt.Errorf("repair not found")
+	}
+
 	if err := s.Policy.CanAssignRepair(ctx, existingRepair, userID); err != nil {
 		return err
 	}
 
-	existingRepair.AssignedTo = &req.AssignedTo
-	existingRepair.Status = "assigned"
-	existingRepair.UpdatedAt = time.Now()
-
-	err = s.RepairRepo.UpdateRepair(ctx, objectID, existingRepair)
-	if err != nil {
+	assignee := req.AssignedTo
+	if assignee == "" {
+		assignee, err = s.pickAssignee(ctx, existingRepair)
+		if err != nil {
+			return err
+		}
+		if assignee == "" {
+			return fmt.Errorf("assigned_to is required, the assignment queue has no one for this repair")
+		}
+	} else if err := s.ensureStaff(ctx, assignee, existingRepair.OrganizationID); err != nil {
 		return err
 	}
 
-	return nil
+	now := time.Now()
+	previous := existingRepair.Status
+	existingRepair.AssignedTo = &assignee
+	recordStatus(existingRepair, StatusAssigned, userID, "", now)
+	// Reassigning does not restart the assign target
+	if existingRepair.AssignedAt == nil {
+		existingRepair.AssignedAt = &now
+	}
+	evaluateSLA(existingRepair, now)
 
+	return s.saveTransition(ctx, existingRepair, previous)
 }
 
 func (s *repairService) CompleteRepair(ctx context.Context, id string, req CompleteRepairRequest, userID string) error {
@@ -397,6 +600,10 @@ func (s *repairService) CompleteRepair(ctx context.Context, id string, req Compl
 		return err
 	}
 
+	if existingRepair == nil {
+		return fmt.Errorf("repair not found")
+	}
+
 	if err := s.Policy.CanCompleteRepair(ctx, existingRepair, userID); err != nil {
 		return err
 	}
@@ -414,22 +621,188 @@ func (s *repairService) CompleteRepair(ctx context.Context, id string, req Compl
 		existingRepair.ImageRepair = imageRepairPtrs
 	}
 
-	// Calculate total cost from shop items
-	shopItemsSummary, err := s.ShopService.GetRepairItems(ctx, objectID)
-	if err == nil && shopItemsSummary != nil {
-		existingRepair.TotalCost = &shopItemsSummary.TotalCost
-	}
-
 	now := time.Now()
-	existingRepair.Status = "completed"
+	previous := existingRepair.Status
+	recordStatus(existingRepair, StatusCompleted, userID, "", now)
 	existingRepair.DateRepair = &now
 	existingRepair.RepairBy = &userID
-	existingRepair.UpdatedAt = now
+	// Completing an unassigned repair also meets its assign target
+	if existingRepair.AssignedAt == nil {
+		existingRepair.AssignedAt = &now
+	}
+	evaluateSLA(existingRepair, now)
 
-	err = s.RepairRepo.UpdateRepair(ctx, objectID, existingRepair)
+	if err := s.saveTransition(ctx, existingRepair, previous); err != nil {
+		return err
+	}
+
+	// Total cost covers shop items, labor and external charges with tax. It is priced in the
+	// database from the labor and charges stored by then, so a line added meanwhile counts
+	basis, err := s.costBasis(ctx, existingRepair)
+	if err == nil {
+		err = s.RepairRepo.RefreshTotalCost(ctx, objectID, basis)
+	}
+	if err != nil {
+		log.Printf("[WARN] failed to price repair %s on completion: %v", id, err)
+	}
+
+	return nil
+}
+
+func (s *repairService) StartRepair(ctx context.Context, id string, userID string) error {
+	return s.transitionRepair(ctx, id, userID, func(repair *Repair, now time.Time) error {
+		if err := s.Policy.CanStartRepair(ctx, repair, userID); err != nil {
+			return err
+		}
+		recordStatus(repair, StatusInProgress, userID, "", now)
+		return nil
+	})
+}
+
+func (s *repairService) HoldRepair(ctx context.Context, id string, req HoldRepairRequest, userID string) error {
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	return s.transitionRepair(ctx, id, userID, func(repair *Repair, now time.Time) error {
+		if err := s.Policy.CanHoldRepair(ctx, repair, userID); err != nil {
+			return err
+		}
+		recordStatus(repair, StatusOnHold, userID, reason, now)
+		return nil
+	})
+}
+
+func (s *repairService) ResumeRepair(ctx context.Context, id string, userID string) error {
+	return s.transitionRepair(ctx, id, userID, func(repair *Repair, now time.Time) error {
+		if err := s.Policy.CanResumeRepair(ctx, repair, userID); err != nil {
+			return err
+		}
+		recordStatus(repair, StatusInProgress, userID, "", now)
+		return nil
+	})
+}
+
+func (s *repairService) VerifyRepair(ctx context.Context, id string, userID string) error {
+	return s.transitionRepair(ctx, id, userID, func(repair *Repair, now time.Time) error {
+		if err := s.Policy.CanVerifyRepair(ctx, repair, userID); err != nil {
+			return err
+		}
+		recordStatus(repair, StatusVerified, userID, "", now)
+		return nil
+	})
+}
+
+// ReopenRepair sends a finished repair back for more work. The previous completion stays in
+// the status history, and the complete target is judged again on the next completion.
+func (s *repairService) ReopenRepair(ctx context.Context, id string, req ReopenRepairRequest, userID string) error {
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	return s.transitionRepair(ctx, id, userID, func(repair *Repair, now time.Time) error {
+		if err := s.Policy.CanReopenRepair(ctx, repair, userID); err != nil {
+			return err
+		}
+		recordStatus(repair, StatusReopened, userID, reason, now)
+		repair.DateRepair = nil
+		evaluateSLA(repair, now)
+		return nil
+	})
+}
+
+// transitionRepair loads the repair, lets apply check and change it, then saves it.
+func (s *repairService) transitionRepair(ctx context.Context, id string, userID string, apply func(repair *Repair, now time.Time) error) error {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	existingRepair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
 	if err != nil {
 		return err
 	}
 
+	if existingRepair == nil {
+		return fmt.Errorf("repair not found")
+	}
+
+	previous := existingRepair.Status
+	if err := apply(existingRepair, time.Now()); err != nil {
+		return err
+	}
+
+	return s.saveTransition(ctx, existingRepair, previous)
+}
+
+// saveTransition saves a repair that moved on from the previous status. The save only lands
+// while the stored repair is still in that status, so when two transitions race the second
+// gets a conflict instead of forking the status history.
+func (s *repairService) saveTransition(ctx context.Context, repair *Repair, previous string) error {
+	saved, err := s.RepairRepo.UpdateRepairFromStatus(ctx, repair.ID, previous, repair)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return helper.Conflict("the repair was moved to another status meanwhile, reload it and try again")
+	}
 	return nil
 }
+
+// recordStatus moves the repair to status and appends the change to its history.
+func recordStatus(repair *Repair, status, userID, reason string, now time.Time) {
+	repair.StatusHistory = append(repair.StatusHistory, StatusChange{
+		From:   repair.Status,
+		To:     status,
+		By:     userID,
+		Reason: reason,
+		At:     now,
+	})
+	repair.Status = status
+	repair.UpdatedAt = now
+}
+
+func (s *repairService) GetRepairQRCode(ctx context.Context, id string) (string, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return "", err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return "", err
+	}
+
+	if repair == nil {
+		return "", fmt.Errorf("repair not found")
+	}
+
+	return repair.QRCode, nil
+}
+
+// isOrganizationStaff reports whether the user is a staff member of the organization.
+func (s *repairService) isOrganizationStaff(ctx context.Context, userID, organizationID string) bool {
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check staff %s in organization %s: %v", userID, organizationID, err)
+		return false
+	}
+	return staff != nil && staff.UserID != ""
+}
+
+// isOrganizationMember reports whether the user is a teacher or staff member of the organization.
+func (s *repairService) isOrganizationMember(ctx context.Context, userID, organizationID string) bool {
+	teacher, err := s.UserService.GetTeacherInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check teacher %s in organization %s: %v", userID, organizationID, err)
+	} else if teacher != nil && teacher.UserID != "" {
+		return true
+	}
+	return s.isOrganizationStaff(ctx, userID, organizationID)
+}
@@ -0,0 +1,361 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"todo-service/helper"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxSLABands           = 10
+	defaultSLAReportDays  = 30
+	maxSLAReportRangeDays = 366
+)
+
+// DefaultSLABands apply to organizations that have not configured a policy.
+var DefaultSLABands = []SLABand{
+	{Name: "low", MinUrgentVote: 0, AssignWithinMinutes: 72 * 60, CompleteWithinMinutes: 14 * 24 * 60},
+	{Name: "medium", MinUrgentVote: 3, AssignWithinMinutes: 24 * 60, CompleteWithinMinutes: 3 * 24 * 60},
+	{Name: "high", MinUrgentVote: 5, AssignWithinMinutes: 4 * 60, CompleteWithinMinutes: 24 * 60},
+}
+
+// bandFor picks the band with the highest MinUrgentVote the vote count reaches.
+func bandFor(bands []SLABand, urgentVote int) *SLABand {
+	var selected *SLABand
+	for i := range bands {
+		band := &bands[i]
+		if urgentVote < band.MinUrgentVote {
+			continue
+		}
+		if selected == nil || band.MinUrgentVote > selected.MinUrgentVote {
+			selected = band
+		}
+	}
+	return selected
+}
+
+// applySLA sets the band and due-by times of the repair, counted from when it was reported.
+func applySLA(repair *Repair, bands []SLABand, now time.Time) {
+	// Preventive repairs are due when their plan says, whatever their urgency
+	if repair.Preventive && repair.Maintenance != nil {
+		dueAt := repair.Maintenance.DueAt
+		repair.SLABand = PreventiveSLABand
+		repair.AssignDueBy = nil
+		repair.CompleteDueBy = &dueAt
+		evaluateSLA(repair, now)
+		return
+	}
+
+	band := bandFor(bands, repair.UrgentVote)
+	if band == nil {
+		repair.SLABand = ""
+		repair.AssignDueBy = nil
+		repair.CompleteDueBy = nil
+		repair.AssignBreachedAt = nil
+		repair.CompleteBreachedAt = nil
+		return
+	}
+
+	assignDueBy := repair.DateReport.Add(time.Duration(band.AssignWithinMinutes) * time.Minute)
+	completeDueBy := repair.DateReport.Add(time.Duration(band.CompleteWithinMinutes) * time.Minute)
+
+	repair.SLABand = band.Name
+	repair.AssignDueBy = &assignDueBy
+	repair.CompleteDueBy = &completeDueBy
+	evaluateSLA(repair, now)
+}
+
+// evaluateSLA derives the breach stamps from the repair's current state. A target is
+// breached when it was met late, or when it is still open and its due-by has passed.
+func evaluateSLA(repair *Repair, now time.Time) {
+
+	if repair.AssignDueBy != nil {
+		switch {
+		case repair.AssignedAt != nil:
+			repair.AssignBreachedAt = breachedAt(*repair.AssignDueBy, *repair.AssignedAt)
+		case repair.Status == StatusPending:
+			repair.AssignBreachedAt = breachedAt(*repair.AssignDueBy, now)
+		}
+	}
+
+	if repair.CompleteDueBy != nil {
+		if repair.DateRepair != nil {
+			repair.CompleteBreachedAt = breachedAt(*repair.CompleteDueBy, *repair.DateRepair)
+		} else {
+			repair.CompleteBreachedAt = breachedAt(*repair.CompleteDueBy, now)
+		}
+	}
+}
+
+func breachedAt(dueBy, at time.Time) *time.Time {
+	if at.After(dueBy) {
+		return &dueBy
+	}
+	return nil
+}
+
+// slaBands returns the organization's bands. A failed lookup falls back to the defaults
+// so that reporting a repair never depends on the policy being readable.
+func (s *repairService) slaBands(ctx context.Context, organizationID string) []SLABand {
+	policy, err := s.RepairRepo.GetSLAPolicy(ctx, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to get SLA policy of organization %s: %v", organizationID, err)
+		return DefaultSLABands
+	}
+	if policy == nil || len(policy.Bands) == 0 {
+		return DefaultSLABands
+	}
+	return policy.Bands
+}
+
+func (s *repairService) GetSLAPolicy(ctx context.Context, organizationID string, userID string) (*SLAPolicyResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewSLA(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.RepairRepo.GetSLAPolicy(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil || len(policy.Bands) == 0 {
+		return &SLAPolicyResponse{
+			OrganizationID: organizationID,
+			Bands:          DefaultSLABands,
+			IsDefault:      true,
+		}, nil
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+	updatedAt := policy.UpdatedAt.In(loc)
+
+	return &SLAPolicyResponse{
+		OrganizationID: organizationID,
+		Bands:          policy.Bands,
+		UpdatedBy:      policy.UpdatedBy,
+		UpdatedAt:      &updatedAt,
+	}, nil
+}
+
+// UpdateSLAPolicy replaces the organization's bands. Repairs keep the due-by times they were
+// given when reported; only new reports and urgency changes use the new bands.
+func (s *repairService) UpdateSLAPolicy(ctx context.Context, req UpdateSLAPolicyRequest, userID string) (*SLAPolicyResponse, error) {
+
+	if req.OrganizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanManageSLA(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	bands, err := validateSLABands(req.Bands)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	policy := &SLAPolicy{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: req.OrganizationID,
+		Bands:          bands,
+		UpdatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.RepairRepo.UpsertSLAPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, req.OrganizationID)
+	updatedAt := now.In(loc)
+
+	return &SLAPolicyResponse{
+		OrganizationID: req.OrganizationID,
+		Bands:          bands,
+		UpdatedBy:      userID,
+		UpdatedAt:      &updatedAt,
+	}, nil
+}
+
+func validateSLABands(bands []SLABand) ([]SLABand, error) {
+
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("bands is required")
+	}
+
+	if len(bands) > maxSLABands {
+		return nil, fmt.Errorf("a policy cannot have more than %d bands", maxSLABands)
+	}
+
+	names := map[string]bool{}
+	votes := map[int]bool{}
+	result := make([]SLABand, 0, len(bands))
+
+	for _, band := range bands {
+		band.Name = strings.TrimSpace(band.Name)
+		if band.Name == "" {
+			return nil, fmt.Errorf("band name is required")
+		}
+		if names[strings.ToLower(band.Name)] {
+			return nil, fmt.Errorf("band %s is listed more than once", band.Name)
+		}
+		names[strings.ToLower(band.Name)] = true
+
+		if band.MinUrgentVote < 0 {
+			return nil, fmt.Errorf("min_urgent_vote of band %s cannot be negative", band.Name)
+		}
+		if votes[band.MinUrgentVote] {
+			return nil, fmt.Errorf("more than one band starts at %d urgent votes", band.MinUrgentVote)
+		}
+		votes[band.MinUrgentVote] = true
+
+		if band.AssignWithinMinutes <= 0 {
+			return nil, fmt.Errorf("assign_within_minutes of band %s must be greater than 0", band.Name)
+		}
+		if band.CompleteWithinMinutes < band.AssignWithinMinutes {
+			return nil, fmt.Errorf("complete_within_minutes of band %s cannot be shorter than assign_within_minutes", band.Name)
+		}
+
+		result = append(result, band)
+	}
+
+	if !votes[0] {
+		return nil, fmt.Errorf("one band must start at 0 urgent votes so that every repair has a target")
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].MinUrgentVote < result[j].MinUrgentVote
+	})
+
+	return result, nil
+}
+
+func (s *repairService) GetSLAReport(ctx context.Context, organizationID, from, to string, userID string) (*SLAReportResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	if err := s.Policy.CanViewSLA(ctx, userID, s.isOrganizationStaff(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+
+	toTime := time.Now().UTC()
+	if to != "" {
+		parsed, err := helper.ParseDateTime(to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		toTime = parsed
+	}
+
+	fromTime := helper.StartOfDay(toTime.AddDate(0, 0, -defaultSLAReportDays), loc).UTC()
+	if from != "" {
+		parsed, err := helper.ParseDateTime(from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		fromTime = parsed
+	}
+
+	if !fromTime.Before(toTime) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	if toTime.Sub(fromTime) > maxSLAReportRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("the range cannot be longer than %d days", maxSLAReportRangeDays)
+	}
+
+	stats, err := s.RepairRepo.GetSLAStats(ctx, organizationID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// List bands in the order of the current policy, then any band that was renamed or
+	// removed since the repairs were reported.
+	byBand := make(map[string]SLABandStats, len(stats))
+	for _, stat := range stats {
+		byBand[stat.Band] = stat
+	}
+
+	var ordered []SLABandStats
+	for _, band := range s.slaBands(ctx, organizationID) {
+		if stat, ok := byBand[band.Name]; ok {
+			ordered = append(ordered, stat)
+			delete(byBand, band.Name)
+		}
+	}
+	for _, stat := range stats {
+		if _, ok := byBand[stat.Band]; ok {
+			ordered = append(ordered, stat)
+		}
+	}
+
+	overall := SLABandStats{Band: "all"}
+	bands := make([]SLABandReport, 0, len(ordered))
+	for _, stat := range ordered {
+		bands = append(bands, bandReport(stat))
+
+		overall.Total += stat.Total
+		overall.Assigned += stat.Assigned
+		overall.Completed += stat.Completed
+		overall.AssignMet += stat.AssignMet
+		overall.AssignBreached += stat.AssignBreached
+		overall.CompleteMet += stat.CompleteMet
+		overall.CompleteBreached += stat.CompleteBreached
+		overall.AverageAssignMs += stat.AverageAssignMs * float64(stat.Assigned)
+		overall.AverageCompleteMs += stat.AverageCompleteMs * float64(stat.Completed)
+	}
+	if overall.Assigned > 0 {
+		overall.AverageAssignMs /= float64(overall.Assigned)
+	}
+	if overall.Completed > 0 {
+		overall.AverageCompleteMs /= float64(overall.Completed)
+	}
+
+	return &SLAReportResponse{
+		OrganizationID: organizationID,
+		From:           fromTime.In(loc),
+		To:             toTime.In(loc),
+		Overall:        bandReport(overall),
+		Bands:          bands,
+	}, nil
+}
+
+func bandReport(stat SLABandStats) SLABandReport {
+	return SLABandReport{
+		Band:     stat.Band,
+		Total:    stat.Total,
+		Assign:   targetReport(stat.Total, stat.AssignMet, stat.AssignBreached, stat.AverageAssignMs),
+		Complete: targetReport(stat.Total, stat.CompleteMet, stat.CompleteBreached, stat.AverageCompleteMs),
+	}
+}
+
+func targetReport(total, met, breached int, averageMs float64) SLATargetReport {
+	report := SLATargetReport{
+		Met:            met,
+		Breached:       breached,
+		Pending:        total - met - breached,
+		AverageMinutes: math.Round(averageMs/float64(time.Minute/time.Millisecond)*10) / 10,
+	}
+	if met+breached > 0 {
+		report.CompliancePercent = math.Round(float64(met)*1000/float64(met+breached)) / 10
+	}
+	return report
+}
@@ -0,0 +1,58 @@
+package repair
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const DefaultSLAWorkerInterval = 5 * time.Minute
+
+// SLAWorker stamps repairs whose assign or complete targets passed while still open.
+// The stamps are idempotent, so several instances can run it at once.
+type SLAWorker struct {
+	RepairRepo RepairRepository
+	Interval   time.Duration
+}
+
+func NewSLAWorker(repairRepo RepairRepository, interval time.Duration) *SLAWorker {
+	if interval <= 0 {
+		interval = DefaultSLAWorkerInterval
+	}
+	return &SLAWorker{
+		RepairRepo: repairRepo,
+		Interval:   interval,
+	}
+}
+
+// Start runs the worker until ctx is cancelled.
+func (w *SLAWorker) Start(ctx context.Context) {
+	log.Printf("Repair SLA worker started, checking every %s", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx, time.Now().UTC()); err != nil {
+			log.Printf("[WARN] repair SLA worker run failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Repair SLA worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *SLAWorker) RunOnce(ctx context.Context, now time.Time) error {
+	breached, err := w.RepairRepo.MarkSLABreaches(ctx, now)
+	if err != nil {
+		return err
+	}
+	if breached > 0 {
+		log.Printf("Marked %d repair SLA breaches", breached)
+	}
+	return nil
+}
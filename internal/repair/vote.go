@@ -0,0 +1,163 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/notification"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *repairService) CastUrgencyVote(ctx context.Context, id string, req UrgencyVoteRequest, userID string) (*UrgencyResponse, error) {
+
+	if req.Level < MinUrgencyLevel || req.Level > MaxUrgencyLevel {
+		return nil, fmt.Errorf("level must be between %d and %d", MinUrgencyLevel, MaxUrgencyLevel)
+	}
+
+	objectID, repair, err := s.getRepairForVote(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.RepairRepo.SetUrgencyVote(ctx, objectID, UrgencyVote{
+		UserID:  userID,
+		Level:   req.Level,
+		VotedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	return s.afterVote(ctx, repair.OrganizationID, updated, userID), nil
+}
+
+func (s *repairService) RemoveUrgencyVote(ctx context.Context, id string, userID string) (*UrgencyResponse, error) {
+
+	objectID, repair, err := s.getRepairForVote(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myVote(repair, userID) == 0 {
+		return nil, fmt.Errorf("you have not voted on this repair")
+	}
+
+	updated, err := s.RepairRepo.RemoveUrgencyVote(ctx, objectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("repair not found")
+	}
+
+	return s.afterVote(ctx, repair.OrganizationID, updated, userID), nil
+}
+
+func (s *repairService) getRepairForVote(ctx context.Context, id string, userID string) (primitive.ObjectID, *Repair, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	repair, err := s.RepairRepo.GetRepairByID(ctx, objectID)
+	if err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	isMember := repair.ReportBy == userID || s.isOrganizationMember(ctx, userID, repair.OrganizationID)
+	if err := s.Policy.CanVoteUrgency(ctx, repair, userID, isMember); err != nil {
+		return primitive.NilObjectID, nil, err
+	}
+
+	return objectID, repair, nil
+}
+
+// afterVote moves the repair to the SLA band of its new score and escalates it when the
+// score reaches the organization's threshold. Both are best effort: the vote is already saved.
+func (s *repairService) afterVote(ctx context.Context, organizationID string, repair *Repair, userID string) *UrgencyResponse {
+
+	now := time.Now()
+
+	// Repairs reported before SLA tracking keep having no targets
+	if repair.SLABand != "" {
+		band := repair.SLABand
+		applySLA(repair, s.slaBands(ctx, organizationID), now)
+		if repair.SLABand != band {
+			if err := s.RepairRepo.UpdateRepairSLA(ctx, repair.ID, repair.UrgentVote, repair); err != nil {
+				log.Printf("[WARN] failed to update SLA of repair %s: %v", repair.ID.Hex(), err)
+			}
+		}
+	}
+
+	if repair.EscalatedAt == nil {
+		s.escalate(ctx, repair, now)
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+
+	return &UrgencyResponse{
+		RepairID:    repair.ID.Hex(),
+		Score:       repair.UrgentVote,
+		VoteCount:   len(repair.Votes),
+		MyVote:      myVote(repair, userID),
+		Escalated:   repair.EscalatedAt != nil,
+		EscalatedAt: helper.InLocation(repair.EscalatedAt, loc),
+	}
+}
+
+// escalate records the escalation once the score is high enough and tells the assignee and
+// the people the organization listed. Escalation is not undone when votes are withdrawn.
+func (s *repairService) escalate(ctx context.Context, repair *Repair, now time.Time) {
+
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, repair.OrganizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to load repair escalation settings for %s: %v", repair.OrganizationID, err)
+		return
+	}
+
+	cfg := orgSetting.RepairEscalation
+	if cfg.Disabled || repair.UrgentVote < cfg.Threshold() {
+		return
+	}
+
+	marked, err := s.RepairRepo.MarkEscalated(ctx, repair.ID, now)
+	if err != nil {
+		log.Printf("[WARN] failed to escalate repair %s: %v", repair.ID.Hex(), err)
+		return
+	}
+	if !marked {
+		// Another vote escalated it first
+		return
+	}
+	repair.EscalatedAt = &now
+
+	recipients := append([]string{}, cfg.NotifyUserIDs...)
+	if repair.AssignedTo != nil {
+		recipients = append(recipients, *repair.AssignedTo)
+	}
+	if len(recipients) == 0 || s.NotificationService == nil {
+		return
+	}
+
+	err = s.NotificationService.Notify(ctx, notification.Message{
+		UserIDs:        recipients,
+		OrganizationID: repair.OrganizationID,
+		Type:           "repair.escalated",
+		Title:          "Repair escalated",
+		Body:           fmt.Sprintf("%s reached an urgency score of %d", repair.JobName, repair.UrgentVote),
+		Data: map[string]string{
+			"repair_id": repair.ID.Hex(),
+			"score":     fmt.Sprintf("%d", repair.UrgentVote),
+		},
+	})
+	if err != nil {
+		log.Printf("[WARN] failed to send repair.escalated notification: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScanHandler struct {
+	ScanService ScanService
+}
+
+func NewScanHandler(scanService ScanService) *ScanHandler {
+	return &ScanHandler{
+		ScanService: scanService,
+	}
+}
+
+func (h *ScanHandler) Scan(c *gin.Context) {
+
+	var req ScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	payload, err := ParsePayload(req.Payload)
+	if err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.ScanService.Scan(ctx, payload, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Scan QR code successfully", data, 0)
+}
@@ -0,0 +1,45 @@
+package scan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Types of entity a SENBOX QR code can point to
+const (
+	TypeTodo     = "TODO"
+	TypeInvite   = "INVITE"
+	TypeRepair   = "REPAIR"
+	TypeLocation = "LOCATION"
+	TypeAsset    = "ASSET"
+)
+
+// ActionReportRepair is offered on a location, where the scanned spot is what needs fixing.
+const ActionReportRepair = "report_repair"
+
+// payloadPattern matches SENBOX.ORG[<TYPE>]:<id>, the format of every code the service prints.
+var payloadPattern = regexp.MustCompile(`^SENBOX\.ORG\[([A-Za-z]+)\]:(\S+)$`)
+
+// Payload is a parsed QR code: what kind of entity it names and its ID or code.
+type Payload struct {
+	Type string
+	ID   string
+}
+
+func ParsePayload(raw string) (*Payload, error) {
+
+	match := payloadPattern.FindStringSubmatch(strings.TrimSpace(raw))
+	if match == nil {
+		return nil, fmt.Errorf("not a SENBOX QR code")
+	}
+
+	payload := &Payload{Type: strings.ToUpper(match[1]), ID: match[2]}
+
+	switch payload.Type {
+	case TypeTodo, TypeInvite, TypeRepair, TypeLocation, TypeAsset:
+		return payload, nil
+	}
+
+	return nil, fmt.Errorf("unsupported QR code type %s", payload.Type)
+}
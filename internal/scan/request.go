@@ -0,0 +1,5 @@
+package scan
+
+type ScanRequest struct {
+	Payload string `json:"payload"`
+}
@@ -0,0 +1,10 @@
+package scan
+
+// ScanResponse is the entity a QR code resolved to, with what the caller can do next.
+// Entity is a todo, invite preview, repair or location depending on Type.
+type ScanResponse struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id"`
+	Entity  interface{} `json:"entity"`
+	Actions []string    `json:"actions"`
+}
@@ -0,0 +1,14 @@
+package scan
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, scanHandler *ScanHandler) {
+	scanGroup := r.Group("/api/v1/scan", middleware.Secured())
+	{
+		scanGroup.POST("", scanHandler.Scan)
+	}
+}
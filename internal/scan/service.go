@@ -0,0 +1,128 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"todo-service/internal/asset"
+	"todo-service/internal/location"
+	"todo-service/internal/repair"
+	"todo-service/internal/todo"
+)
+
+type ScanService interface {
+	Scan(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error)
+}
+
+type scanService struct {
+	TodoService     todo.TodoService
+	RepairService   repair.RepairService
+	AssetService    asset.AssetService
+	LocationService location.LocationService
+}
+
+func NewScanService(
+	TodoService todo.TodoService,
+	RepairService repair.RepairService,
+	AssetService asset.AssetService,
+	LocationService location.LocationService,
+) ScanService {
+	return &scanService{
+		TodoService:     TodoService,
+		RepairService:   RepairService,
+		AssetService:    AssetService,
+		LocationService: LocationService,
+	}
+}
+
+func (s *scanService) Scan(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error) {
+
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+
+	switch payload.Type {
+	case TypeTodo:
+		return s.scanTodo(ctx, payload, userID)
+	case TypeInvite:
+		return s.scanInvite(ctx, payload, userID)
+	case TypeRepair:
+		return s.scanRepair(ctx, payload, userID)
+	case TypeLocation:
+		return s.scanLocation(ctx, payload)
+	case TypeAsset:
+		return s.scanAsset(ctx, payload, userID)
+	}
+
+	return nil, fmt.Errorf("unsupported QR code type %s", payload.Type)
+}
+
+func (s *scanService) scanTodo(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error) {
+
+	entity, err := s.TodoService.GetTodoByID(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.TodoService.GetTodoActions(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanResponse{Type: payload.Type, ID: payload.ID, Entity: entity, Actions: actions}, nil
+}
+
+func (s *scanService) scanInvite(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error) {
+
+	entity, actions, err := s.TodoService.PreviewInvite(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanResponse{Type: payload.Type, ID: entity.Code, Entity: entity, Actions: actions}, nil
+}
+
+func (s *scanService) scanRepair(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error) {
+
+	entity, err := s.RepairService.GetRepairByID(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.RepairService.GetRepairActions(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanResponse{Type: payload.Type, ID: payload.ID, Entity: entity, Actions: actions}, nil
+}
+
+// scanAsset resolves an asset tag to the asset and its repair history.
+func (s *scanService) scanAsset(ctx context.Context, payload *Payload, userID string) (*ScanResponse, error) {
+
+	entity, err := s.AssetService.GetAssetByID(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.AssetService.GetAssetActions(ctx, payload.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanResponse{Type: payload.Type, ID: payload.ID, Entity: entity, Actions: actions}, nil
+}
+
+// scanLocation resolves a location sticker. Anyone signed in may report a repair there.
+func (s *scanService) scanLocation(ctx context.Context, payload *Payload) (*ScanResponse, error) {
+
+	entity, err := s.LocationService.GetLocationByID(ctx, payload.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entity == nil {
+		return nil, fmt.Errorf("location not found")
+	}
+
+	return &ScanResponse{Type: payload.Type, ID: payload.ID, Entity: entity, Actions: []string{ActionReportRepair}}, nil
+}
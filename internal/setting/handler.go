@@ -0,0 +1,140 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"todo-service/helper"
+	"todo-service/pkg/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingHandler struct {
+	SettingService SettingService
+}
+
+func NewSettingHandler(settingService SettingService) *SettingHandler {
+	return &SettingHandler{
+		SettingService: settingService,
+	}
+}
+
+func (h *SettingHandler) GetOrganizationSetting(c *gin.Context) {
+	organizationID := c.Param("id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.SettingService.ViewOrganizationSetting(ctx, organizationID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get organization setting successfully", data, 0)
+}
+
+func (h *SettingHandler) UpdateOrganizationSetting(c *gin.Context) {
+	organizationID := c.Param("id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req UpdateOrganizationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.SettingService.UpdateOrganizationSetting(ctx, organizationID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update organization setting successfully", nil, 0)
+}
+
+func (h *SettingHandler) GetMySetting(c *gin.Context) {
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.SettingService.GetUserSetting(ctx, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get my setting successfully", data, 0)
+}
+
+func (h *SettingHandler) UpdateMySetting(c *gin.Context) {
+	var req UpdateUserSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.SettingService.UpdateUserSetting(ctx, userID.(string), req)
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update my setting successfully", nil, 0)
+}
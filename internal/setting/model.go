@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrganizationSetting struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Timezone       string             `json:"timezone" bson:"timezone"`
+	// DisableLegacyQRJoin stops members joining todos with the permanent todo QR code,
+	// leaving invite codes as the only way in.
+	DisableLegacyQRJoin bool              `json:"disable_legacy_qr_join" bson:"disable_legacy_qr_join"`
+	OverdueEscalation   OverdueEscalation `json:"overdue_escalation" bson:"overdue_escalation"`
+	RepairEscalation    RepairEscalation  `json:"repair_escalation" bson:"repair_escalation"`
+	RepairNumberFormat  string            `json:"repair_number_format" bson:"repair_number_format"`
+	RepairTaxRate       float64           `json:"repair_tax_rate" bson:"repair_tax_rate"` // percent
+	UpdatedBy           string            `json:"updated_by" bson:"updated_by"`
+	CreatedAt           time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" bson:"updated_at"`
+}
+
+const DefaultRepairNumberFormat = "REP-{YYYY}-{N:5}"
+
+// JobNumberFormat is how repair job numbers are displayed, see helper.FormatSequence.
+func (s OrganizationSetting) JobNumberFormat() string {
+	if s.RepairNumberFormat == "" {
+		return DefaultRepairNumberFormat
+	}
+	return s.RepairNumberFormat
+}
+
+// Default escalation delays, counted from when an item was found overdue
+const (
+	DefaultOwnerEscalationHours = 24
+	DefaultAdminEscalationHours = 72
+)
+
+// OverdueEscalation configures who hears about overdue todos and tasks. Members are told
+// as soon as an item is overdue, the creator (and task leaders) after OwnerAfterHours, and
+// AdminUserIDs after AdminAfterHours. Zero hours fall back to the defaults.
+type OverdueEscalation struct {
+	Disabled        bool     `json:"disabled" bson:"disabled"`
+	OwnerAfterHours int      `json:"owner_after_hours" bson:"owner_after_hours"`
+	AdminAfterHours int      `json:"admin_after_hours" bson:"admin_after_hours"`
+	AdminUserIDs    []string `json:"admin_user_ids" bson:"admin_user_ids"`
+}
+
+func (e OverdueEscalation) OwnerDelay() time.Duration {
+	if e.OwnerAfterHours <= 0 {
+		return DefaultOwnerEscalationHours * time.Hour
+	}
+	return time.Duration(e.OwnerAfterHours) * time.Hour
+}
+
+func (e OverdueEscalation) AdminDelay() time.Duration {
+	if e.AdminAfterHours <= 0 {
+		return DefaultAdminEscalationHours * time.Hour
+	}
+	return time.Duration(e.AdminAfterHours) * time.Hour
+}
+
+// DefaultRepairEscalationScore is the urgency score at which a repair is escalated
+// when the organization has not set its own.
+const DefaultRepairEscalationScore = 10
+
+// RepairEscalation configures when crowd urgency votes escalate a repair, and who is told
+// besides the assignee.
+type RepairEscalation struct {
+	Disabled      bool     `json:"disabled" bson:"disabled"`
+	ScoreAtLeast  int      `json:"score_at_least" bson:"score_at_least"`
+	NotifyUserIDs []string `json:"notify_user_ids" bson:"notify_user_ids"`
+}
+
+func (e RepairEscalation) Threshold() int {
+	if e.ScoreAtLeast <= 0 {
+		return DefaultRepairEscalationScore
+	}
+	return e.ScoreAtLeast
+}
+
+type UserSetting struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	UserID    string             `json:"user_id" bson:"user_id"`
+	Timezone  string             `json:"timezone" bson:"timezone"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
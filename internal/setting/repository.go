@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SettingRepository interface {
+	GetOrganizationSetting(ctx context.Context, organizationID string) (*OrganizationSetting, error)
+	UpsertOrganizationSetting(ctx context.Context, setting *OrganizationSetting) error
+	GetUserSetting(ctx context.Context, userID string) (*UserSetting, error)
+	UpsertUserSetting(ctx context.Context, setting *UserSetting) error
+}
+
+type settingRepository struct {
+	organizationSettingCollection *mongo.Collection
+	userSettingCollection         *mongo.Collection
+}
+
+func NewSettingRepository(organizationSettingCollection, userSettingCollection *mongo.Collection) SettingRepository {
+	return &settingRepository{
+		organizationSettingCollection: organizationSettingCollection,
+		userSettingCollection:         userSettingCollection,
+	}
+}
+
+func (r *settingRepository) GetOrganizationSetting(ctx context.Context, organizationID string) (*OrganizationSetting, error) {
+	var setting OrganizationSetting
+	err := r.organizationSettingCollection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&setting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *settingRepository) UpsertOrganizationSetting(ctx context.Context, setting *OrganizationSetting) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.organizationSettingCollection.ReplaceOne(ctx, bson.M{"organization_id": setting.OrganizationID}, setting, opts)
+	return err
+}
+
+func (r *settingRepository) GetUserSetting(ctx context.Context, userID string) (*UserSetting, error) {
+	var setting UserSetting
+	err := r.userSettingCollection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&setting)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *settingRepository) UpsertUserSetting(ctx context.Context, setting *UserSetting) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.userSettingCollection.ReplaceOne(ctx, bson.M{"user_id": setting.UserID}, setting, opts)
+	return err
+}
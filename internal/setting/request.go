@@ -0,0 +1,28 @@
+package setting
+
+type UpdateOrganizationSettingRequest struct {
+	Timezone            *string                   `json:"timezone"`
+	DisableLegacyQRJoin *bool                     `json:"disable_legacy_qr_join"`
+	OverdueEscalation   *OverdueEscalationRequest `json:"overdue_escalation"`
+	RepairEscalation    *RepairEscalationRequest  `json:"repair_escalation"`
+	// An empty repair_number_format goes back to the default
+	RepairNumberFormat *string  `json:"repair_number_format"`
+	RepairTaxRate      *float64 `json:"repair_tax_rate"`
+}
+
+type OverdueEscalationRequest struct {
+	Disabled        *bool     `json:"disabled"`
+	OwnerAfterHours *int      `json:"owner_after_hours"`
+	AdminAfterHours *int      `json:"admin_after_hours"`
+	AdminUserIDs    *[]string `json:"admin_user_ids"`
+}
+
+type RepairEscalationRequest struct {
+	Disabled      *bool     `json:"disabled"`
+	ScoreAtLeast  *int      `json:"score_at_least"`
+	NotifyUserIDs *[]string `json:"notify_user_ids"`
+}
+
+type UpdateUserSettingRequest struct {
+	Timezone *string `json:"timezone"`
+}
@@ -0,0 +1,18 @@
+package setting
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, settingHandler *SettingHandler) {
+	settingGroup := r.Group("/api/v1/settings", middleware.Secured())
+	{
+		settingGroup.GET("/organizations/:id", settingHandler.GetOrganizationSetting)
+		settingGroup.PUT("/organizations/:id", settingHandler.UpdateOrganizationSetting)
+
+		settingGroup.GET("/me", settingHandler.GetMySetting)
+		settingGroup.PUT("/me", settingHandler.UpdateMySetting)
+	}
+}
@@ -0,0 +1,307 @@
+package setting
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/authz"
+	"todo-service/internal/user"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const DefaultTimezone = "UTC"
+
+type SettingService interface {
+	GetOrganizationSetting(ctx context.Context, organizationID string) (*OrganizationSetting, error)
+	ViewOrganizationSetting(ctx context.Context, organizationID string, userID string) (*OrganizationSetting, error)
+	UpdateOrganizationSetting(ctx context.Context, organizationID string, req UpdateOrganizationSettingRequest, userID string) error
+	GetUserSetting(ctx context.Context, userID string) (*UserSetting, error)
+	UpdateUserSetting(ctx context.Context, userID string, req UpdateUserSettingRequest) error
+
+	// Timezone resolution
+	GetOrganizationLocation(ctx context.Context, organizationID string) *time.Location
+	GetUserLocation(ctx context.Context, userID, organizationID string) *time.Location
+}
+
+type settingService struct {
+	SettingRepo SettingRepository
+	UserService user.UserService
+}
+
+func NewSettingService(settingRepo SettingRepository, userService user.UserService) SettingService {
+	return &settingService{
+		SettingRepo: settingRepo,
+		UserService: userService,
+	}
+}
+
+func (s *settingService) GetOrganizationSetting(ctx context.Context, organizationID string) (*OrganizationSetting, error) {
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	setting, err := s.SettingRepo.GetOrganizationSetting(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if setting == nil {
+		return &OrganizationSetting{
+			OrganizationID: organizationID,
+			Timezone:       DefaultTimezone,
+		}, nil
+	}
+
+	return setting, nil
+}
+
+// ViewOrganizationSetting returns the setting to the organization's members only, since it
+// names who gets escalations.
+func (s *settingService) ViewOrganizationSetting(ctx context.Context, organizationID string, userID string) (*OrganizationSetting, error) {
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization_id is required")
+	}
+
+	isMember := s.isOrganizationMember(ctx, userID, organizationID)
+	if err := authz.Authorize(ctx, authz.SettingView, userID, authz.When("being a member of the organization", isMember)); err != nil {
+		return nil, err
+	}
+
+	return s.GetOrganizationSetting(ctx, organizationID)
+}
+
+func (s *settingService) UpdateOrganizationSetting(ctx context.Context, organizationID string, req UpdateOrganizationSettingRequest, userID string) error {
+	if organizationID == "" {
+		return fmt.Errorf("organization_id is required")
+	}
+
+	if err := authz.Authorize(ctx, authz.SettingUpdate, userID); err != nil {
+		return err
+	}
+
+	setting, err := s.SettingRepo.GetOrganizationSetting(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if setting == nil {
+		setting = &OrganizationSetting{
+			ID:             primitive.NewObjectID(),
+			OrganizationID: organizationID,
+			Timezone:       DefaultTimezone,
+			CreatedAt:      now,
+		}
+	}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return fmt.Errorf("invalid timezone %q, expected an IANA name such as Asia/Ho_Chi_Minh", *req.Timezone)
+		}
+		setting.Timezone = *req.Timezone
+	}
+
+	if req.DisableLegacyQRJoin != nil {
+		setting.DisableLegacyQRJoin = *req.DisableLegacyQRJoin
+	}
+
+	if req.OverdueEscalation != nil {
+		if err := applyOverdueEscalation(&setting.OverdueEscalation, *req.OverdueEscalation); err != nil {
+			return err
+		}
+	}
+
+	if req.RepairNumberFormat != nil {
+		if *req.RepairNumberFormat != "" {
+			if err := helper.ValidateSequenceFormat(*req.RepairNumberFormat); err != nil {
+				return fmt.Errorf("invalid repair_number_format: %v", err)
+			}
+		}
+		setting.RepairNumberFormat = *req.RepairNumberFormat
+	}
+
+	if req.RepairTaxRate != nil {
+		if *req.RepairTaxRate < 0 || *req.RepairTaxRate > 100 {
+			return fmt.Errorf("repair_tax_rate must be between 0 and 100")
+		}
+		setting.RepairTaxRate = *req.RepairTaxRate
+	}
+
+	if req.RepairEscalation != nil {
+		if err := applyRepairEscalation(&setting.RepairEscalation, *req.RepairEscalation); err != nil {
+			return err
+		}
+	}
+
+	setting.UpdatedBy = userID
+	setting.UpdatedAt = now
+
+	return s.SettingRepo.UpsertOrganizationSetting(ctx, setting)
+}
+
+func (s *settingService) GetUserSetting(ctx context.Context, userID string) (*UserSetting, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+
+	setting, err := s.SettingRepo.GetUserSetting(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if setting == nil {
+		return &UserSetting{
+			UserID: userID,
+		}, nil
+	}
+
+	return setting, nil
+}
+
+func (s *settingService) UpdateUserSetting(ctx context.Context, userID string, req UpdateUserSettingRequest) error {
+	if userID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+
+	setting, err := s.SettingRepo.GetUserSetting(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if setting == nil {
+		setting = &UserSetting{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			CreatedAt: now,
+		}
+	}
+
+	if req.Timezone != nil {
+		// An empty timezone clears the override and falls back to the organization's zone.
+		if *req.Timezone != "" {
+			if _, err := time.LoadLocation(*req.Timezone); err != nil {
+				return fmt.Errorf("invalid timezone %q, expected an IANA name such as Asia/Ho_Chi_Minh", *req.Timezone)
+			}
+		}
+		setting.Timezone = *req.Timezone
+	}
+
+	setting.UpdatedAt = now
+
+	return s.SettingRepo.UpsertUserSetting(ctx, setting)
+}
+
+// GetOrganizationLocation returns the organization's configured zone, or UTC when none is set.
+func (s *settingService) GetOrganizationLocation(ctx context.Context, organizationID string) *time.Location {
+	if organizationID == "" {
+		return time.UTC
+	}
+
+	setting, err := s.SettingRepo.GetOrganizationSetting(ctx, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to get organization setting for %s: %v", organizationID, err)
+		return time.UTC
+	}
+
+	if setting == nil {
+		return time.UTC
+	}
+
+	return loadLocation(setting.Timezone)
+}
+
+// GetUserLocation returns the user's own zone, falling back to the organization's zone.
+func (s *settingService) GetUserLocation(ctx context.Context, userID, organizationID string) *time.Location {
+	if userID != "" {
+		setting, err := s.SettingRepo.GetUserSetting(ctx, userID)
+		if err != nil {
+			log.Printf("[WARN] failed to get user setting for %s: %v", userID, err)
+		} else if setting != nil && setting.Timezone != "" {
+			return loadLocation(setting.Timezone)
+		}
+	}
+
+	return s.GetOrganizationLocation(ctx, organizationID)
+}
+
+func (s *settingService) isOrganizationMember(ctx context.Context, userID, organizationID string) bool {
+	teacher, err := s.UserService.GetTeacherInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check teacher %s in organization %s: %v", userID, organizationID, err)
+	} else if teacher != nil && teacher.UserID != "" {
+		return true
+	}
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check staff %s in organization %s: %v", userID, organizationID, err)
+		return false
+	}
+	return staff != nil && staff.UserID != ""
+}
+
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("[WARN] invalid stored timezone %q: %v", name, err)
+		return time.UTC
+	}
+
+	return loc
+}
+
+func applyOverdueEscalation(escalation *OverdueEscalation, req OverdueEscalationRequest) error {
+	if req.Disabled != nil {
+		escalation.Disabled = *req.Disabled
+	}
+
+	if req.OwnerAfterHours != nil {
+		if *req.OwnerAfterHours < 0 {
+			return fmt.Errorf("owner_after_hours cannot be negative")
+		}
+		escalation.OwnerAfterHours = *req.OwnerAfterHours
+	}
+
+	if req.AdminAfterHours != nil {
+		if *req.AdminAfterHours < 0 {
+			return fmt.Errorf("admin_after_hours cannot be negative")
+		}
+		escalation.AdminAfterHours = *req.AdminAfterHours
+	}
+
+	if req.AdminUserIDs != nil {
+		escalation.AdminUserIDs = *req.AdminUserIDs
+	}
+
+	if escalation.AdminDelay() < escalation.OwnerDelay() {
+		return fmt.Errorf("admin_after_hours must not be shorter than owner_after_hours")
+	}
+
+	return nil
+}
+
+func applyRepairEscalation(escalation *RepairEscalation, req RepairEscalationRequest) error {
+	if req.Disabled != nil {
+		escalation.Disabled = *req.Disabled
+	}
+
+	if req.ScoreAtLeast != nil {
+		if *req.ScoreAtLeast < 0 {
+			return fmt.Errorf("score_at_least cannot be negative")
+		}
+		escalation.ScoreAtLeast = *req.ScoreAtLeast
+	}
+
+	if req.NotifyUserIDs != nil {
+		escalation.NotifyUserIDs = *req.NotifyUserIDs
+	}
+
+	return nil
+}
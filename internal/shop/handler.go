@@ -130,7 +130,7 @@ func (h *ShopHandler) UpdateShop(c *gin.Context) {
 
 	err := h.ShopService.UpdateShop(ctx, shopIDStr, req, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -162,7 +162,7 @@ func (h *ShopHandler) DeleteShop(c *gin.Context) {
 
 	err := h.ShopService.DeleteShop(ctx, shopIDStr, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -198,7 +198,7 @@ func (h *ShopHandler) CreateProduct(c *gin.Context) {
 
 	product, err := h.ShopService.CreateProduct(ctx, req, shopIDStr, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -228,7 +228,7 @@ func (h *ShopHandler) GetProductsByShop(c *gin.Context) {
 
 	products, err := h.ShopService.GetProductsByShop(ctx, shopIDStr, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -264,7 +264,7 @@ func (h *ShopHandler) UpdateProduct(c *gin.Context) {
 
 	err := h.ShopService.UpdateProduct(ctx, productIDStr, req, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -294,7 +294,7 @@ func (h *ShopHandler) DeleteProduct(c *gin.Context) {
 
 	err := h.ShopService.DeleteProduct(ctx, productIDStr, userID.(string))
 	if err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
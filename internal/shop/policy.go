@@ -0,0 +1,24 @@
+package shop
+
+import (
+	"context"
+	"todo-service/internal/authz"
+)
+
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+func (p *Policy) CanUpdateShop(ctx context.Context, shop *Shop, userID string) error {
+	return authz.Authorize(ctx, authz.ShopUpdate, userID, authz.Owner("being the shop owner", shop.OwnerID))
+}
+
+func (p *Policy) CanDeleteShop(ctx context.Context, shop *Shop, userID string) error {
+	return authz.Authorize(ctx, authz.ShopDelete, userID, authz.Owner("being the shop owner", shop.OwnerID))
+}
+
+func (p *Policy) CanManageProducts(ctx context.Context, shop *Shop, userID string) error {
+	return authz.Authorize(ctx, authz.ProductManage, userID, authz.Owner("being the shop owner", shop.OwnerID))
+}
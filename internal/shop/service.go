@@ -29,12 +29,14 @@ type ShopService interface {
 type shopService struct {
 	ShopRepo    ShopRepository
 	UploaderSvc uploader.ImageService
+	Policy      *Policy
 }
 
 func NewShopService(shopRepo ShopRepository, uploaderSvc uploader.ImageService) ShopService {
 	return &shopService{
 		ShopRepo:    shopRepo,
 		UploaderSvc: uploaderSvc,
+		Policy:      NewPolicy(),
 	}
 }
 
@@ -111,8 +113,8 @@ func (s *shopService) UpdateShop(ctx context.Context, shopID string, req UpdateS
 	if shop == nil {
 		return fmt.Errorf("shop not found")
 	}
-	if shop.OwnerID != ownerID {
-		return fmt.Errorf("you are not the owner of this shop")
+	if err := s.Policy.CanUpdateShop(ctx, shop, ownerID); err != nil {
+		return err
 	}
 
 	if req.Name != nil {
@@ -146,8 +148,8 @@ func (s *shopService) DeleteShop(ctx context.Context, shopID string, ownerID str
 		return fmt.Errorf("shop not found")
 	}
 
-	if shop.OwnerID != ownerID {
-		return fmt.Errorf("you are not the owner of this shop")
+	if err := s.Policy.CanDeleteShop(ctx, shop, ownerID); err != nil {
+		return err
 	}
 
 	return s.ShopRepo.DeleteShop(ctx, shopIDObj)
@@ -169,8 +171,8 @@ func (s *shopService) CreateProduct(ctx context.Context, req CreateProductReques
 		return nil, fmt.Errorf("shop not found")
 	}
 
-	if shop.OwnerID != ownerID {
-		return nil, fmt.Errorf("you are not the owner of this shop")
+	if err := s.Policy.CanManageProducts(ctx, shop, ownerID); err != nil {
+		return nil, err
 	}
 
 	id := primitive.NewObjectID()
@@ -217,8 +219,8 @@ func (s *shopService) GetProductsByShop(ctx context.Context, shopID string, owne
 		return nil, fmt.Errorf("shop not found")
 	}
 
-	if shop.OwnerID != ownerID {
-		return nil, fmt.Errorf("you are not the owner of this shop")
+	if err := s.Policy.CanManageProducts(ctx, shop, ownerID); err != nil {
+		return nil, err
 	}
 
 	return s.ShopRepo.GetProductsByShop(ctx, shopID)
@@ -237,6 +239,14 @@ func (s *shopService) UpdateProduct(ctx context.Context, productID string, req U
 		return err
 	}
 
+	if existingProduct == nil {
+		return fmt.Errorf("product not found")
+	}
+
+	if err := s.authorizeProduct(ctx, existingProduct, ownerID); err != nil {
+		return err
+	}
+
 	if req.Name != nil {
 		existingProduct.Name = *req.Name
 	}
@@ -287,9 +297,32 @@ func (s *shopService) DeleteProduct(ctx context.Context, productID string, owner
 		return fmt.Errorf("product not found")
 	}
 
+	if err := s.authorizeProduct(ctx, product, ownerID); err != nil {
+		return err
+	}
+
 	return s.ShopRepo.DeleteProduct(ctx, productIDObj)
 }
 
+// authorizeProduct checks the caller against the shop that owns the product.
+func (s *shopService) authorizeProduct(ctx context.Context, product *Product, userID string) error {
+	shopIDObj, err := primitive.ObjectIDFromHex(product.ShopID)
+	if err != nil {
+		return err
+	}
+
+	shop, err := s.ShopRepo.GetShopByID(ctx, shopIDObj)
+	if err != nil {
+		return err
+	}
+
+	if shop == nil {
+		return fmt.Errorf("shop not found")
+	}
+
+	return s.Policy.CanManageProducts(ctx, shop, userID)
+}
+
 func (s *shopService) AddRepairItem(ctx context.Context, repairID primitive.ObjectID, req AddRepairItemRequest) (*RepairItem, error) {
 	// Get product details to get current price
 	product, err := s.ShopRepo.GetProductByID(ctx, req.ProductID)
@@ -53,6 +53,12 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 	role := c.Query("role")
 	status := c.Query("status")
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -61,7 +67,7 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.TaskService.GetTasks(ctx, role, status)
+	data, err := h.TaskService.GetTasks(ctx, role, status, userID.(string))
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
@@ -77,6 +83,12 @@ func (h *TaskHandler) GetTaskById(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -85,7 +97,7 @@ func (h *TaskHandler) GetTaskById(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.TaskService.GetTaskById(ctx, id)
+	data, err := h.TaskService.GetTaskById(ctx, id, userID.(string))
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
@@ -113,11 +125,17 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TaskService.UpdateTask(ctx, req, id)
+	err := h.TaskService.UpdateTask(ctx, req, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -137,11 +155,17 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TaskService.DeleteTask(ctx, id)
+	err := h.TaskService.DeleteTask(ctx, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -201,7 +225,7 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 
 	err := h.TaskService.UpdateTaskStatus(ctx, req, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -3,6 +3,8 @@ package task
 import (
 	"context"
 	"log"
+	"time"
+	"todo-service/helper"
 	"todo-service/internal/uploader"
 	"todo-service/internal/user"
 )
@@ -12,6 +14,7 @@ func MapTaskToResponse(
 	task *Task,
 	userService user.UserService,
 	fileService uploader.ImageService,
+	loc *time.Location,
 ) *TaskResponse {
 
 	if task == nil {
@@ -95,14 +98,15 @@ func MapTaskToResponse(
 		Title:          task.Title,
 		OrganizationID: task.OrganizationID,
 		Leader:         leader,
-		StartDate:      task.StartDate,
-		DueDate:        task.DueDate,
+		StartDate:      task.StartDate.In(loc),
+		DueDate:        task.DueDate.In(loc),
 		Group:          group,
 		File:           task.File,
 		FileURL:        fileURL,
 		CreatedBy:      task.CreatedBy,
 		CreatedByInfor: createdBy,
-		CreatedAt:      task.CreatedAt,
-		UpdatedAt:      task.UpdatedAt,
+		OverdueSince:   helper.InLocation(task.OverdueSince, loc),
+		CreatedAt:      task.CreatedAt.In(loc),
+		UpdatedAt:      task.UpdatedAt.In(loc),
 	}
 }
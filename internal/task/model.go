@@ -17,6 +17,8 @@ type Task struct {
 	File           *string            `json:"file" bson:"file"`
 	FileURL        *string            `json:"file_url" bson:"file_url"`
 	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	OverdueSince   *time.Time         `json:"overdue_since" bson:"overdue_since"`
+	Escalations    []Escalation       `json:"escalations" bson:"escalations"`
 	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
 }
@@ -31,3 +33,43 @@ type Leader struct {
 	UserID string `json:"user_id" bson:"user_id"`
 	Role   string `json:"role" bson:"role"`
 }
+
+// Member statuses in Group
+const (
+	MemberStatusNotStarted  = "not_started"
+	MemberStatusProgressing = "progressing"
+	MemberStatusDone        = "done"
+)
+
+// validMemberStatus reports whether status is one of the member statuses above. Anything
+// else would never count as done and keep the task overdue.
+func validMemberStatus(status string) bool {
+	switch status {
+	case MemberStatusNotStarted, MemberStatusProgressing, MemberStatusDone:
+		return true
+	}
+	return false
+}
+
+// PendingMembers lists group members who have not finished.
+func (t *Task) PendingMembers() []string {
+	var ids []string
+	for _, member := range t.Group {
+		if member.Status != MemberStatusDone {
+			ids = append(ids, member.UserID)
+		}
+	}
+	return ids
+}
+
+// IsOverdue reports whether the task is past its due date with members not done.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return !t.DueDate.IsZero() && t.DueDate.Before(now) && len(t.PendingMembers()) > 0
+}
+
+// Escalation records an overdue notification step so the worker sends it once.
+type Escalation struct {
+	Step       string    `json:"step" bson:"step"`
+	Recipients []string  `json:"recipients" bson:"recipients"`
+	At         time.Time `json:"at" bson:"at"`
+}
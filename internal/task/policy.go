@@ -0,0 +1,39 @@
+package task
+
+import (
+	"context"
+	"todo-service/internal/authz"
+)
+
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+func leaderIDs(task *Task) []string {
+	ids := make([]string, 0, len(task.Leader))
+	for _, leader := range task.Leader {
+		ids = append(ids, leader.UserID)
+	}
+	return ids
+}
+
+func (p *Policy) CanUpdateTask(ctx context.Context, task *Task, userID string) error {
+	return authz.Authorize(ctx, authz.TaskUpdate, userID,
+		authz.Owner("being the creator", task.CreatedBy),
+		authz.MemberOf("being a task leader", leaderIDs(task)))
+}
+
+func (p *Policy) CanDeleteTask(ctx context.Context, task *Task, userID string) error {
+	return authz.Authorize(ctx, authz.TaskDelete, userID, authz.Owner("being the creator", task.CreatedBy))
+}
+
+// CanUpdateMemberStatus lets members report their own status, while the creator
+// and leaders can update anyone in the group.
+func (p *Policy) CanUpdateMemberStatus(ctx context.Context, task *Task, memberID string, userID string) error {
+	return authz.Authorize(ctx, authz.TaskUpdateStatus, userID,
+		authz.Owner("updating their own status", memberID),
+		authz.Owner("being the creator", task.CreatedBy),
+		authz.MemberOf("being a task leader", leaderIDs(task)))
+}
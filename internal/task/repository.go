@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,6 +17,12 @@ type TaskRepository interface {
 	UpdateTask(ctx context.Context, id primitive.ObjectID, task *Task) error
 	DeleteTask(ctx context.Context, id primitive.ObjectID) error
 	GetMyTask(ctx context.Context, userID string) ([]*Task, error)
+	// Overdue
+	MarkOverdue(ctx context.Context, now time.Time) (int64, error)
+	ClearResolvedOverdue(ctx context.Context, now time.Time) (int64, error)
+	ClearResolvedOverdueTask(ctx context.Context, taskID primitive.ObjectID, now time.Time) error
+	GetOverdueTasks(ctx context.Context) ([]*Task, error)
+	RecordEscalation(ctx context.Context, taskID primitive.ObjectID, overdueSince time.Time, escalation Escalation) (bool, error)
 }
 
 type taskRepository struct {
@@ -80,8 +87,25 @@ func (r *taskRepository) GetTaskById(ctx context.Context, id primitive.ObjectID)
 	return &task, nil
 }
 
+// taskAtomicFields only change through the overdue worker's targeted updates, so saving a
+// task that was read before one of those does not undo a recorded escalation step.
+var taskAtomicFields = []string{"overdue_since", "escalations"}
+
 func (r *taskRepository) UpdateTask(ctx context.Context, id primitive.ObjectID, task *Task) error {
-	_, err := r.taskCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": task})
+	data, err := bson.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	var set bson.M
+	if err := bson.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	for _, field := range taskAtomicFields {
+		delete(set, field)
+	}
+
+	_, err = r.taskCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
 	if err != nil {
 		return err
 	}
@@ -122,3 +146,104 @@ func (r *taskRepository) GetMyTask(ctx context.Context, userID string) ([]*Task,
 	}
 	return tasks, nil
 }
+
+// MarkOverdue stamps overdue_since on tasks that just passed their due date.
+func (r *taskRepository) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+
+	filter := bson.M{
+		"due_date":      bson.M{"$lt": now},
+		"overdue_since": nil,
+	}
+	for key, value := range notDoneTask {
+		filter[key] = value
+	}
+
+	update := bson.M{"$set": bson.M{"overdue_since": now, "escalations": bson.A{}}}
+
+	result, err := r.taskCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ClearResolvedOverdue resets tasks that were finished or rescheduled since they were marked.
+func (r *taskRepository) ClearResolvedOverdue(ctx context.Context, now time.Time) (int64, error) {
+	return r.clearResolvedOverdue(ctx, bson.M{}, now)
+}
+
+// ClearResolvedOverdueTask is ClearResolvedOverdue for a single task that was just saved.
+func (r *taskRepository) ClearResolvedOverdueTask(ctx context.Context, taskID primitive.ObjectID, now time.Time) error {
+	_, err := r.clearResolvedOverdue(ctx, bson.M{"_id": taskID}, now)
+	return err
+}
+
+func (r *taskRepository) clearResolvedOverdue(ctx context.Context, filter bson.M, now time.Time) (int64, error) {
+
+	filter["overdue_since"] = bson.M{"$ne": nil}
+	filter["$or"] = bson.A{
+		bson.M{"due_date": bson.M{"$gte": now}},
+		bson.M{"group": bson.M{"$not": bson.M{"$elemMatch": bson.M{"status": bson.M{"$ne": MemberStatusDone}}}}},
+	}
+
+	update := bson.M{"$set": bson.M{"overdue_since": nil, "escalations": bson.A{}}}
+
+	result, err := r.taskCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *taskRepository) GetOverdueTasks(ctx context.Context) ([]*Task, error) {
+
+	filter := bson.M{"overdue_since": bson.M{"$ne": nil}}
+	for key, value := range notDoneTask {
+		filter[key] = value
+	}
+
+	cursor, err := r.taskCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*Task
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// RecordEscalation appends the step unless it was already recorded for this overdue period,
+// and reports whether this call recorded it.
+func (r *taskRepository) RecordEscalation(ctx context.Context, id primitive.ObjectID, overdueSince time.Time, escalation Escalation) (bool, error) {
+
+	filter := bson.M{
+		"_id":              id,
+		"overdue_since":    overdueSince,
+		"escalations.step": bson.M{"$ne": escalation.Step},
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"escalations": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$escalations", bson.A{}}},
+				bson.A{bson.M{"$literal": escalation}},
+			}},
+		}}},
+	}
+
+	result, err := r.taskCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// notDoneTask matches tasks with at least one group member who has not finished
+var notDoneTask = bson.M{"group": bson.M{"$elemMatch": bson.M{"status": bson.M{"$ne": MemberStatusDone}}}}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"time"
+	"todo-service/internal/timesheet"
 	"todo-service/internal/user"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,8 +20,11 @@ type TaskResponse struct {
 	FileURL        *string            `json:"file_url" bson:"file_url"`
 	CreatedBy      string             `json:"created_by" bson:"created_by"`
 	CreatedByInfor *user.UserInfor    `json:"created_by_infor" bson:"created_by_infor"`
-	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+	// TimeSpent is only filled on the detail response
+	TimeSpent    *timesheet.TimeTotalResponse `json:"time_spent,omitempty" bson:"-"`
+	OverdueSince *time.Time                   `json:"overdue_since" bson:"overdue_since"`
+	CreatedAt    time.Time                    `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at" bson:"updated_at"`
 }
 
 type UserRoleResponse struct {
@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
+	"todo-service/helper"
+	"todo-service/internal/setting"
+	"todo-service/internal/timesheet"
 	"todo-service/internal/uploader"
 	"todo-service/internal/user"
 
@@ -13,30 +17,38 @@ import (
 
 type TaskService interface {
 	CreateTask(ctx context.Context, req CreateTaskRequest, userID string) (*string, error)
-	GetTasks(ctx context.Context, role string, status string) ([]*TaskResponse, error)
-	GetTaskById(ctx context.Context, id string) (*TaskResponse, error)
-	UpdateTask(ctx context.Context, req UpdateTaskRequest, id string) error
-	DeleteTask(ctx context.Context, id string) error
+	GetTasks(ctx context.Context, role string, status string, userID string) ([]*TaskResponse, error)
+	GetTaskById(ctx context.Context, id string, userID string) (*TaskResponse, error)
+	UpdateTask(ctx context.Context, req UpdateTaskRequest, id string, userID string) error
+	DeleteTask(ctx context.Context, id string, userID string) error
 
 	GetMyTask(ctx context.Context, userID string) ([]*TaskResponse, error)
 	UpdateTaskStatus(ctx context.Context, req []*UpdateTaskStatusRequest, id string, userID string) error
 }
 
 type taskService struct {
-	TaskRepo    TaskRepository
-	UserGateway user.UserService
-	FileGateway uploader.ImageService
+	TaskRepo       TaskRepository
+	UserGateway    user.UserService
+	FileGateway    uploader.ImageService
+	SettingService setting.SettingService
+	Timesheet      timesheet.TimesheetService
+	Policy         *Policy
 }
 
 func NewTaskService(
 	taskRepo TaskRepository,
 	userGateway user.UserService,
 	fileGateway uploader.ImageService,
+	settingService setting.SettingService,
+	timesheetService timesheet.TimesheetService,
 ) TaskService {
 	return &taskService{
-		TaskRepo:    taskRepo,
-		UserGateway: userGateway,
-		FileGateway: fileGateway,
+		TaskRepo:       taskRepo,
+		UserGateway:    userGateway,
+		FileGateway:    fileGateway,
+		SettingService: settingService,
+		Timesheet:      timesheetService,
+		Policy:         NewPolicy(),
 	}
 }
 
@@ -65,26 +77,29 @@ func (s *taskService) CreateTask(ctx context.Context, req CreateTaskRequest, use
 		return nil, fmt.Errorf("group is required")
 	}
 
-	startDate, err := time.Parse("2006-01-02 15:04:05", req.StartDate)
+	loc := s.SettingService.GetOrganizationLocation(ctx, req.OrganizationID)
+
+	startDate, err := helper.ParseDateTime(req.StartDate, loc)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start_date format, expected YYYY-MM-DD HH:MM:SS: %v", err)
+		return nil, fmt.Errorf("invalid start_date format: %v", err)
 	}
 
-	dueDate, err := time.Parse("2006-01-02 15:04:05", req.DueDate)
+	dueDate, err := helper.ParseDateTime(req.DueDate, loc)
 	if err != nil {
-		return nil, fmt.Errorf("invalid due_date format, expected YYYY-MM-DD HH:MM:SS: %v", err)
+		return nil, fmt.Errorf("invalid due_date format: %v", err)
 	}
 
 	id := primitive.NewObjectID()
 
+	// "Today" is the organization's calendar day, not the server's UTC day.
 	var status string
-	now := time.Now().Truncate(24 * time.Hour)
-	start := startDate.Truncate(24 * time.Hour)
+	now := helper.StartOfDay(time.Now(), loc)
+	start := helper.StartOfDay(startDate, loc)
 
 	if now.Before(start) {
-		status = "not_started"
+		status = MemberStatusNotStarted
 	} else {
-		status = "progressing"
+		status = MemberStatusProgressing
 	}
 
 	group := make([]UserRole, len(req.Group))
@@ -114,8 +129,8 @@ func (s *taskService) CreateTask(ctx context.Context, req CreateTaskRequest, use
 		Group:          group,
 		File:           req.File,
 		CreatedBy:      userID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
 	}
 
 	err = s.TaskRepo.CreateTask(ctx, task)
@@ -127,21 +142,27 @@ func (s *taskService) CreateTask(ctx context.Context, req CreateTaskRequest, use
 	return &idParse, nil
 }
 
-func (s *taskService) GetTasks(ctx context.Context, role string, status string) ([]*TaskResponse, error) {
+func (s *taskService) GetTasks(ctx context.Context, role string, status string, userID string) ([]*TaskResponse, error) {
 	tasks, err := s.TaskRepo.GetTasks(ctx, role, status)
 	if err != nil {
 		return nil, err
 	}
 
 	results := make([]*TaskResponse, len(tasks))
+	locations := map[string]*time.Location{}
 	for i, task := range tasks {
-		results[i] = MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway)
+		loc, ok := locations[task.OrganizationID]
+		if !ok {
+			loc = s.SettingService.GetUserLocation(ctx, userID, task.OrganizationID)
+			locations[task.OrganizationID] = loc
+		}
+		results[i] = MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway, loc)
 	}
 
 	return results, nil
 }
 
-func (s *taskService) GetTaskById(ctx context.Context, id string) (*TaskResponse, error) {
+func (s *taskService) GetTaskById(ctx context.Context, id string, userID string) (*TaskResponse, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id is required")
 	}
@@ -160,10 +181,21 @@ func (s *taskService) GetTaskById(ctx context.Context, id string) (*TaskResponse
 		return nil, fmt.Errorf("task not found")
 	}
 
-	return MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway), nil
+	loc := s.SettingService.GetUserLocation(ctx, userID, task.OrganizationID)
+
+	response := MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway, loc)
+	if response != nil && s.Timesheet != nil {
+		timeSpent, err := s.Timesheet.GetTargetTotal(ctx, timesheet.TargetTask, task.ID)
+		if err != nil {
+			log.Printf("[WARN] failed to load time spent on task %s: %v", task.ID.Hex(), err)
+		}
+		response.TimeSpent = timeSpent
+	}
+
+	return response, nil
 }
 
-func (s *taskService) UpdateTask(ctx context.Context, req UpdateTaskRequest, id string) error {
+func (s *taskService) UpdateTask(ctx context.Context, req UpdateTaskRequest, id string, userID string) error {
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
@@ -182,6 +214,10 @@ func (s *taskService) UpdateTask(ctx context.Context, req UpdateTaskRequest, id
 		return fmt.Errorf("task not found")
 	}
 
+	if err := s.Policy.CanUpdateTask(ctx, task, userID); err != nil {
+		return err
+	}
+
 	if req.Title != nil {
 		task.Title = *req.Title
 	}
@@ -204,28 +240,35 @@ func (s *taskService) UpdateTask(ctx context.Context, req UpdateTaskRequest, id
 		task.Leader = *req.Leader
 	}
 
+	loc := s.SettingService.GetOrganizationLocation(ctx, task.OrganizationID)
+
 	if req.StartDate != nil {
-		startDate, err := time.Parse("2006-01-02 15:04:05", *req.StartDate)
+		startDate, err := helper.ParseDateTime(*req.StartDate, loc)
 		if err != nil {
-			return fmt.Errorf("invalid start_date format, expected YYYY-MM-DD HH:MM:SS: %v", err)
+			return fmt.Errorf("invalid start_date format: %v", err)
 		}
 		task.StartDate = startDate
 	}
 
 	if req.DueDate != nil {
-		dueDate, err := time.Parse("2006-01-02 15:04:05", *req.DueDate)
+		dueDate, err := helper.ParseDateTime(*req.DueDate, loc)
 		if err != nil {
-			return fmt.Errorf("invalid due_date format, expected YYYY-MM-DD HH:MM:SS: %v", err)
+			return fmt.Errorf("invalid due_date format: %v", err)
 		}
 		task.DueDate = dueDate
 	}
 
-	task.UpdatedAt = time.Now()
+	task.UpdatedAt = time.Now().UTC()
 
-	return s.TaskRepo.UpdateTask(ctx, objectID, task)
+	if err := s.TaskRepo.UpdateTask(ctx, objectID, task); err != nil {
+		return err
+	}
+
+	s.clearResolvedOverdue(ctx, task, task.UpdatedAt)
+	return nil
 }
 
-func (s *taskService) DeleteTask(ctx context.Context, id string) error {
+func (s *taskService) DeleteTask(ctx context.Context, id string, userID string) error {
 	if id == "" {
 		return fmt.Errorf("id is required")
 	}
@@ -244,6 +287,10 @@ func (s *taskService) DeleteTask(ctx context.Context, id string) error {
 		return fmt.Errorf("task not found")
 	}
 
+	if err := s.Policy.CanDeleteTask(ctx, task, userID); err != nil {
+		return err
+	}
+
 	if task.File != nil {
 		err = s.FileGateway.DeletePDFKey(ctx, *task.File)
 		if err != nil {
@@ -274,8 +321,14 @@ func (s *taskService) GetMyTask(ctx context.Context, userID string) ([]*TaskResp
 	}
 
 	results := make([]*TaskResponse, len(task))
+	locations := map[string]*time.Location{}
 	for i, task := range task {
-		results[i] = MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway)
+		loc, ok := locations[task.OrganizationID]
+		if !ok {
+			loc = s.SettingService.GetUserLocation(ctx, userID, task.OrganizationID)
+			locations[task.OrganizationID] = loc
+		}
+		results[i] = MapTaskToResponse(ctx, task, s.UserGateway, s.FileGateway, loc)
 	}
 
 	return results, nil
@@ -300,8 +353,21 @@ func (s *taskService) 	UpdateTaskStatus(ctx context.Context, req []*UpdateTaskSt
 		return fmt.Errorf("task not found")
 	}
 
+	// Statuses are matched exactly by the overdue checks, so normalise them and reject
+	// anything unknown before changing a member
+	for _, groupUpdate := range req {
+		groupUpdate.Status = strings.ToLower(strings.TrimSpace(groupUpdate.Status))
+		if !validMemberStatus(groupUpdate.Status) {
+			return fmt.Errorf("status must be %s, %s or %s", MemberStatusNotStarted, MemberStatusProgressing, MemberStatusDone)
+		}
+	}
+
 	// Update status for each group item in the request
 	for _, groupUpdate := range req {
+		if err := s.Policy.CanUpdateMemberStatus(ctx, task, groupUpdate.UserID, userID); err != nil {
+			return err
+		}
+
 		found := false
 		for i, group := range task.Group {
 			if group.UserID == groupUpdate.UserID && group.Role == groupUpdate.Role {
@@ -317,5 +383,22 @@ func (s *taskService) 	UpdateTaskStatus(ctx context.Context, req []*UpdateTaskSt
 
 	task.UpdatedAt = time.Now()
 
-	return s.TaskRepo.UpdateTask(ctx, objectID, task)
+	if err := s.TaskRepo.UpdateTask(ctx, objectID, task); err != nil {
+		return err
+	}
+
+	s.clearResolvedOverdue(ctx, task, task.UpdatedAt)
+	return nil
+}
+
+// clearResolvedOverdue resets the overdue state once the saved task is no longer overdue, so
+// a later miss escalates from the start again. The save already happened, so a failure is
+// only logged; the overdue worker clears it on its next run.
+func (s *taskService) clearResolvedOverdue(ctx context.Context, task *Task, now time.Time) {
+	if task.OverdueSince == nil || task.IsOverdue(now) {
+		return
+	}
+	if err := s.TaskRepo.ClearResolvedOverdueTask(ctx, task.ID, now); err != nil {
+		log.Printf("[WARN] failed to clear the overdue state of task %s: %v", task.ID.Hex(), err)
+	}
 }
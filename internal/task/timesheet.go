@@ -0,0 +1,48 @@
+package task
+
+import (
+	"context"
+	"todo-service/internal/timesheet"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimesheetTarget lets the timesheet log time against tasks. The creator, leaders and
+// group members can log time; the creator and leaders see everyone's.
+type TimesheetTarget struct {
+	TaskRepo TaskRepository
+}
+
+func NewTimesheetTarget(taskRepo TaskRepository) *TimesheetTarget {
+	return &TimesheetTarget{
+		TaskRepo: taskRepo,
+	}
+}
+
+func (t *TimesheetTarget) ResolveTarget(ctx context.Context, id primitive.ObjectID) (*timesheet.Target, error) {
+
+	task, err := t.TaskRepo.GetTaskById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, nil
+	}
+
+	managers := append([]string{task.CreatedBy}, leaderIDs(task)...)
+
+	members := append([]string{}, managers...)
+	for _, member := range task.Group {
+		members = append(members, member.UserID)
+	}
+
+	return &timesheet.Target{
+		ID:             task.ID,
+		Type:           timesheet.TargetTask,
+		OrganizationID: task.OrganizationID,
+		Name:           task.Title,
+		Members:        members,
+		Managers:       managers,
+	}, nil
+}
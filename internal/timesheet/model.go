@@ -0,0 +1,56 @@
+package timesheet
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Targets time can be logged against
+const (
+	TargetTodo = "todo"
+	TargetTask = "task"
+)
+
+const (
+	SourceTimer  = "timer"
+	SourceManual = "manual"
+)
+
+// TimeEntry is a block of time a user spent on a todo or task. A running timer is an
+// entry without EndedAt; each user has at most one.
+type TimeEntry struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID  string             `json:"organization_id" bson:"organization_id"`
+	UserID          string             `json:"user_id" bson:"user_id"`
+	TargetType      string             `json:"target_type" bson:"target_type"`
+	TargetID        primitive.ObjectID `json:"target_id" bson:"target_id"`
+	TargetName      string             `json:"target_name" bson:"target_name"`
+	Note            string             `json:"note" bson:"note"`
+	Source          string             `json:"source" bson:"source"`
+	StartedAt       time.Time          `json:"started_at" bson:"started_at"`
+	EndedAt         *time.Time         `json:"ended_at" bson:"ended_at"`
+	DurationSeconds int64              `json:"duration_seconds" bson:"duration_seconds"`
+	Running         bool               `json:"running" bson:"running"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// EntryFilter narrows entries for listings and reports. Empty fields match everything.
+type EntryFilter struct {
+	OrganizationID string
+	UserID         string
+	TargetType     string
+	TargetID       *primitive.ObjectID
+	From           time.Time
+	To             time.Time
+	// CompletedOnly leaves out running timers
+	CompletedOnly bool
+}
+
+// UserTotal is the time one user logged on a target.
+type UserTotal struct {
+	UserID  string `bson:"_id"`
+	Seconds int64  `bson:"seconds"`
+	Entries int    `bson:"entries"`
+}
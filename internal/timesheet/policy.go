@@ -0,0 +1,31 @@
+package timesheet
+
+import (
+	"context"
+	"todo-service/internal/authz"
+)
+
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+func (p *Policy) CanLogTime(ctx context.Context, target *Target, userID string) error {
+	return authz.Authorize(ctx, authz.TimesheetManage, userID,
+		authz.MemberOf("being a member of the "+target.Type, target.Members))
+}
+
+func (p *Policy) CanEditEntry(ctx context.Context, entry *TimeEntry, userID string) error {
+	return authz.Authorize(ctx, authz.TimesheetManage, userID, authz.Owner("owning the entry", entry.UserID))
+}
+
+// CanViewOthers covers listings and reports with other people's time. Managers of a
+// todo or task can see everyone's time on it; organization-wide views need the Admin role.
+func (p *Policy) CanViewOthers(ctx context.Context, target *Target, userID string) error {
+	var rules []authz.Rule
+	if target != nil {
+		rules = append(rules, authz.MemberOf("managing the "+target.Type, target.Managers))
+	}
+	return authz.Authorize(ctx, authz.TimesheetReport, userID, rules...)
+}
@@ -0,0 +1,149 @@
+package timesheet
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"todo-service/helper"
+)
+
+// Report groupings
+const (
+	GroupByUser   = "user"
+	GroupByTarget = "target"
+	GroupByWeek   = "week"
+)
+
+// GetReport totals the stopped entries of the query, grouped per user, per todo or task,
+// or per week. Weeks start on Monday in the caller's time zone, and an entry counts
+// towards the week it started in.
+func (s *timesheetService) GetReport(ctx context.Context, query EntryQuery, userID string) (*ReportResponse, error) {
+
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = GroupByUser
+	}
+
+	if groupBy != GroupByUser && groupBy != GroupByTarget && groupBy != GroupByWeek {
+		return nil, fmt.Errorf("group_by must be user, target or week")
+	}
+
+	filter, loc, err := s.buildFilter(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter.CompletedOnly = true
+
+	entries, err := s.TimesheetRepo.GetEntries(ctx, *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := map[string]*ReportRow{}
+	var total int64
+
+	for _, entry := range entries {
+		var key, label string
+		switch groupBy {
+		case GroupByUser:
+			key = entry.UserID
+		case GroupByTarget:
+			key = entry.TargetType + ":" + entry.TargetID.Hex()
+			label = entry.TargetName
+		case GroupByWeek:
+			week := startOfWeek(entry.StartedAt, loc)
+			key = week.Format("2006-01-02")
+			label = "Week of " + key
+		}
+
+		row, ok := rows[key]
+		if !ok {
+			row = &ReportRow{Key: key, Label: label}
+			if groupBy == GroupByUser {
+				row.Label = s.userLabel(ctx, entry.UserID)
+			}
+			rows[key] = row
+		}
+
+		row.Entries++
+		row.Seconds += entry.DurationSeconds
+		total += entry.DurationSeconds
+	}
+
+	result := &ReportResponse{
+		OrganizationID: filter.OrganizationID,
+		GroupBy:        groupBy,
+		From:           filter.From.In(loc),
+		To:             filter.To.In(loc),
+		TotalSeconds:   total,
+		TotalHours:     toHours(total),
+		Rows:           make([]ReportRow, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		row.Hours = toHours(row.Seconds)
+		result.Rows = append(result.Rows, *row)
+	}
+
+	// Weeks read in date order, the other groupings with the most time first
+	sort.Slice(result.Rows, func(i, j int) bool {
+		if groupBy == GroupByWeek {
+			return result.Rows[i].Key < result.Rows[j].Key
+		}
+		if result.Rows[i].Seconds != result.Rows[j].Seconds {
+			return result.Rows[i].Seconds > result.Rows[j].Seconds
+		}
+		return result.Rows[i].Label < result.Rows[j].Label
+	})
+
+	return result, nil
+}
+
+// ExportReport renders GetReport as CSV with a closing total row.
+func (s *timesheetService) ExportReport(ctx context.Context, query EntryQuery, userID string) ([]byte, error) {
+
+	report, err := s.GetReport(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{report.GroupBy, "label", "entries", "seconds", "hours"}}
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			helper.CSVText(row.Key),
+			helper.CSVText(row.Label),
+			strconv.Itoa(row.Entries),
+			strconv.FormatInt(row.Seconds, 10),
+			strconv.FormatFloat(row.Hours, 'f', 2, 64),
+		})
+	}
+	records = append(records, []string{
+		"total",
+		fmt.Sprintf("%s to %s", report.From.Format("2006-01-02"), report.To.Format("2006-01-02")),
+		"",
+		strconv.FormatInt(report.TotalSeconds, 10),
+		strconv.FormatFloat(report.TotalHours, 'f', 2, 64),
+	})
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func startOfWeek(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	offset := (int(local.Weekday()) + 6) % 7
+	return time.Date(local.Year(), local.Month(), local.Day()-offset, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,203 @@
+package timesheet
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TimesheetRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	CreateEntry(ctx context.Context, entry *TimeEntry) error
+	GetEntryByID(ctx context.Context, id primitive.ObjectID) (*TimeEntry, error)
+	GetRunningEntry(ctx context.Context, userID string) (*TimeEntry, error)
+	StopEntry(ctx context.Context, id primitive.ObjectID, endedAt time.Time, duration int64, note *string) (*TimeEntry, error)
+	UpdateEntry(ctx context.Context, entry *TimeEntry) error
+	DeleteEntry(ctx context.Context, id primitive.ObjectID) error
+	GetEntries(ctx context.Context, filter EntryFilter) ([]*TimeEntry, error)
+	GetTotalsByTarget(ctx context.Context, targetType string, targetID primitive.ObjectID) ([]*UserTotal, error)
+}
+
+type timesheetRepository struct {
+	entryCollection *mongo.Collection
+}
+
+func NewTimesheetRepository(entryCollection *mongo.Collection) TimesheetRepository {
+	return &timesheetRepository{
+		entryCollection: entryCollection,
+	}
+}
+
+// EnsureIndexes creates the index that keeps a single running timer per user, plus the
+// ones reports and detail totals read through.
+func (r *timesheetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.entryCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("one_running_timer_per_user").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"running": true}),
+		},
+		{
+			Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "started_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *timesheetRepository) CreateEntry(ctx context.Context, entry *TimeEntry) error {
+	_, err := r.entryCollection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *timesheetRepository) GetEntryByID(ctx context.Context, id primitive.ObjectID) (*TimeEntry, error) {
+
+	var entry TimeEntry
+
+	err := r.entryCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *timesheetRepository) GetRunningEntry(ctx context.Context, userID string) (*TimeEntry, error) {
+
+	var entry TimeEntry
+
+	err := r.entryCollection.FindOne(ctx, bson.M{"user_id": userID, "running": true}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// StopEntry closes a running timer and returns it, or nil when it was already stopped.
+func (r *timesheetRepository) StopEntry(ctx context.Context, id primitive.ObjectID, endedAt time.Time, duration int64, note *string) (*TimeEntry, error) {
+
+	set := bson.M{
+		"ended_at":         endedAt,
+		"duration_seconds": duration,
+		"running":          false,
+		"updated_at":       endedAt,
+	}
+	if note != nil {
+		set["note"] = *note
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var entry TimeEntry
+	err := r.entryCollection.FindOneAndUpdate(ctx, bson.M{"_id": id, "running": true}, bson.M{"$set": set}, opts).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *timesheetRepository) UpdateEntry(ctx context.Context, entry *TimeEntry) error {
+	_, err := r.entryCollection.UpdateOne(ctx, bson.M{"_id": entry.ID}, bson.M{"$set": entry})
+	return err
+}
+
+func (r *timesheetRepository) DeleteEntry(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.entryCollection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *timesheetRepository) GetEntries(ctx context.Context, filter EntryFilter) ([]*TimeEntry, error) {
+
+	query := bson.M{}
+	if filter.OrganizationID != "" {
+		query["organization_id"] = filter.OrganizationID
+	}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if filter.TargetType != "" {
+		query["target_type"] = filter.TargetType
+	}
+	if filter.TargetID != nil {
+		query["target_id"] = *filter.TargetID
+	}
+	if filter.CompletedOnly {
+		query["running"] = false
+	}
+
+	startedAt := bson.M{}
+	if !filter.From.IsZero() {
+		startedAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		startedAt["$lt"] = filter.To
+	}
+	if len(startedAt) > 0 {
+		query["started_at"] = startedAt
+	}
+
+	opts := options.Find().SetSort(bson.M{"started_at": -1})
+
+	cursor, err := r.entryCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*TimeEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetTotalsByTarget sums the completed entries on a target per user.
+func (r *timesheetRepository) GetTotalsByTarget(ctx context.Context, targetType string, targetID primitive.ObjectID) ([]*UserTotal, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"target_type": targetType,
+			"target_id":   targetID,
+			"running":     false,
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$user_id",
+			"seconds": bson.M{"$sum": "$duration_seconds"},
+			"entries": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"seconds": -1}}},
+	}
+
+	cursor, err := r.entryCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var totals []*UserTotal
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
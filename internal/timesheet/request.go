@@ -0,0 +1,39 @@
+package timesheet
+
+type StartTimerRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Note       string `json:"note"`
+}
+
+type StopTimerRequest struct {
+	Note *string `json:"note"`
+}
+
+// CreateEntryRequest logs time after the fact. EndedAt can be replaced by DurationMinutes.
+type CreateEntryRequest struct {
+	TargetType      string `json:"target_type"`
+	TargetID        string `json:"target_id"`
+	StartedAt       string `json:"started_at"`
+	EndedAt         string `json:"ended_at"`
+	DurationMinutes int    `json:"duration_minutes"`
+	Note            string `json:"note"`
+}
+
+type UpdateEntryRequest struct {
+	StartedAt string  `json:"started_at"`
+	EndedAt   string  `json:"ended_at"`
+	Note      *string `json:"note"`
+}
+
+// EntryQuery filters listings and reports. UserID is empty for the caller's own time,
+// "all" for everyone, or another user's ID.
+type EntryQuery struct {
+	OrganizationID string
+	UserID         string
+	TargetType     string
+	TargetID       string
+	From           string
+	To             string
+	GroupBy        string
+}
@@ -0,0 +1,58 @@
+package timesheet
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TimeEntryResponse struct {
+	ID              primitive.ObjectID `json:"id"`
+	OrganizationID  string             `json:"organization_id"`
+	UserID          string             `json:"user_id"`
+	TargetType      string             `json:"target_type"`
+	TargetID        primitive.ObjectID `json:"target_id"`
+	TargetName      string             `json:"target_name"`
+	Note            string             `json:"note"`
+	Source          string             `json:"source"`
+	StartedAt       time.Time          `json:"started_at"`
+	EndedAt         *time.Time         `json:"ended_at"`
+	DurationSeconds int64              `json:"duration_seconds"`
+	Running         bool               `json:"running"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+type UserTimeResponse struct {
+	UserID  string  `json:"user_id"`
+	Seconds int64   `json:"seconds"`
+	Hours   float64 `json:"hours"`
+	Entries int     `json:"entries"`
+}
+
+// TimeTotalResponse is the logged time shown on todo and task details. Running timers
+// are not included until they are stopped.
+type TimeTotalResponse struct {
+	TotalSeconds int64              `json:"total_seconds"`
+	TotalHours   float64            `json:"total_hours"`
+	Entries      int                `json:"entries"`
+	ByUser       []UserTimeResponse `json:"by_user"`
+}
+
+type ReportRow struct {
+	Key     string  `json:"key"`
+	Label   string  `json:"label"`
+	Entries int     `json:"entries"`
+	Seconds int64   `json:"seconds"`
+	Hours   float64 `json:"hours"`
+}
+
+type ReportResponse struct {
+	OrganizationID string      `json:"organization_id"`
+	GroupBy        string      `json:"group_by"`
+	From           time.Time   `json:"from"`
+	To             time.Time   `json:"to"`
+	TotalSeconds   int64       `json:"total_seconds"`
+	TotalHours     float64     `json:"total_hours"`
+	Rows           []ReportRow `json:"rows"`
+}
@@ -0,0 +1,25 @@
+package timesheet
+
+import (
+	"todo-service/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.Engine, timesheetHandler *TimesheetHandler) {
+	timesheetGroup := r.Group("/api/v1/timesheets", middleware.Secured())
+	{
+		// Timer
+		timesheetGroup.GET("/timer", timesheetHandler.GetRunningTimer)
+		timesheetGroup.POST("/timer/start", timesheetHandler.StartTimer)
+		timesheetGroup.POST("/timer/stop", timesheetHandler.StopTimer)
+		// Entries
+		timesheetGroup.GET("/entries", timesheetHandler.GetEntries)
+		timesheetGroup.POST("/entries", timesheetHandler.CreateEntry)
+		timesheetGroup.PUT("/entries/:id", timesheetHandler.UpdateEntry)
+		timesheetGroup.DELETE("/entries/:id", timesheetHandler.DeleteEntry)
+		// Reports
+		timesheetGroup.GET("/report", timesheetHandler.GetReport)
+		timesheetGroup.GET("/report/export", timesheetHandler.ExportReport)
+	}
+}
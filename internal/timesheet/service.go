@@ -0,0 +1,501 @@
+package timesheet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"todo-service/helper"
+	"todo-service/internal/setting"
+	"todo-service/internal/user"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxEntryDuration caps a single manual entry
+	maxEntryDuration = 24 * time.Hour
+	// defaultRangeDays applies to listings and reports without from and to
+	defaultRangeDays = 30
+	maxRangeDays     = 366
+)
+
+type TimesheetService interface {
+	StartTimer(ctx context.Context, req StartTimerRequest, userID string) (*TimeEntryResponse, error)
+	StopTimer(ctx context.Context, req StopTimerRequest, userID string) (*TimeEntryResponse, error)
+	GetRunningTimer(ctx context.Context, userID string) (*TimeEntryResponse, error)
+	CreateEntry(ctx context.Context, req CreateEntryRequest, userID string) (*TimeEntryResponse, error)
+	GetEntries(ctx context.Context, query EntryQuery, userID string) ([]*TimeEntryResponse, error)
+	UpdateEntry(ctx context.Context, id string, req UpdateEntryRequest, userID string) error
+	DeleteEntry(ctx context.Context, id string, userID string) error
+	// Reports
+	GetReport(ctx context.Context, query EntryQuery, userID string) (*ReportResponse, error)
+	ExportReport(ctx context.Context, query EntryQuery, userID string) ([]byte, error)
+	GetTargetTotal(ctx context.Context, targetType string, targetID primitive.ObjectID) (*TimeTotalResponse, error)
+}
+
+type timesheetService struct {
+	TimesheetRepo  TimesheetRepository
+	UserService    user.UserService
+	SettingService setting.SettingService
+	Targets        map[string]TargetResolver
+	Policy         *Policy
+}
+
+func NewTimesheetService(timesheetRepo TimesheetRepository, userService user.UserService, settingService setting.SettingService, todoTargets, taskTargets TargetResolver) TimesheetService {
+	return &timesheetService{
+		TimesheetRepo:  timesheetRepo,
+		UserService:    userService,
+		SettingService: settingService,
+		Targets: map[string]TargetResolver{
+			TargetTodo: todoTargets,
+			TargetTask: taskTargets,
+		},
+		Policy: NewPolicy(),
+	}
+}
+
+// StartTimer starts timing the caller on a todo or task. A timer that is already running
+// is stopped first, so switching work is a single call.
+func (s *timesheetService) StartTimer(ctx context.Context, req StartTimerRequest, userID string) (*TimeEntryResponse, error) {
+
+	target, err := s.resolveTarget(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanLogTime(ctx, target, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	running, err := s.TimesheetRepo.GetRunningEntry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if running != nil {
+		if _, err := s.TimesheetRepo.StopEntry(ctx, running.ID, now, elapsedSeconds(running.StartedAt, now), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &TimeEntry{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: target.OrganizationID,
+		UserID:         userID,
+		TargetType:     target.Type,
+		TargetID:       target.ID,
+		TargetName:     target.Name,
+		Note:           req.Note,
+		Source:         SourceTimer,
+		StartedAt:      now,
+		Running:        true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.TimesheetRepo.CreateEntry(ctx, entry); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("another timer was started at the same time, try again")
+		}
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, entry.OrganizationID)
+
+	return buildEntryResponse(entry, loc), nil
+}
+
+func (s *timesheetService) StopTimer(ctx context.Context, req StopTimerRequest, userID string) (*TimeEntryResponse, error) {
+
+	running, err := s.TimesheetRepo.GetRunningEntry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if running == nil {
+		return nil, fmt.Errorf("no timer is running")
+	}
+
+	now := time.Now().UTC()
+
+	stopped, err := s.TimesheetRepo.StopEntry(ctx, running.ID, now, elapsedSeconds(running.StartedAt, now), req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	if stopped == nil {
+		return nil, fmt.Errorf("no timer is running")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, stopped.OrganizationID)
+
+	return buildEntryResponse(stopped, loc), nil
+}
+
+// GetRunningTimer returns nil when the caller has no timer running.
+func (s *timesheetService) GetRunningTimer(ctx context.Context, userID string) (*TimeEntryResponse, error) {
+
+	running, err := s.TimesheetRepo.GetRunningEntry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if running == nil {
+		return nil, nil
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, running.OrganizationID)
+
+	return buildEntryResponse(running, loc), nil
+}
+
+func (s *timesheetService) CreateEntry(ctx context.Context, req CreateEntryRequest, userID string) (*TimeEntryResponse, error) {
+
+	target, err := s.resolveTarget(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Policy.CanLogTime(ctx, target, userID); err != nil {
+		return nil, err
+	}
+
+	if req.StartedAt == "" {
+		return nil, fmt.Errorf("started_at is required")
+	}
+
+	if (req.EndedAt == "") == (req.DurationMinutes == 0) {
+		return nil, fmt.Errorf("exactly one of ended_at or duration_minutes is required")
+	}
+
+	if req.DurationMinutes < 0 {
+		return nil, fmt.Errorf("duration_minutes must be positive")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, target.OrganizationID)
+
+	startedAt, err := helper.ParseDateTime(req.StartedAt, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid started_at format: %v", err)
+	}
+
+	endedAt := startedAt.Add(time.Duration(req.DurationMinutes) * time.Minute)
+	if req.EndedAt != "" {
+		endedAt, err = helper.ParseDateTime(req.EndedAt, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ended_at format: %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := validateSpan(startedAt, endedAt, now); err != nil {
+		return nil, err
+	}
+
+	entry := &TimeEntry{
+		ID:              primitive.NewObjectID(),
+		OrganizationID:  target.OrganizationID,
+		UserID:          userID,
+		TargetType:      target.Type,
+		TargetID:        target.ID,
+		TargetName:      target.Name,
+		Note:            req.Note,
+		Source:          SourceManual,
+		StartedAt:       startedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: elapsedSeconds(startedAt, endedAt),
+		Running:         false,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.TimesheetRepo.CreateEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return buildEntryResponse(entry, loc), nil
+}
+
+func (s *timesheetService) GetEntries(ctx context.Context, query EntryQuery, userID string) ([]*TimeEntryResponse, error) {
+
+	filter, loc, err := s.buildFilter(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.TimesheetRepo.GetEntries(ctx, *filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*TimeEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, buildEntryResponse(entry, loc))
+	}
+
+	return results, nil
+}
+
+// UpdateEntry corrects a stopped entry. Running timers are changed by stopping them.
+func (s *timesheetService) UpdateEntry(ctx context.Context, id string, req UpdateEntryRequest, userID string) error {
+
+	entry, err := s.getEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if entry.Running {
+		return fmt.Errorf("stop the timer before editing it")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, entry.OrganizationID)
+
+	startedAt := entry.StartedAt
+	if req.StartedAt != "" {
+		startedAt, err = helper.ParseDateTime(req.StartedAt, loc)
+		if err != nil {
+			return fmt.Errorf("invalid started_at format: %v", err)
+		}
+	}
+
+	endedAt := *entry.EndedAt
+	if req.EndedAt != "" {
+		endedAt, err = helper.ParseDateTime(req.EndedAt, loc)
+		if err != nil {
+			return fmt.Errorf("invalid ended_at format: %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := validateSpan(startedAt, endedAt, now); err != nil {
+		return err
+	}
+
+	if req.Note != nil {
+		entry.Note = *req.Note
+	}
+
+	entry.StartedAt = startedAt
+	entry.EndedAt = &endedAt
+	entry.DurationSeconds = elapsedSeconds(startedAt, endedAt)
+	entry.UpdatedAt = now
+
+	return s.TimesheetRepo.UpdateEntry(ctx, entry)
+}
+
+func (s *timesheetService) DeleteEntry(ctx context.Context, id string, userID string) error {
+
+	entry, err := s.getEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.TimesheetRepo.DeleteEntry(ctx, entry.ID)
+}
+
+// GetTargetTotal sums the stopped entries of a todo or task for its detail response.
+func (s *timesheetService) GetTargetTotal(ctx context.Context, targetType string, targetID primitive.ObjectID) (*TimeTotalResponse, error) {
+
+	totals, err := s.TimesheetRepo.GetTotalsByTarget(ctx, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TimeTotalResponse{ByUser: []UserTimeResponse{}}
+	for _, total := range totals {
+		result.TotalSeconds += total.Seconds
+		result.Entries += total.Entries
+		result.ByUser = append(result.ByUser, UserTimeResponse{
+			UserID:  total.UserID,
+			Seconds: total.Seconds,
+			Hours:   toHours(total.Seconds),
+			Entries: total.Entries,
+		})
+	}
+	result.TotalHours = toHours(result.TotalSeconds)
+
+	return result, nil
+}
+
+func (s *timesheetService) resolveTarget(ctx context.Context, targetType, targetID string) (*Target, error) {
+
+	resolver, ok := s.Targets[targetType]
+	if !ok || resolver == nil {
+		return nil, fmt.Errorf("target_type must be todo or task")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target_id: %v", err)
+	}
+
+	target, err := resolver.ResolveTarget(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		return nil, fmt.Errorf("%s not found", targetType)
+	}
+
+	return target, nil
+}
+
+func (s *timesheetService) getEntry(ctx context.Context, id string, userID string) (*TimeEntry, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.TimesheetRepo.GetEntryByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, fmt.Errorf("time entry not found")
+	}
+
+	if err := s.Policy.CanEditEntry(ctx, entry, userID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// buildFilter turns a query into a repository filter. Without user_id the caller only
+// sees their own time; user_id=all or another user's ID needs CanViewOthers.
+func (s *timesheetService) buildFilter(ctx context.Context, query EntryQuery, userID string) (*EntryFilter, *time.Location, error) {
+
+	filter := &EntryFilter{OrganizationID: query.OrganizationID}
+
+	var target *Target
+	if query.TargetID != "" {
+		var err error
+		target, err = s.resolveTarget(ctx, query.TargetType, query.TargetID)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter.TargetType = target.Type
+		filter.TargetID = &target.ID
+		if filter.OrganizationID == "" {
+			filter.OrganizationID = target.OrganizationID
+		}
+	} else if query.TargetType != "" {
+		if _, ok := s.Targets[query.TargetType]; !ok {
+			return nil, nil, fmt.Errorf("target_type must be todo or task")
+		}
+		filter.TargetType = query.TargetType
+	}
+
+	switch query.UserID {
+	case "", userID:
+		filter.UserID = userID
+	default:
+		if filter.OrganizationID == "" {
+			return nil, nil, fmt.Errorf("organization_id is required")
+		}
+		if err := s.Policy.CanViewOthers(ctx, target, userID); err != nil {
+			return nil, nil, err
+		}
+		if query.UserID != "all" {
+			filter.UserID = query.UserID
+		}
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, filter.OrganizationID)
+
+	now := time.Now().UTC()
+	filter.To = now
+	if query.To != "" {
+		to, err := helper.ParseDateTime(query.To, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		filter.To = to
+	}
+
+	filter.From = helper.StartOfDay(filter.To.AddDate(0, 0, -defaultRangeDays), loc).UTC()
+	if query.From != "" {
+		from, err := helper.ParseDateTime(query.From, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		filter.From = from
+	}
+
+	if !filter.From.Before(filter.To) {
+		return nil, nil, fmt.Errorf("from must be before to")
+	}
+
+	if filter.To.Sub(filter.From) > maxRangeDays*24*time.Hour {
+		return nil, nil, fmt.Errorf("the range cannot be longer than %d days", maxRangeDays)
+	}
+
+	return filter, loc, nil
+}
+
+func validateSpan(startedAt, endedAt, now time.Time) error {
+	if !endedAt.After(startedAt) {
+		return fmt.Errorf("ended_at must be after started_at")
+	}
+	if endedAt.Sub(startedAt) > maxEntryDuration {
+		return fmt.Errorf("an entry cannot be longer than %d hours", int(maxEntryDuration.Hours()))
+	}
+	if endedAt.After(now.Add(time.Minute)) {
+		return fmt.Errorf("time cannot be logged in the future")
+	}
+	return nil
+}
+
+func elapsedSeconds(from, to time.Time) int64 {
+	return int64(to.Sub(from) / time.Second)
+}
+
+func toHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/36) / 100
+}
+
+func buildEntryResponse(entry *TimeEntry, loc *time.Location) *TimeEntryResponse {
+
+	duration := entry.DurationSeconds
+	if entry.Running {
+		duration = elapsedSeconds(entry.StartedAt, time.Now().UTC())
+	}
+
+	return &TimeEntryResponse{
+		ID:              entry.ID,
+		OrganizationID:  entry.OrganizationID,
+		UserID:          entry.UserID,
+		TargetType:      entry.TargetType,
+		TargetID:        entry.TargetID,
+		TargetName:      entry.TargetName,
+		Note:            entry.Note,
+		Source:          entry.Source,
+		StartedAt:       entry.StartedAt.In(loc),
+		EndedAt:         helper.InLocation(entry.EndedAt, loc),
+		DurationSeconds: duration,
+		Running:         entry.Running,
+		CreatedAt:       entry.CreatedAt.In(loc),
+		UpdatedAt:       entry.UpdatedAt.In(loc),
+	}
+}
+
+// userLabel is the nickname shown for a user in reports, falling back to the ID.
+func (s *timesheetService) userLabel(ctx context.Context, userID string) string {
+	info, err := s.UserService.GetUserInfor(ctx, userID)
+	if err != nil {
+		log.Printf("[WARN] failed to load user %s for timesheet report: %v", userID, err)
+		return userID
+	}
+	if info == nil || info.UserName == "" {
+		return userID
+	}
+	return info.UserName
+}
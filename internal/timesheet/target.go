@@ -0,0 +1,24 @@
+package timesheet
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Target is the todo or task an entry is logged against, as seen by the timesheet.
+type Target struct {
+	ID             primitive.ObjectID
+	Type           string
+	OrganizationID string
+	Name           string
+	// Members can log time on the target
+	Members []string
+	// Managers can see everyone's time on the target
+	Managers []string
+}
+
+// TargetResolver looks up targets of one type. It returns nil, nil when the target does not exist.
+type TargetResolver interface {
+	ResolveTarget(ctx context.Context, id primitive.ObjectID) (*Target, error)
+}
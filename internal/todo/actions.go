@@ -0,0 +1,161 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"todo-service/internal/authz"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions a caller can take on a todo, as offered to clients that scan its QR code
+const (
+	ActionJoin           = "join"
+	ActionRequestJoin    = "request_join"
+	ActionLeave          = "leave"
+	ActionUpdate         = "update"
+	ActionUpdateProgress = "update_progress"
+	ActionManageMembers  = "manage_members"
+	ActionViewProgress   = "view_progress"
+	ActionDelete         = "delete"
+)
+
+// GetTodoActions lists what the user may do with the todo. Joining follows the todo's join
+// policy; the rest are the policy checks of each endpoint, run without auditing.
+func (s *todoService) GetTodoActions(ctx context.Context, todoID string, userID string) ([]string, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if todo == nil {
+		return nil, fmt.Errorf("todo not found")
+	}
+
+	actions := []string{}
+
+	if isMember(todo, userID) {
+		actions = append(actions, ActionLeave)
+	} else if todo.CreatedBy != userID {
+		action, err := s.joinAction(ctx, todo, userID)
+		if err != nil {
+			return nil, err
+		}
+		if action != "" {
+			actions = append(actions, action)
+		}
+	}
+
+	probe := authz.Probe(ctx)
+	checks := []struct {
+		action string
+		err    error
+	}{
+		{ActionUpdate, s.Policy.CanUpdateTodo(probe, todo, userID, []string{FieldName})},
+		{ActionUpdateProgress, s.Policy.CanUpdateTodo(probe, todo, userID, []string{FieldProgress})},
+		{ActionManageMembers, s.Policy.CanManageMembers(probe, todo, userID)},
+		{ActionViewProgress, s.Policy.CanViewProgress(probe, todo, userID)},
+		{ActionDelete, s.Policy.CanDeleteTodo(probe, todo, userID)},
+	}
+
+	for _, check := range checks {
+		if check.err == nil {
+			actions = append(actions, check.action)
+		}
+	}
+
+	return actions, nil
+}
+
+// joinAction tells whether scanning the todo's own code lets the user in, and how. It is
+// empty when the organization turned the legacy join off, the todo is closed, or a request
+// is already waiting for review.
+func (s *todoService) joinAction(ctx context.Context, todo *Todo, userID string) (string, error) {
+
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, todo.OrganizationID)
+	if err != nil {
+		return "", err
+	}
+
+	if orgSetting.DisableLegacyQRJoin {
+		return "", nil
+	}
+
+	switch todo.EffectiveJoinPolicy() {
+	case JoinPolicyOpen:
+		return ActionJoin, nil
+	case JoinPolicyApproval:
+		pending, err := s.TodoRepo.GetPendingJoinRequest(ctx, todo.ID, userID)
+		if err != nil {
+			return "", err
+		}
+		if pending != nil {
+			return "", nil
+		}
+		return ActionRequestJoin, nil
+	}
+
+	return "", nil
+}
+
+// PreviewInvite shows what an invite code leads to without redeeming it, and whether the
+// user can join with it.
+func (s *todoService) PreviewInvite(ctx context.Context, code string, userID string) (*InvitePreviewResponse, []string, error) {
+
+	invite, err := s.TodoRepo.GetInviteByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if invite == nil {
+		return nil, nil, fmt.Errorf("invite code not found")
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, invite.TodoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if todo == nil {
+		return nil, nil, fmt.Errorf("todo not found")
+	}
+
+	now := time.Now().UTC()
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+
+	preview := &InvitePreviewResponse{
+		Code:      invite.Code,
+		Role:      invite.Role,
+		Status:    inviteStatus(invite, now),
+		ExpiresAt: invite.ExpiresAt.In(loc),
+		TodoID:    todo.ID,
+		TodoName:  todo.Name,
+	}
+
+	actions := []string{}
+	if preview.Status == InviteStatusActive &&
+		todo.EffectiveJoinPolicy() != JoinPolicyClosed &&
+		ensureCanJoin(todo, userID, invite.Role) == nil {
+		actions = append(actions, ActionJoin)
+	}
+
+	return preview, actions, nil
+}
+
+// isMember reports whether the user is on the todo in any role.
+func isMember(todo *Todo, userID string) bool {
+	for _, memberType := range memberTypes {
+		if containsString(membersOf(todo, memberType), userID) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package todo
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"todo-service/helper"
 	"todo-service/pkg/constants"
 
@@ -27,6 +29,13 @@ func (h *TodoHandler) GetTodos(c *gin.Context) {
 	student := c.Query("student")
 	staff := c.Query("staff")
 	fmt.Printf("status: %s, name: %s, teacher: %s, student: %s, staff: %s", status, name, teacher, student, staff)
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -35,7 +44,7 @@ func (h *TodoHandler) GetTodos(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.TodoService.GetAllTodo(ctx, status, name, teacher, student, staff)
+	data, err := h.TodoService.GetAllTodo(ctx, status, name, teacher, student, staff, userID.(string))
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
@@ -54,6 +63,12 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -62,7 +77,7 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	data, err := h.TodoService.GetTodoByID(ctx, id)
+	data, err := h.TodoService.GetTodoByID(ctx, id, userID.(string))
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
@@ -121,6 +136,12 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -129,9 +150,9 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TodoService.UpdateTodo(ctx, req, id)
+	err := h.TodoService.UpdateTodo(ctx, req, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -148,6 +169,12 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -156,9 +183,9 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TodoService.DeleteTodo(ctx, id)
+	err := h.TodoService.DeleteTodo(ctx, id, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -187,13 +214,18 @@ func (h *TodoHandler) JoinTodo(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TodoService.JoinTodo(ctx, req, userID.(string), false)
+	data, err := h.TodoService.JoinTodo(ctx, req, userID.(string), false)
 	if err != nil {
 		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
 		return
 	}
 
-	helper.SendSuccess(c, 200, "Join todo successfully", nil, 0)
+	if data.Status == JoinResultPending {
+		helper.SendSuccess(c, 202, "Join request sent for approval", data, 0)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Join todo successfully", data, 0)
 
 }
 
@@ -205,6 +237,12 @@ func (h TodoHandler) AddUser(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
 	token, exists := c.Get(constants.Token)
 	if !exists {
 		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
@@ -213,9 +251,9 @@ func (h TodoHandler) AddUser(c *gin.Context) {
 
 	ctx := context.WithValue(c, constants.TokenKey, token)
 
-	err := h.TodoService.AddUser(ctx, req)
+	err := h.TodoService.AddUser(ctx, req, userID.(string))
 	if err != nil {
-		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		helper.SendServiceError(c, err)
 		return
 	}
 
@@ -247,3 +285,633 @@ func (h *TodoHandler) GetMyTodo(c *gin.Context) {
 
 	helper.SendSuccess(c, 200, "Get my todo successfully", data, avg)
 }
+
+func (h *TodoHandler) AddDependency(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.AddDependency(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Add dependency successfully", nil, 0)
+}
+
+func (h *TodoHandler) RemoveDependency(c *gin.Context) {
+
+	id := c.Param("id")
+	blockerID := c.Param("blocker_id")
+	if id == "" || blockerID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and blocker_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.RemoveDependency(ctx, id, blockerID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Remove dependency successfully", nil, 0)
+}
+
+func (h *TodoHandler) GetDependencies(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.TodoService.GetDependencies(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get dependencies successfully", data, 0)
+}
+
+func (h *TodoHandler) GetQRCode(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	payload, err := h.TodoService.GetTodoQRCode(ctx, id)
+	if err != nil {
+		helper.SendError(c, 500, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendQRCode(c, payload)
+}
+
+func (h *TodoHandler) CreateInvite(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.TodoService.CreateInvite(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Create invite successfully", data, 0)
+}
+
+func (h *TodoHandler) GetInvites(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.TodoService.GetInvites(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get invites successfully", data, 0)
+}
+
+func (h *TodoHandler) RevokeInvite(c *gin.Context) {
+
+	id := c.Param("id")
+	inviteID := c.Param("invite_id")
+	if id == "" || inviteID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and invite_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.RevokeInvite(ctx, id, inviteID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Revoke invite successfully", nil, 0)
+}
+
+func (h *TodoHandler) GetInviteRedemptions(c *gin.Context) {
+
+	id := c.Param("id")
+	inviteID := c.Param("invite_id")
+	if id == "" || inviteID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and invite_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.TodoService.GetInviteRedemptions(ctx, id, inviteID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get invite redemptions successfully", data, 0)
+}
+
+func (h *TodoHandler) GetJoinRequests(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	status := c.Query("status")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	data, err := h.TodoService.GetJoinRequests(ctx, id, status, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get join requests successfully", data, 0)
+}
+
+func (h *TodoHandler) ApproveJoinRequest(c *gin.Context) {
+
+	id := c.Param("id")
+	requestID := c.Param("request_id")
+	if id == "" || requestID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and request_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.ApproveJoinRequest(ctx, id, requestID, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Approve join request successfully", nil, 0)
+}
+
+func (h *TodoHandler) RejectJoinRequest(c *gin.Context) {
+
+	id := c.Param("id")
+	requestID := c.Param("request_id")
+	if id == "" || requestID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and request_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req RejectJoinRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.RejectJoinRequest(ctx, id, requestID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Reject join request successfully", nil, 0)
+}
+
+func (h *TodoHandler) AddMembers(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req AddMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.AddMembers(ctx, id, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Add members successfully", nil, 0)
+}
+
+func (h *TodoHandler) RemoveMember(c *gin.Context) {
+
+	id := c.Param("id")
+	memberID := c.Param("user_id")
+	if id == "" || memberID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and user_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	memberType := c.Query("type")
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.RemoveMember(ctx, id, memberID, memberType, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Remove member successfully", nil, 0)
+}
+
+func (h *TodoHandler) MoveMember(c *gin.Context) {
+
+	id := c.Param("id")
+	memberID := c.Param("user_id")
+	if id == "" || memberID == "" {
+		helper.SendError(c, 400, fmt.Errorf("id and user_id are required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req MoveMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.MoveMember(ctx, id, memberID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Move member successfully", nil, 0)
+}
+
+func (h *TodoHandler) LeaveTodo(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	err := h.TodoService.LeaveTodo(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Leave todo successfully", nil, 0)
+}
+
+// UpdateMyProgress reports the caller's own progress on the todo.
+func (h *TodoHandler) UpdateMyProgress(c *gin.Context) {
+	h.updateMemberProgress(c, "")
+}
+
+// UpdateMemberProgress lets the creator or a teacher set a member's progress.
+func (h *TodoHandler) UpdateMemberProgress(c *gin.Context) {
+
+	memberID := c.Param("user_id")
+	if memberID == "" {
+		helper.SendError(c, 400, fmt.Errorf("user_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	h.updateMemberProgress(c, memberID)
+}
+
+func (h *TodoHandler) updateMemberProgress(c *gin.Context, memberID string) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req UpdateMemberProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, 400, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	if memberID == "" {
+		memberID = userID.(string)
+	}
+
+	err := h.TodoService.UpdateMemberProgress(ctx, id, memberID, req, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Update progress successfully", nil, 0)
+}
+
+func (h *TodoHandler) GetProgressBreakdown(c *gin.Context) {
+
+	id := c.Param("id")
+	if id == "" {
+		helper.SendError(c, 400, fmt.Errorf("id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	breakdown, err := h.TodoService.GetProgressBreakdown(ctx, id, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get progress breakdown successfully", breakdown, 0)
+}
+
+func (h *TodoHandler) GetTodoStats(c *gin.Context) {
+
+	organizationID := c.Query("organization_id")
+	if organizationID == "" {
+		helper.SendError(c, 400, fmt.Errorf("organization_id is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			helper.SendError(c, 400, fmt.Errorf("limit must be a number"), helper.ErrInvalidRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	userID, exists := c.Get(constants.UserID)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("user_id not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	token, exists := c.Get(constants.Token)
+	if !exists {
+		helper.SendError(c, 400, fmt.Errorf("token not found"), helper.ErrInvalidRequest)
+		return
+	}
+
+	ctx := context.WithValue(c, constants.TokenKey, token)
+
+	stats, err := h.TodoService.GetTodoStats(ctx, organizationID, c.Query("from"), c.Query("to"), limit, userID.(string))
+	if err != nil {
+		helper.SendServiceError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, 200, "Get todo stats successfully", stats, 0)
+}
@@ -7,27 +7,103 @@ import (
 )
 
 type Todo struct {
-	ID             primitive.ObjectID `json:"id" bson:"_id"`
-	Name           string             `json:"name" bson:"name"`
-	OrganizationID string             `json:"organization_id" bson:"organization_id"`
-	Description    *string            `json:"description" bson:"description"`
-	DueDate        time.Time          `json:"due_date" bson:"due_date"`
-	Urgent         bool               `json:"urgent" bson:"urgent"`
-	Link           *string            `json:"link" bson:"link"`
-	Progress       int                `json:"progress" bson:"progress"`
-	Status         string             `json:"status" bson:"status"`
-	Stage          *string            `json:"stage" bson:"stage"`
-	QRCode         string             `json:"qrcode" bson:"qrcode"`
-	Options        *string            `json:"options" bson:"options"`
-	Pictures       []string           `json:"pictures" bson:"pictures"`
-	ImageTask      string             `json:"image_task" bson:"image_task"`
-	TaskUsers      TaskUsers          `json:"task_users" bson:"task_users"`
-	Feedback       *string            `json:"feedback" bson:"feedback"`
-	CreatedBy      string             `json:"created_by" bson:"created_by"`
-	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
-	DeletedAt      *string            `json:"deleted_at" bson:"deleted_at"`
-	DeletedBy      *string            `json:"deleted_by" bson:"deleted_by"`
+	ID             primitive.ObjectID   `json:"id" bson:"_id"`
+	Name           string               `json:"name" bson:"name"`
+	OrganizationID string               `json:"organization_id" bson:"organization_id"`
+	Description    *string              `json:"description" bson:"description"`
+	DueDate        time.Time            `json:"due_date" bson:"due_date"`
+	Urgent         bool                 `json:"urgent" bson:"urgent"`
+	Link           *string              `json:"link" bson:"link"`
+	Progress       int                  `json:"progress" bson:"progress"`
+	Status         string               `json:"status" bson:"status"`
+	Stage          *string              `json:"stage" bson:"stage"`
+	Position       float64              `json:"position" bson:"position"`
+	QRCode         string               `json:"qrcode" bson:"qrcode"`
+	Options        *string              `json:"options" bson:"options"`
+	Pictures       []string             `json:"pictures" bson:"pictures"`
+	ImageTask      string               `json:"image_task" bson:"image_task"`
+	TaskUsers      TaskUsers            `json:"task_users" bson:"task_users"`
+	JoinPolicy     string               `json:"join_policy" bson:"join_policy"`
+	ProgressMode   string               `json:"progress_mode" bson:"progress_mode"`
+	MemberProgress []MemberProgress     `json:"member_progress" bson:"member_progress"`
+	BlockedBy      []primitive.ObjectID `json:"blocked_by" bson:"blocked_by"`
+	Feedback       *string              `json:"feedback" bson:"feedback"`
+	CreatedBy      string               `json:"created_by" bson:"created_by"`
+	CompletedAt    *time.Time           `json:"completed_at" bson:"completed_at"`
+	OverdueSince   *time.Time           `json:"overdue_since" bson:"overdue_since"`
+	Escalations    []Escalation         `json:"escalations" bson:"escalations"`
+	CreatedAt      time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at" bson:"updated_at"`
+	DeletedAt      *string              `json:"deleted_at" bson:"deleted_at"`
+	DeletedBy      *string              `json:"deleted_by" bson:"deleted_by"`
+}
+
+const (
+	TodoStatusPending = "pending"
+	TodoStatusDone    = "done"
+)
+
+// Join policies. Todos stored before join policies existed have an empty value and are open.
+const (
+	JoinPolicyOpen     = "open"
+	JoinPolicyApproval = "approval"
+	JoinPolicyClosed   = "closed"
+)
+
+func (t *Todo) EffectiveJoinPolicy() string {
+	if t.JoinPolicy == "" {
+		return JoinPolicyOpen
+	}
+	return t.JoinPolicy
+}
+
+// Progress modes decide how member progress rolls up into the todo's Progress.
+// Todos stored before member progress existed have an empty value and use the mean.
+const (
+	ProgressModeMean      = "mean"
+	ProgressModeDoneShare = "done_share"
+)
+
+func (t *Todo) EffectiveProgressMode() string {
+	if t.ProgressMode == "" {
+		return ProgressModeMean
+	}
+	return t.ProgressMode
+}
+
+const (
+	MemberStatusPending    = "pending"
+	MemberStatusInProgress = "in_progress"
+	MemberStatusDone       = "done"
+)
+
+// MemberProgress is one student's or staff member's own progress on a todo. Members
+// without an entry count as pending at 0%.
+type MemberProgress struct {
+	UserID      string     `json:"user_id" bson:"user_id"`
+	Progress    int        `json:"progress" bson:"progress"`
+	Status      string     `json:"status" bson:"status"`
+	Pictures    []string   `json:"pictures" bson:"pictures"`
+	SubmittedAt *time.Time `json:"submitted_at" bson:"submitted_at"`
+	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// TracksMemberProgress reports whether the todo's progress is rolled up from member entries,
+// which it is once a member has reported their own.
+func (t *Todo) TracksMemberProgress() bool {
+	return len(t.MemberProgress) > 0
+}
+
+// IsOverdue reports whether the todo is past its due date and not done.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return t.Status != TodoStatusDone && !t.DueDate.IsZero() && t.DueDate.Before(now)
+}
+
+// Escalation records an overdue notification step so the worker sends it once.
+type Escalation struct {
+	Step       string    `json:"step" bson:"step"`
+	Recipients []string  `json:"recipients" bson:"recipients"`
+	At         time.Time `json:"at" bson:"at"`
 }
 
 type TaskUsers struct {
@@ -35,3 +111,86 @@ type TaskUsers struct {
 	Students []string `json:"students" bson:"students"`
 	Staffs   []string `json:"staffs" bson:"staffs"`
 }
+
+// Member types, matching the keys of TaskUsers
+const (
+	MemberTypeTeachers = "teachers"
+	MemberTypeStudents = "students"
+	MemberTypeStaffs   = "staffs"
+)
+
+// InviteQRCodePrefix marks a QR payload that carries an invite code instead of a todo.
+const InviteQRCodePrefix = "SENBOX.ORG[INVITE]:"
+
+// TodoInvite lets people join a todo in a fixed role until it expires, runs out of uses
+// or is revoked.
+type TodoInvite struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	TodoID         primitive.ObjectID `json:"todo_id" bson:"todo_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	Code           string             `json:"code" bson:"code"`
+	Role           string             `json:"role" bson:"role"`
+	MaxUses        int                `json:"max_uses" bson:"max_uses"`
+	Uses           int                `json:"uses" bson:"uses"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt      *time.Time         `json:"revoked_at" bson:"revoked_at"`
+	RevokedBy      *string            `json:"revoked_by" bson:"revoked_by"`
+	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type InviteRedemption struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	InviteID   primitive.ObjectID `json:"invite_id" bson:"invite_id"`
+	TodoID     primitive.ObjectID `json:"todo_id" bson:"todo_id"`
+	UserID     string             `json:"user_id" bson:"user_id"`
+	Role       string             `json:"role" bson:"role"`
+	RedeemedAt time.Time          `json:"redeemed_at" bson:"redeemed_at"`
+}
+
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// JoinRequest is a pending membership on a todo with the approval join policy.
+type JoinRequest struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	TodoID         primitive.ObjectID `json:"todo_id" bson:"todo_id"`
+	OrganizationID string             `json:"organization_id" bson:"organization_id"`
+	UserID         string             `json:"user_id" bson:"user_id"`
+	Role           string             `json:"role" bson:"role"`
+	Message        string             `json:"message" bson:"message"`
+	Status         string             `json:"status" bson:"status"`
+	DecidedBy      *string            `json:"decided_by" bson:"decided_by"`
+	DecidedAt      *time.Time         `json:"decided_at" bson:"decided_at"`
+	Reason         *string            `json:"reason" bson:"reason"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// TodoStats is the aggregation result behind the organization stats endpoint.
+type TodoStats struct {
+	Total            int           `bson:"total"`
+	ByStatus         []CountBucket `bson:"by_status"`
+	ByStage          []CountBucket `bson:"by_stage"`
+	Overdue          int           `bson:"overdue"`
+	Urgent           int           `bson:"urgent"`
+	Completed        int           `bson:"completed"`
+	CompletedOnTime  int           `bson:"completed_on_time"`
+	AverageCycleTime float64       `bson:"average_cycle_ms"`
+	Leaderboard      []MemberStats `bson:"leaderboard"`
+}
+
+type CountBucket struct {
+	Key   *string `bson:"_id"`
+	Count int     `bson:"count"`
+}
+
+type MemberStats struct {
+	UserID          string  `bson:"_id"`
+	Assigned        int     `bson:"assigned"`
+	Completed       int     `bson:"completed"`
+	CompletedOnTime int     `bson:"completed_on_time"`
+	AverageProgress float64 `bson:"average_progress"`
+}
@@ -0,0 +1,173 @@
+package todo
+
+import (
+	"context"
+	"time"
+	"todo-service/internal/authz"
+)
+
+// Fields a caller can touch through UpdateTodo
+const (
+	FieldName         = "name"
+	FieldDescription  = "description"
+	FieldDueDate      = "due_date"
+	FieldUrgent       = "urgent"
+	FieldStatus       = "status"
+	FieldLink         = "link"
+	FieldStage        = "stage"
+	FieldOptions      = "options"
+	FieldFeedback     = "feedback"
+	FieldImageTask    = "image_task"
+	FieldPictures     = "pictures"
+	FieldProgress     = "progress"
+	FieldJoinPolicy   = "join_policy"
+	FieldProgressMode = "progress_mode"
+	FieldBlockedBy    = "blocked_by"
+)
+
+// fieldActions maps the fields staff and students may touch to their own
+// actions. Every other field falls under authz.TodoUpdate.
+var fieldActions = map[string]authz.Action{
+	FieldStage:    authz.TodoUpdateStage,
+	FieldProgress: authz.TodoUpdateProgress,
+	FieldPictures: authz.TodoUpdatePictures,
+}
+
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// CanUpdateTodo checks the fields a caller is changing against their relation to the todo.
+// The creator and the todo's teachers can edit everything, staff can move the stage and
+// progress, and students can only report progress and pictures.
+func (p *Policy) CanUpdateTodo(ctx context.Context, todo *Todo, userID string, fields []string) error {
+	checked := map[authz.Action]bool{}
+
+	for _, field := range fields {
+		action, ok := fieldActions[field]
+		if !ok {
+			action = authz.TodoUpdate
+		}
+		if checked[action] {
+			continue
+		}
+		checked[action] = true
+
+		rules := []authz.Rule{
+			authz.Owner("being the creator", todo.CreatedBy),
+			authz.MemberOf("being a teacher on the todo", todo.TaskUsers.Teachers),
+		}
+		switch action {
+		case authz.TodoUpdateStage:
+			rules = append(rules, authz.MemberOf("being staff on the todo", todo.TaskUsers.Staffs))
+		case authz.TodoUpdateProgress:
+			rules = append(rules,
+				authz.MemberOf("being staff on the todo", todo.TaskUsers.Staffs),
+				authz.MemberOf("being a student on the todo", todo.TaskUsers.Students))
+		case authz.TodoUpdatePictures:
+			rules = append(rules, authz.MemberOf("being a student on the todo", todo.TaskUsers.Students))
+		}
+
+		if err := authz.Authorize(ctx, action, userID, rules...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Policy) CanDeleteTodo(ctx context.Context, todo *Todo, userID string) error {
+	return authz.Authorize(ctx, authz.TodoDelete, userID, authz.Owner("being the creator", todo.CreatedBy))
+}
+
+// CanManageMembers covers invites and membership changes, which stay with the creator
+// and the todo's teachers.
+func (p *Policy) CanManageMembers(ctx context.Context, todo *Todo, userID string) error {
+	return authz.Authorize(ctx, authz.TodoManageMembers, userID,
+		authz.Owner("being the creator", todo.CreatedBy),
+		authz.MemberOf("being a teacher on the todo", todo.TaskUsers.Teachers))
+}
+
+// CanUpdateMemberProgress lets members report their own progress, and the creator and
+// teachers correct anyone's.
+func (p *Policy) CanUpdateMemberProgress(ctx context.Context, todo *Todo, userID, memberID string) error {
+	return authz.Authorize(ctx, authz.TodoUpdateProgress, userID,
+		authz.Owner("reporting their own progress", memberID),
+		authz.Owner("being the creator", todo.CreatedBy),
+		authz.MemberOf("being a teacher on the todo", todo.TaskUsers.Teachers))
+}
+
+func (p *Policy) CanViewProgress(ctx context.Context, todo *Todo, userID string) error {
+	return authz.Authorize(ctx, authz.TodoViewProgress, userID,
+		authz.Owner("being the creator", todo.CreatedBy),
+		authz.MemberOf("being a teacher on the todo", todo.TaskUsers.Teachers))
+}
+
+// CanViewStats allows organization-wide numbers for the organization's teachers and staff.
+func (p *Policy) CanViewStats(ctx context.Context, userID string, inOrganization bool) error {
+	return authz.Authorize(ctx, authz.TodoViewStats, userID,
+		authz.When("being a teacher or staff of the organization", inOrganization))
+}
+
+// changedFields lists the fields of req that differ from the stored todo. Clients often
+// send the whole object back, so unchanged values do not count against the caller.
+func changedFields(existing *Todo, req UpdateTaskProgressRequest, dueDate time.Time) []string {
+	var fields []string
+
+	if req.Name != "" && req.Name != existing.Name {
+		fields = append(fields, FieldName)
+	}
+	if req.Description != nil && !equalStringPtr(req.Description, existing.Description) {
+		fields = append(fields, FieldDescription)
+	}
+	if !dueDate.Equal(existing.DueDate) {
+		fields = append(fields, FieldDueDate)
+	}
+	if req.Urgent != nil && *req.Urgent != existing.Urgent {
+		fields = append(fields, FieldUrgent)
+	}
+	if req.Status != "" && req.Status != existing.Status {
+		fields = append(fields, FieldStatus)
+	}
+	if req.Link != nil && !equalStringPtr(req.Link, existing.Link) {
+		fields = append(fields, FieldLink)
+	}
+	if req.Stage != nil && !equalStringPtr(req.Stage, existing.Stage) {
+		fields = append(fields, FieldStage)
+	}
+	if req.Options != nil && !equalStringPtr(req.Options, existing.Options) {
+		fields = append(fields, FieldOptions)
+	}
+	if req.Feedback != nil && !equalStringPtr(req.Feedback, existing.Feedback) {
+		fields = append(fields, FieldFeedback)
+	}
+	if req.ImageTask != "" && req.ImageTask != existing.ImageTask {
+		fields = append(fields, FieldImageTask)
+	}
+	for _, picture := range req.Pictures {
+		if picture != "" {
+			fields = append(fields, FieldPictures)
+			break
+		}
+	}
+	if req.Progress != nil && *req.Progress != existing.Progress {
+		fields = append(fields, FieldProgress)
+	}
+	if req.JoinPolicy != nil && *req.JoinPolicy != existing.EffectiveJoinPolicy() {
+		fields = append(fields, FieldJoinPolicy)
+	}
+	if req.ProgressMode != nil && *req.ProgressMode != existing.EffectiveProgressMode() {
+		fields = append(fields, FieldProgressMode)
+	}
+
+	return fields
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -3,10 +3,12 @@ package todo
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TodoRepository interface {
@@ -19,16 +21,56 @@ type TodoRepository interface {
 	GetTodoByQRCode(ctx context.Context, qrCode string) (*Todo, error)
 	JoinTodo(ctx context.Context, todoID primitive.ObjectID, userID, typeUser string, isCreator bool) error
 	AddUsers(ctx context.Context, todoID primitive.ObjectID, userArray []string, typeUser string) error
+	RemoveMember(ctx context.Context, todoID primitive.ObjectID, userID string, typeUsers []string) (bool, error)
+	MoveMember(ctx context.Context, todoID primitive.ObjectID, userID, from, to string) (bool, error)
 	GetMyTodo(ctx context.Context, userID string) ([]*Todo, error)
+	// Member progress
+	SaveMemberProgress(ctx context.Context, todoID primitive.ObjectID, entry MemberProgress) error
+	RecomputeProgress(ctx context.Context, todoID primitive.ObjectID, resolvedBlockers []primitive.ObjectID) error
+	// Overdue
+	MarkOverdue(ctx context.Context, now time.Time) (int64, error)
+	ClearResolvedOverdue(ctx context.Context, now time.Time) (int64, error)
+	ClearResolvedOverdueTodo(ctx context.Context, todoID primitive.ObjectID, now time.Time) error
+	GetOverdueTodos(ctx context.Context) ([]*Todo, error)
+	RecordEscalation(ctx context.Context, todoID primitive.ObjectID, overdueSince time.Time, escalation Escalation) (bool, error)
+	// Stats
+	GetTodoStats(ctx context.Context, organizationID string, from, to, now time.Time, leaderboardLimit int) (*TodoStats, error)
+	// Dependencies
+	GetTodosByIDs(ctx context.Context, todoIDs []primitive.ObjectID) ([]*Todo, error)
+	GetBlockedTodos(ctx context.Context, blockerID primitive.ObjectID) ([]*Todo, error)
+	AddDependency(ctx context.Context, todoID, blockerID primitive.ObjectID) (bool, error)
+	RemoveDependency(ctx context.Context, todoID, blockerID primitive.ObjectID) error
+	// Invites
+	CreateInvite(ctx context.Context, invite *TodoInvite) error
+	GetInviteByID(ctx context.Context, inviteID primitive.ObjectID) (*TodoInvite, error)
+	GetInviteByCode(ctx context.Context, code string) (*TodoInvite, error)
+	GetInvitesByTodo(ctx context.Context, todoID primitive.ObjectID) ([]*TodoInvite, error)
+	ClaimInvite(ctx context.Context, code string, now time.Time) (*TodoInvite, error)
+	ReleaseInvite(ctx context.Context, inviteID primitive.ObjectID) error
+	RevokeInvite(ctx context.Context, inviteID primitive.ObjectID, userID string, now time.Time) error
+	CreateRedemption(ctx context.Context, redemption *InviteRedemption) error
+	GetRedemptionsByInvite(ctx context.Context, inviteID primitive.ObjectID) ([]*InviteRedemption, error)
+	// Join requests
+	CreateJoinRequest(ctx context.Context, request *JoinRequest) error
+	GetJoinRequestByID(ctx context.Context, requestID primitive.ObjectID) (*JoinRequest, error)
+	GetPendingJoinRequest(ctx context.Context, todoID primitive.ObjectID, userID string) (*JoinRequest, error)
+	GetJoinRequestsByTodo(ctx context.Context, todoID primitive.ObjectID, status string) ([]*JoinRequest, error)
+	DecideJoinRequest(ctx context.Context, requestID primitive.ObjectID, status, decidedBy string, reason *string, now time.Time) (bool, error)
 }
 
 type todoRepository struct {
-	todoCollection *mongo.Collection
+	todoCollection        *mongo.Collection
+	inviteCollection      *mongo.Collection
+	redemptionCollection  *mongo.Collection
+	joinRequestCollection *mongo.Collection
 }
 
-func NewTodoRepository(todoCollection *mongo.Collection) TodoRepository {
+func NewTodoRepository(todoCollection, inviteCollection, redemptionCollection, joinRequestCollection *mongo.Collection) TodoRepository {
 	return &todoRepository{
-		todoCollection: todoCollection,
+		todoCollection:        todoCollection,
+		inviteCollection:      inviteCollection,
+		redemptionCollection:  redemptionCollection,
+		joinRequestCollection: joinRequestCollection,
 	}
 }
 
@@ -108,13 +150,37 @@ func (r *todoRepository) CreateTodo(ctx context.Context, todo *Todo) (*string, e
 	return &id, nil
 }
 
+// todoAtomicFields only change through their own targeted updates (members, member progress,
+// dependencies, overdue tracking, board moves), so saving a todo that was read before one of
+// those does not undo it.
+var todoAtomicFields = []string{"task_users", "member_progress", "blocked_by", "overdue_since", "escalations", "stage", "position"}
+
 func (r *todoRepository) UpdateTodo(ctx context.Context, todo *Todo) error {
-	_, err := r.todoCollection.UpdateOne(ctx, bson.M{"_id": todo.ID}, bson.M{"$set": todo})
+	data, err := bson.Marshal(todo)
+	if err != nil {
+		return err
+	}
+
+	var set bson.M
+	if err := bson.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	for _, field := range todoAtomicFields {
+		delete(set, field)
+	}
+
+	_, err = r.todoCollection.UpdateOne(ctx, bson.M{"_id": todo.ID}, bson.M{"$set": set})
 	return err
 }
 
 func (r *todoRepository) DeleteTodo(ctx context.Context, todoID primitive.ObjectID) error {
 	_, err := r.todoCollection.DeleteOne(ctx, bson.M{"_id": todoID})
+	if err != nil {
+		return err
+	}
+
+	// Drop links from todos that were waiting on the deleted one
+	_, err = r.todoCollection.UpdateMany(ctx, bson.M{"blocked_by": todoID}, bson.M{"$pull": bson.M{"blocked_by": todoID}})
 	return err
 }
 
@@ -168,28 +234,427 @@ func (r *todoRepository) AddUsers(ctx context.Context, todoID primitive.ObjectID
 		"_id": todoID,
 	}
 
-	var field string
-	switch typeUser {
-	case "students":
-		field = "task_users.students"
-	case "teachers":
-		field = "task_users.teachers"
-	case "staffs":
-		field = "task_users.staffs"
-	default:
-		return fmt.Errorf("type user not found")
+	field, err := memberField(typeUser)
+	if err != nil {
+		return err
 	}
 
+	// $addToSet keeps existing members; $set used to replace the whole list
 	update := bson.M{
-		"$set": bson.M{
-			field: userArray,
+		"$addToSet": bson.M{
+			field: bson.M{"$each": userArray},
 		},
 	}
 
-	_, err := r.todoCollection.UpdateOne(ctx, filter, update)
+	_, err = r.todoCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RemoveMember pulls the user from each of the given member lists and reports
+// whether they were on any of them.
+func (r *todoRepository) RemoveMember(ctx context.Context, todoID primitive.ObjectID, userID string, typeUsers []string) (bool, error) {
+
+	pull := bson.M{}
+	for _, typeUser := range typeUsers {
+		field, err := memberField(typeUser)
+		if err != nil {
+			return false, err
+		}
+		pull[field] = userID
+	}
+
+	result, err := r.todoCollection.UpdateOne(ctx, bson.M{"_id": todoID}, bson.M{"$pull": pull})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// MoveMember switches a member's list in one update. It reports false when the
+// user was not in the from list.
+func (r *todoRepository) MoveMember(ctx context.Context, todoID primitive.ObjectID, userID, from, to string) (bool, error) {
+
+	fromField, err := memberField(from)
+	if err != nil {
+		return false, err
+	}
+
+	toField, err := memberField(to)
+	if err != nil {
+		return false, err
+	}
+
+	update := bson.M{
+		"$pull":     bson.M{fromField: userID},
+		"$addToSet": bson.M{toField: userID},
+	}
+
+	result, err := r.todoCollection.UpdateOne(ctx, bson.M{"_id": todoID, fromField: userID}, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func memberField(typeUser string) (string, error) {
+	switch typeUser {
+	case MemberTypeStudents:
+		return "task_users.students", nil
+	case MemberTypeTeachers:
+		return "task_users.teachers", nil
+	case MemberTypeStaffs:
+		return "task_users.staffs", nil
+	default:
+		return "", fmt.Errorf("type user not found")
+	}
+}
+
+// SaveMemberProgress replaces the member's progress entry, adding it on first report.
+func (r *todoRepository) SaveMemberProgress(ctx context.Context, todoID primitive.ObjectID, entry MemberProgress) error {
+
+	// Two tries cover a concurrent first report winning the $push
+	for attempt := 0; attempt < 2; attempt++ {
+		result, err := r.todoCollection.UpdateOne(ctx,
+			bson.M{"_id": todoID, "member_progress.user_id": entry.UserID},
+			bson.M{"$set": bson.M{"member_progress.$": entry}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+
+		result, err = r.todoCollection.UpdateOne(ctx,
+			bson.M{"_id": todoID, "member_progress.user_id": bson.M{"$ne": entry.UserID}},
+			bson.M{"$push": bson.M{"member_progress": entry}})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("todo not found")
+}
+
+// RecomputeProgress rolls the member entries of current students and staff up into the
+// todo's progress and status in a single pipeline update, so concurrent reports cannot
+// overwrite each other's aggregate. Todos without member entries keep their shared progress.
+// The todo only becomes done while every blocker it has is among resolvedBlockers.
+func (r *todoRepository) RecomputeProgress(ctx context.Context, todoID primitive.ObjectID, resolvedBlockers []primitive.ObjectID) error {
+
+	if resolvedBlockers == nil {
+		resolvedBlockers = []primitive.ObjectID{}
+	}
+
+	participants := bson.M{"$setUnion": bson.A{
+		bson.M{"$ifNull": bson.A{"$task_users.students", bson.A{}}},
+		bson.M{"$ifNull": bson.A{"$task_users.staffs", bson.A{}}},
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"_participants": participants,
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"_entries": bson.M{"$filter": bson.M{
+				"input": "$member_progress",
+				"as":    "m",
+				"cond":  bson.M{"$in": bson.A{"$$m.user_id", "$_participants"}},
+			}},
+			"_total": bson.M{"$max": bson.A{1, bson.M{"$size": "$_participants"}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"_done": bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": "$_entries",
+				"as":    "m",
+				"cond":  bson.M{"$eq": bson.A{"$$m.status", MemberStatusDone}},
+			}}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"progress": bson.M{"$toInt": bson.M{"$round": bson.A{
+				bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$progress_mode", ProgressModeDoneShare}},
+					bson.M{"$divide": bson.A{bson.M{"$multiply": bson.A{"$_done", 100}}, "$_total"}},
+					bson.M{"$divide": bson.A{bson.M{"$sum": "$_entries.progress"}, "$_total"}},
+				}},
+				0,
+			}}},
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$gt": bson.A{bson.M{"$size": "$_participants"}, 0}},
+					bson.M{"$eq": bson.A{"$_done", bson.M{"$size": "$_participants"}}},
+					bson.M{"$setIsSubset": bson.A{bson.M{"$ifNull": bson.A{"$blocked_by", bson.A{}}}, resolvedBlockers}},
+				}},
+				TodoStatusDone,
+				bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$status", TodoStatusDone}},
+					TodoStatusPending,
+					"$status",
+				}},
+			}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"completed_at": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$status", TodoStatusDone}},
+				bson.M{"$ifNull": bson.A{"$completed_at", "$$NOW"}},
+				nil,
+			}},
+		}}},
+		{{Key: "$unset", Value: bson.A{"_participants", "_entries", "_total", "_done"}}},
+	}
+
+	filter := bson.M{
+		"_id":               todoID,
+		"member_progress.0": bson.M{"$exists": true},
+	}
+
+	_, err := r.todoCollection.UpdateOne(ctx, filter, pipeline)
 	return err
 }
 
+// MarkOverdue stamps overdue_since on todos that just passed their due date.
+func (r *todoRepository) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+
+	filter := bson.M{
+		"due_date":      bson.M{"$lt": now},
+		"overdue_since": nil,
+	}
+	for key, value := range notDoneTodo {
+		filter[key] = value
+	}
+
+	update := bson.M{"$set": bson.M{"overdue_since": now, "escalations": bson.A{}}}
+
+	result, err := r.todoCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ClearResolvedOverdue resets todos that were finished or rescheduled since they were marked.
+func (r *todoRepository) ClearResolvedOverdue(ctx context.Context, now time.Time) (int64, error) {
+	return r.clearResolvedOverdue(ctx, bson.M{}, now)
+}
+
+// ClearResolvedOverdueTodo resets one todo right after it was finished or rescheduled,
+// instead of waiting for the worker.
+func (r *todoRepository) ClearResolvedOverdueTodo(ctx context.Context, todoID primitive.ObjectID, now time.Time) error {
+	_, err := r.clearResolvedOverdue(ctx, bson.M{"_id": todoID}, now)
+	return err
+}
+
+func (r *todoRepository) clearResolvedOverdue(ctx context.Context, filter bson.M, now time.Time) (int64, error) {
+
+	filter["overdue_since"] = bson.M{"$ne": nil}
+	filter["$or"] = bson.A{
+		bson.M{"due_date": bson.M{"$gte": now}},
+		bson.M{"status": TodoStatusDone},
+	}
+
+	update := bson.M{"$set": bson.M{"overdue_since": nil, "escalations": bson.A{}}}
+
+	result, err := r.todoCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *todoRepository) GetOverdueTodos(ctx context.Context) ([]*Todo, error) {
+
+	filter := bson.M{"overdue_since": bson.M{"$ne": nil}}
+	for key, value := range notDoneTodo {
+		filter[key] = value
+	}
+
+	cursor, err := r.todoCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*Todo
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// RecordEscalation appends the step unless it was already recorded for this overdue period,
+// and reports whether this call recorded it.
+func (r *todoRepository) RecordEscalation(ctx context.Context, id primitive.ObjectID, overdueSince time.Time, escalation Escalation) (bool, error) {
+
+	filter := bson.M{
+		"_id":              id,
+		"overdue_since":    overdueSince,
+		"escalations.step": bson.M{"$ne": escalation.Step},
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"escalations": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$escalations", bson.A{}}},
+				bson.A{bson.M{"$literal": escalation}},
+			}},
+		}}},
+	}
+
+	result, err := r.todoCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// GetTodoStats aggregates the organization's todos created in [from, to) in one $facet
+// pass. Todos finished before completed_at was recorded use updated_at as their finish time.
+func (r *todoRepository) GetTodoStats(ctx context.Context, organizationID string, from, to, now time.Time, leaderboardLimit int) (*TodoStats, error) {
+
+	finishedAt := bson.M{"$ifNull": bson.A{"$completed_at", "$updated_at"}}
+	isDone := bson.M{"$eq": bson.A{"$status", TodoStatusDone}}
+
+	count := func(match bson.M) bson.A {
+		return bson.A{
+			bson.M{"$match": match},
+			bson.M{"$count": "count"},
+		}
+	}
+
+	groupCount := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+
+	// A member with their own progress entry is scored on it, others on the todo itself
+	leaderboard := bson.A{
+		bson.M{"$project": bson.M{
+			"status":       1,
+			"progress":     1,
+			"due_date":     1,
+			"_finished_at": 1,
+			"_member": bson.M{"$setUnion": bson.A{
+				bson.M{"$ifNull": bson.A{"$task_users.students", bson.A{}}},
+				bson.M{"$ifNull": bson.A{"$task_users.staffs", bson.A{}}},
+			}},
+			"member_progress": 1,
+		}},
+		bson.M{"$unwind": "$_member"},
+		bson.M{"$set": bson.M{
+			"_entry": bson.M{"$arrayElemAt": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$member_progress", bson.A{}}},
+					"as":    "m",
+					"cond":  bson.M{"$eq": bson.A{"$$m.user_id", "$_member"}},
+				}},
+				0,
+			}},
+		}},
+		bson.M{"$set": bson.M{
+			"_has_entry": bson.M{"$eq": bson.A{bson.M{"$type": "$_entry"}, "object"}},
+		}},
+		bson.M{"$set": bson.M{
+			"_done": bson.M{"$cond": bson.A{
+				"$_has_entry",
+				bson.M{"$eq": bson.A{"$_entry.status", MemberStatusDone}},
+				isDone,
+			}},
+			"_member_finished_at": bson.M{"$cond": bson.A{"$_has_entry", "$_entry.submitted_at", "$_finished_at"}},
+			"_progress":           bson.M{"$cond": bson.A{"$_has_entry", "$_entry.progress", "$progress"}},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":       "$_member",
+			"assigned":  bson.M{"$sum": 1},
+			"completed": bson.M{"$sum": bson.M{"$cond": bson.A{"$_done", 1, 0}}},
+			"completed_on_time": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{"$_done", bson.M{"$lte": bson.A{"$_member_finished_at", "$due_date"}}}},
+				1,
+				0,
+			}}},
+			"average_progress": bson.M{"$avg": "$_progress"},
+		}},
+		bson.M{"$sort": bson.D{
+			{Key: "completed", Value: -1},
+			{Key: "completed_on_time", Value: -1},
+			{Key: "average_progress", Value: -1},
+			{Key: "_id", Value: 1},
+		}},
+		bson.M{"$limit": leaderboardLimit},
+	}
+
+	firstOr0 := func(path string) bson.M {
+		return bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{path, 0}}, 0}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"organization_id": organizationID,
+			"created_at":      bson.M{"$gte": from, "$lt": to},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"_finished_at": bson.M{"$cond": bson.A{isDone, finishedAt, nil}},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"total":     bson.A{bson.M{"$count": "count"}},
+			"by_status": groupCount("$status"),
+			"by_stage":  groupCount("$stage"),
+			"overdue": count(bson.M{
+				"status":   bson.M{"$ne": TodoStatusDone},
+				"due_date": bson.M{"$lt": now},
+			}),
+			"urgent": count(bson.M{"urgent": true}),
+			"completion": bson.A{
+				bson.M{"$match": bson.M{"status": TodoStatusDone}},
+				bson.M{"$group": bson.M{
+					"_id":       nil,
+					"completed": bson.M{"$sum": 1},
+					"completed_on_time": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$lte": bson.A{"$_finished_at", "$due_date"}}, 1, 0,
+					}}},
+					"average_cycle_ms": bson.M{"$avg": bson.M{"$subtract": bson.A{"$_finished_at", "$created_at"}}},
+				}},
+			},
+			"leaderboard": leaderboard,
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"total":             firstOr0("$total.count"),
+			"by_status":         1,
+			"by_stage":          1,
+			"overdue":           firstOr0("$overdue.count"),
+			"urgent":            firstOr0("$urgent.count"),
+			"completed":         firstOr0("$completion.completed"),
+			"completed_on_time": firstOr0("$completion.completed_on_time"),
+			"average_cycle_ms":  firstOr0("$completion.average_cycle_ms"),
+			"leaderboard":       1,
+		}}},
+	}
+
+	cursor, err := r.todoCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stats := &TodoStats{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(stats); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, cursor.Err()
+}
+
 func (r *todoRepository) GetMyTodo(ctx context.Context, userID string) ([]*Todo, error) {
 
 	var todos []*Todo
@@ -220,3 +685,281 @@ func (r *todoRepository) GetMyTodo(ctx context.Context, userID string) ([]*Todo,
 	return todos, nil
 
 }
+
+func (r *todoRepository) GetTodosByIDs(ctx context.Context, todoIDs []primitive.ObjectID) ([]*Todo, error) {
+
+	var todos []*Todo
+
+	if len(todoIDs) == 0 {
+		return todos, nil
+	}
+
+	cursor, err := r.todoCollection.Find(ctx, bson.M{"_id": bson.M{"$in": todoIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var todo Todo
+		if err := cursor.Decode(&todo); err != nil {
+			return nil, err
+		}
+		todos = append(todos, &todo)
+	}
+
+	return todos, cursor.Err()
+}
+
+func (r *todoRepository) GetBlockedTodos(ctx context.Context, blockerID primitive.ObjectID) ([]*Todo, error) {
+
+	var todos []*Todo
+
+	cursor, err := r.todoCollection.Find(ctx, bson.M{"blocked_by": blockerID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var todo Todo
+		if err := cursor.Decode(&todo); err != nil {
+			return nil, err
+		}
+		todos = append(todos, &todo)
+	}
+
+	return todos, cursor.Err()
+}
+
+// AddDependency links the blocker unless the todo is done by now. It reports whether a new
+// link was written.
+func (r *todoRepository) AddDependency(ctx context.Context, todoID, blockerID primitive.ObjectID) (bool, error) {
+	result, err := r.todoCollection.UpdateOne(ctx,
+		bson.M{"_id": todoID, "status": bson.M{"$ne": TodoStatusDone}},
+		bson.M{"$addToSet": bson.M{"blocked_by": blockerID}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *todoRepository) RemoveDependency(ctx context.Context, todoID, blockerID primitive.ObjectID) error {
+	_, err := r.todoCollection.UpdateOne(ctx, bson.M{"_id": todoID}, bson.M{"$pull": bson.M{"blocked_by": blockerID}})
+	return err
+}
+
+func (r *todoRepository) CreateInvite(ctx context.Context, invite *TodoInvite) error {
+	_, err := r.inviteCollection.InsertOne(ctx, invite)
+	return err
+}
+
+func (r *todoRepository) GetInviteByID(ctx context.Context, inviteID primitive.ObjectID) (*TodoInvite, error) {
+
+	var invite TodoInvite
+
+	err := r.inviteCollection.FindOne(ctx, bson.M{"_id": inviteID}).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *todoRepository) GetInviteByCode(ctx context.Context, code string) (*TodoInvite, error) {
+
+	var invite TodoInvite
+
+	err := r.inviteCollection.FindOne(ctx, bson.M{"code": code}).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *todoRepository) GetInvitesByTodo(ctx context.Context, todoID primitive.ObjectID) ([]*TodoInvite, error) {
+
+	var invites []*TodoInvite
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.inviteCollection.Find(ctx, bson.M{"todo_id": todoID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var invite TodoInvite
+		if err := cursor.Decode(&invite); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+
+	return invites, cursor.Err()
+}
+
+// ClaimInvite takes one use of a live invite in a single update, so concurrent
+// redemptions can never exceed MaxUses. It returns nil when the invite is unusable.
+func (r *todoRepository) ClaimInvite(ctx context.Context, code string, now time.Time) (*TodoInvite, error) {
+
+	filter := bson.M{
+		"code":       code,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": now},
+		"$expr":      bson.M{"$lt": bson.A{"$uses", "$max_uses"}},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var invite TodoInvite
+	err := r.inviteCollection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"uses": 1}}, opts).Decode(&invite)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// ReleaseInvite gives back a use taken by ClaimInvite when the join itself failed.
+func (r *todoRepository) ReleaseInvite(ctx context.Context, inviteID primitive.ObjectID) error {
+	_, err := r.inviteCollection.UpdateOne(ctx,
+		bson.M{"_id": inviteID, "uses": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"uses": -1}})
+	return err
+}
+
+func (r *todoRepository) RevokeInvite(ctx context.Context, inviteID primitive.ObjectID, userID string, now time.Time) error {
+	_, err := r.inviteCollection.UpdateOne(ctx,
+		bson.M{"_id": inviteID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": now, "revoked_by": userID}})
+	return err
+}
+
+func (r *todoRepository) CreateRedemption(ctx context.Context, redemption *InviteRedemption) error {
+	_, err := r.redemptionCollection.InsertOne(ctx, redemption)
+	return err
+}
+
+func (r *todoRepository) GetRedemptionsByInvite(ctx context.Context, inviteID primitive.ObjectID) ([]*InviteRedemption, error) {
+
+	var redemptions []*InviteRedemption
+
+	opts := options.Find().SetSort(bson.D{{Key: "redeemed_at", Value: 1}})
+
+	cursor, err := r.redemptionCollection.Find(ctx, bson.M{"invite_id": inviteID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var redemption InviteRedemption
+		if err := cursor.Decode(&redemption); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, &redemption)
+	}
+
+	return redemptions, cursor.Err()
+}
+
+func (r *todoRepository) CreateJoinRequest(ctx context.Context, request *JoinRequest) error {
+	_, err := r.joinRequestCollection.InsertOne(ctx, request)
+	return err
+}
+
+func (r *todoRepository) GetJoinRequestByID(ctx context.Context, requestID primitive.ObjectID) (*JoinRequest, error) {
+
+	var request JoinRequest
+
+	err := r.joinRequestCollection.FindOne(ctx, bson.M{"_id": requestID}).Decode(&request)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *todoRepository) GetPendingJoinRequest(ctx context.Context, todoID primitive.ObjectID, userID string) (*JoinRequest, error) {
+
+	var request JoinRequest
+
+	filter := bson.M{"todo_id": todoID, "user_id": userID, "status": JoinRequestPending}
+
+	err := r.joinRequestCollection.FindOne(ctx, filter).Decode(&request)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *todoRepository) GetJoinRequestsByTodo(ctx context.Context, todoID primitive.ObjectID, status string) ([]*JoinRequest, error) {
+
+	var requests []*JoinRequest
+
+	filter := bson.M{"todo_id": todoID}
+	if status != "" && status != "all" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.joinRequestCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var request JoinRequest
+		if err := cursor.Decode(&request); err != nil {
+			return nil, err
+		}
+		requests = append(requests, &request)
+	}
+
+	return requests, cursor.Err()
+}
+
+// DecideJoinRequest moves a pending request to approved or rejected. It reports false
+// when the request was no longer pending, so two reviewers cannot both decide it.
+func (r *todoRepository) DecideJoinRequest(ctx context.Context, requestID primitive.ObjectID, status, decidedBy string, reason *string, now time.Time) (bool, error) {
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"decided_by": decidedBy,
+			"decided_at": now,
+			"reason":     reason,
+		},
+	}
+
+	result, err := r.joinRequestCollection.UpdateOne(ctx, bson.M{"_id": requestID, "status": JoinRequestPending}, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+var notDoneTodo = bson.M{"status": bson.M{"$ne": TodoStatusDone}}
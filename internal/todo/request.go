@@ -11,6 +11,8 @@ type CreateTodoRequest struct {
 	Options        *string `json:"options"`
 	CreatedBy      string  `json:"created_by"`
 	ImageTask      string  `json:"image_task"`
+	JoinPolicy     string  `json:"join_policy"`
+	ProgressMode   string  `json:"progress_mode"`
 }
 
 type UpdateTaskProgressRequest struct {
@@ -27,12 +29,31 @@ type UpdateTaskProgressRequest struct {
 	CreatedBy   string   `json:"created_by"`
 	Pictures    []string `json:"pictures"`
 	ImageTask   string   `json:"image_task"`
-	Progress    int      `json:"progress"`
+	Progress    *int     `json:"progress"`
+	JoinPolicy  *string  `json:"join_policy"`
+	ProgressMode *string `json:"progress_mode"`
 }
 
+// JoinTodoRequest joins with an invite code, or with the todo's permanent QR code and
+// a self-chosen Type when the organization still allows it.
 type JoinTodoRequest struct {
-	QRCode string `json:"qrcode"`
-	Type   string `json:"type"`
+	QRCode     string `json:"qrcode"`
+	Type       string `json:"type"`
+	InviteCode string `json:"invite_code"`
+	// Message is shown to the creator when the todo needs approval to join
+	Message string `json:"message"`
+}
+
+type AddMembersRequest struct {
+	Type    string   `json:"type"`
+	UserIDs []string `json:"user_ids"`
+}
+
+// MoveMemberRequest changes a member's role. From can be left empty when the
+// member holds a single role on the todo.
+type MoveMemberRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 type AddUserRequest struct {
@@ -40,3 +61,31 @@ type AddUserRequest struct {
 	UserIDs []string `json:"user_ids"`
 	Type    string   `json:"type"`
 }
+
+// AddDependencyRequest links the todo to another one. Exactly one of the fields is set:
+// BlockedBy makes the todo wait on the given todo, Blocks makes the given todo wait on it.
+type AddDependencyRequest struct {
+	BlockedBy string `json:"blocked_by"`
+	Blocks    string `json:"blocks"`
+}
+
+// CreateInviteRequest sets up an invite code. MaxUses defaults to a single use, and the
+// invite expires after ExpiresAt, or ExpiresInHours (default 7 days) when it is empty.
+type CreateInviteRequest struct {
+	Role           string `json:"role"`
+	MaxUses        int    `json:"max_uses"`
+	ExpiresAt      string `json:"expires_at"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+type RejectJoinRequestRequest struct {
+	Reason string `json:"reason"`
+}
+
+// UpdateMemberProgressRequest reports a member's own progress. Pictures are appended
+// to the ones already submitted.
+type UpdateMemberProgressRequest struct {
+	Progress *int     `json:"progress"`
+	Status   string   `json:"status"`
+	Pictures []string `json:"pictures"`
+}
@@ -2,6 +2,7 @@ package todo
 
 import (
 	"time"
+	"todo-service/internal/timesheet"
 	"todo-service/internal/user"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,6 +18,7 @@ type TodoResponse struct {
 	Link           *string            `json:"link" bson:"link"`
 	Progress       int                `json:"progress" bson:"progress"`
 	Stage          *string            `json:"stage" bson:"stage"`
+	Position       float64            `json:"position" bson:"position"`
 	Status         string             `json:"status" bson:"status"`
 	QRCode         string             `json:"qrcode" bson:"qrcode"`
 	Options        *string            `json:"options" bson:"options"`
@@ -25,10 +27,16 @@ type TodoResponse struct {
 	ImageTask      string             `json:"image_task" bson:"image_task"`
 	FeedBack       *string            `json:"feedback" bson:"feedback"`
 	TaskUsers      TaskUsersResponse  `json:"task_users" bson:"task_users"`
-	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
-	DeletedAt      *string            `json:"deleted_at" bson:"deleted_at"`
-	DeletedBy      *string            `json:"deleted_by" bson:"deleted_by"`
+	JoinPolicy     string             `json:"join_policy" bson:"join_policy"`
+	ProgressMode   string             `json:"progress_mode" bson:"progress_mode"`
+	// TimeSpent is only filled on the detail response
+	TimeSpent    *timesheet.TimeTotalResponse `json:"time_spent,omitempty" bson:"-"`
+	CompletedAt  *time.Time                   `json:"completed_at" bson:"completed_at"`
+	OverdueSince *time.Time                   `json:"overdue_since" bson:"overdue_since"`
+	CreatedAt    time.Time                    `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at" bson:"updated_at"`
+	DeletedAt    *string                      `json:"deleted_at" bson:"deleted_at"`
+	DeletedBy    *string                      `json:"deleted_by" bson:"deleted_by"`
 }
 
 type TaskUsersResponse struct {
@@ -43,3 +51,140 @@ type TaskUser struct {
 	Roles    []string    `json:"roles,omitempty"`
 	Avartar  user.Avatar `json:"avatar"`
 }
+
+type DependencyNode struct {
+	ID       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	Status   string             `json:"status"`
+	Progress int                `json:"progress"`
+	DueDate  time.Time          `json:"due_date"`
+	Depth    int                `json:"depth"`
+}
+
+type DependencyEdge struct {
+	// Blocker must be done before Blocked can be finished.
+	Blocker primitive.ObjectID `json:"blocker"`
+	Blocked primitive.ObjectID `json:"blocked"`
+}
+
+type DependencyGraphResponse struct {
+	Todo         DependencyNode   `json:"todo"`
+	Upstream     []DependencyNode `json:"upstream"`
+	Downstream   []DependencyNode `json:"downstream"`
+	Edges        []DependencyEdge `json:"edges"`
+	OpenBlockers int              `json:"open_blockers"`
+}
+
+const (
+	InviteStatusActive  = "active"
+	InviteStatusExpired = "expired"
+	InviteStatusUsedUp  = "used_up"
+	InviteStatusRevoked = "revoked"
+)
+
+type InviteResponse struct {
+	ID            primitive.ObjectID `json:"id"`
+	TodoID        primitive.ObjectID `json:"todo_id"`
+	Code          string             `json:"code"`
+	QRCode        string             `json:"qrcode"`
+	Role          string             `json:"role"`
+	MaxUses       int                `json:"max_uses"`
+	Uses          int                `json:"uses"`
+	RemainingUses int                `json:"remaining_uses"`
+	Status        string             `json:"status"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+	RevokedAt     *time.Time         `json:"revoked_at"`
+	RevokedBy     *string            `json:"revoked_by"`
+	CreatedBy     string             `json:"created_by"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// InvitePreviewResponse describes an invite code before it is redeemed.
+type InvitePreviewResponse struct {
+	Code      string             `json:"code"`
+	Role      string             `json:"role"`
+	Status    string             `json:"status"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	TodoID    primitive.ObjectID `json:"todo_id"`
+	TodoName  string             `json:"todo_name"`
+}
+
+const (
+	JoinResultJoined  = "joined"
+	JoinResultPending = "pending"
+)
+
+// JoinTodoResponse tells the caller whether they are in, or waiting for approval.
+type JoinTodoResponse struct {
+	TodoID    primitive.ObjectID  `json:"todo_id"`
+	Status    string              `json:"status"`
+	Role      string              `json:"role"`
+	RequestID *primitive.ObjectID `json:"request_id,omitempty"`
+}
+
+type JoinRequestResponse struct {
+	ID        primitive.ObjectID `json:"id"`
+	TodoID    primitive.ObjectID `json:"todo_id"`
+	User      TaskUser           `json:"user"`
+	Role      string             `json:"role"`
+	Message   string             `json:"message"`
+	Status    string             `json:"status"`
+	DecidedBy *string            `json:"decided_by"`
+	DecidedAt *time.Time         `json:"decided_at"`
+	Reason    *string            `json:"reason"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type MemberProgressResponse struct {
+	User        TaskUser   `json:"user"`
+	Role        string     `json:"role"`
+	Progress    int        `json:"progress"`
+	Status      string     `json:"status"`
+	Pictures    []string   `json:"pictures"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	// Behind is set for unfinished members below the todo's average, or for every
+	// unfinished member once the todo is overdue
+	Behind bool `json:"behind"`
+}
+
+// ProgressBreakdownResponse lists members with the least progress first.
+type ProgressBreakdownResponse struct {
+	TodoID   primitive.ObjectID        `json:"todo_id"`
+	Mode     string                    `json:"mode"`
+	Progress int                       `json:"progress"`
+	Status   string                    `json:"status"`
+	Overdue  bool                      `json:"overdue"`
+	Done     int                       `json:"done"`
+	Total    int                       `json:"total"`
+	Members  []*MemberProgressResponse `json:"members"`
+}
+
+type CountResponse struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type LeaderboardEntry struct {
+	User            TaskUser `json:"user"`
+	Assigned        int      `json:"assigned"`
+	Completed       int      `json:"completed"`
+	CompletedOnTime int      `json:"completed_on_time"`
+	AverageProgress float64  `json:"average_progress"`
+}
+
+// TodoStatsResponse covers the todos created in [From, To). Rates are percentages.
+type TodoStatsResponse struct {
+	OrganizationID    string             `json:"organization_id"`
+	From              time.Time          `json:"from"`
+	To                time.Time          `json:"to"`
+	Total             int                `json:"total"`
+	ByStatus          []CountResponse    `json:"by_status"`
+	ByStage           []CountResponse    `json:"by_stage"`
+	Overdue           int                `json:"overdue"`
+	Completed         int                `json:"completed"`
+	OnTimeRate        float64            `json:"on_time_rate"`
+	AverageCycleHours float64            `json:"average_cycle_hours"`
+	UrgentShare       float64            `json:"urgent_share"`
+	Leaderboard       []LeaderboardEntry `json:"leaderboard"`
+}
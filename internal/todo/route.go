@@ -18,5 +18,29 @@ func RegisterRoutes(r *gin.Engine, todoHanlder *TodoHandler) {
 		todoGroup.POST("/join", todoHanlder.JoinTodo)
 		todoGroup.POST("add-user", todoHanlder.AddUser)
 		todoGroup.GET("/my-todo", todoHanlder.GetMyTodo)
+		todoGroup.GET("/stats", todoHanlder.GetTodoStats)
+		// Dependencies
+		todoGroup.GET("/:id/dependencies", todoHanlder.GetDependencies)
+		todoGroup.POST("/:id/dependencies", todoHanlder.AddDependency)
+		todoGroup.DELETE("/:id/dependencies/:blocker_id", todoHanlder.RemoveDependency)
+		todoGroup.GET("/:id/qrcode", todoHanlder.GetQRCode)
+		// Invites
+		todoGroup.POST("/:id/invites", todoHanlder.CreateInvite)
+		todoGroup.GET("/:id/invites", todoHanlder.GetInvites)
+		todoGroup.DELETE("/:id/invites/:invite_id", todoHanlder.RevokeInvite)
+		todoGroup.GET("/:id/invites/:invite_id/redemptions", todoHanlder.GetInviteRedemptions)
+		// Membership
+		todoGroup.POST("/:id/members", todoHanlder.AddMembers)
+		todoGroup.DELETE("/:id/members/:user_id", todoHanlder.RemoveMember)
+		todoGroup.POST("/:id/members/:user_id/move", todoHanlder.MoveMember)
+		todoGroup.POST("/:id/leave", todoHanlder.LeaveTodo)
+		// Member progress
+		todoGroup.GET("/:id/progress", todoHanlder.GetProgressBreakdown)
+		todoGroup.PUT("/:id/progress", todoHanlder.UpdateMyProgress)
+		todoGroup.PUT("/:id/members/:user_id/progress", todoHanlder.UpdateMemberProgress)
+		// Join requests
+		todoGroup.GET("/:id/join-requests", todoHanlder.GetJoinRequests)
+		todoGroup.POST("/:id/join-requests/:request_id/approve", todoHanlder.ApproveJoinRequest)
+		todoGroup.POST("/:id/join-requests/:request_id/reject", todoHanlder.RejectJoinRequest)
 	}
 }
@@ -2,40 +2,84 @@ package todo
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"math"
+	"sort"
+	"strings"
 	"time"
+	"todo-service/helper"
+	"todo-service/internal/board"
+	"todo-service/internal/notification"
+	"todo-service/internal/setting"
+	"todo-service/internal/timesheet"
 	"todo-service/internal/user"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TodoService interface {
-	GetAllTodo(ctx context.Context, status, name, teacher, student, staff string) ([]*TodoResponse, error)
-	GetTodoByID(ctx context.Context, todoID string) (*TodoResponse, error)
+	GetAllTodo(ctx context.Context, status, name, teacher, student, staff string, userID string) ([]*TodoResponse, error)
+	GetTodoByID(ctx context.Context, todoID string, userID string) (*TodoResponse, error)
 	CreateTodo(ctx context.Context, req CreateTodoRequest, userID string) (*string, error)
-	UpdateTodo(ctx context.Context, req UpdateTaskProgressRequest, id string) error
-	DeleteTodo(ctx context.Context, id string) error
+	UpdateTodo(ctx context.Context, req UpdateTaskProgressRequest, id string, userID string) error
+	DeleteTodo(ctx context.Context, id string, userID string) error
 	// Join Todo
-	JoinTodo(ctx context.Context, req JoinTodoRequest, userID string, isCreator bool) error
-	AddUser(ctx context.Context, req AddUserRequest) error
+	JoinTodo(ctx context.Context, req JoinTodoRequest, userID string, isCreator bool) (*JoinTodoResponse, error)
+	AddUser(ctx context.Context, req AddUserRequest, userID string) error
 	GetMyTodo(ctx context.Context, userID string) ([]*TodoResponse, float64, error)
+	// Dependencies
+	AddDependency(ctx context.Context, todoID string, req AddDependencyRequest, userID string) error
+	RemoveDependency(ctx context.Context, todoID, blockerID string, userID string) error
+	GetDependencies(ctx context.Context, todoID string, userID string) (*DependencyGraphResponse, error)
+	GetTodoQRCode(ctx context.Context, todoID string) (string, error)
+	GetTodoActions(ctx context.Context, todoID string, userID string) ([]string, error)
+	// Invites
+	CreateInvite(ctx context.Context, todoID string, req CreateInviteRequest, userID string) (*InviteResponse, error)
+	GetInvites(ctx context.Context, todoID string, userID string) ([]*InviteResponse, error)
+	RevokeInvite(ctx context.Context, todoID, inviteID string, userID string) error
+	GetInviteRedemptions(ctx context.Context, todoID, inviteID string, userID string) ([]*InviteRedemption, error)
+	PreviewInvite(ctx context.Context, code string, userID string) (*InvitePreviewResponse, []string, error)
+	// Membership
+	AddMembers(ctx context.Context, todoID string, req AddMembersRequest, userID string) error
+	RemoveMember(ctx context.Context, todoID, memberID, memberType string, userID string) error
+	MoveMember(ctx context.Context, todoID, memberID string, req MoveMemberRequest, userID string) error
+	LeaveTodo(ctx context.Context, todoID string, userID string) error
+	// Join requests
+	GetJoinRequests(ctx context.Context, todoID, status string, userID string) ([]*JoinRequestResponse, error)
+	ApproveJoinRequest(ctx context.Context, todoID, requestID string, userID string) error
+	RejectJoinRequest(ctx context.Context, todoID, requestID string, req RejectJoinRequestRequest, userID string) error
+	// Member progress
+	UpdateMemberProgress(ctx context.Context, todoID, memberID string, req UpdateMemberProgressRequest, userID string) error
+	GetProgressBreakdown(ctx context.Context, todoID string, userID string) (*ProgressBreakdownResponse, error)
+	// Stats
+	GetTodoStats(ctx context.Context, organizationID, from, to string, limit int, userID string) (*TodoStatsResponse, error)
 }
 
 type todoService struct {
-	TodoRepo    TodoRepository
-	UserService user.UserService
+	TodoRepo            TodoRepository
+	UserService         user.UserService
+	SettingService      setting.SettingService
+	BoardService        board.BoardService
+	NotificationService notification.NotificationService
+	TimesheetService    timesheet.TimesheetService
+	Policy              *Policy
 }
 
-func NewTodoService(TodoRepo TodoRepository, UserService user.UserService) TodoService {
+func NewTodoService(TodoRepo TodoRepository, UserService user.UserService, SettingService setting.SettingService, BoardService board.BoardService, NotificationService notification.NotificationService, TimesheetService timesheet.TimesheetService) TodoService {
 	return &todoService{
-		TodoRepo:    TodoRepo,
-		UserService: UserService,
+		TodoRepo:            TodoRepo,
+		UserService:         UserService,
+		SettingService:      SettingService,
+		BoardService:        BoardService,
+		NotificationService: NotificationService,
+		TimesheetService:    TimesheetService,
+		Policy:              NewPolicy(),
 	}
 }
 
-func (s *todoService) GetAllTodo(ctx context.Context, status, name, teacher, student, staff string) ([]*TodoResponse, error) {
+func (s *todoService) GetAllTodo(ctx context.Context, status, name, teacher, student, staff string, userID string) ([]*TodoResponse, error) {
 
 	todos, err := s.TodoRepo.GetAllTodo(ctx, status, name, teacher, student, staff)
 	if err != nil {
@@ -43,11 +87,13 @@ func (s *todoService) GetAllTodo(ctx context.Context, status, name, teacher, stu
 	}
 
 	var results []*TodoResponse
+	locations := map[string]*time.Location{}
 	for _, todo := range todos {
 		if todo == nil {
 			continue
 		}
-		response := s.buildTodoResponse(ctx, todo)
+		loc := s.userLocation(ctx, userID, todo.OrganizationID, locations)
+		response := s.buildTodoResponse(ctx, todo, loc)
 		if response != nil {
 			results = append(results, response)
 		}
@@ -55,7 +101,17 @@ func (s *todoService) GetAllTodo(ctx context.Context, status, name, teacher, stu
 	return results, nil
 }
 
-func (s *todoService) GetTodoByID(ctx context.Context, todoID string) (*TodoResponse, error) {
+// userLocation resolves the user's time zone once per organization while building a list.
+func (s *todoService) userLocation(ctx context.Context, userID, organizationID string, locations map[string]*time.Location) *time.Location {
+	loc, ok := locations[organizationID]
+	if !ok {
+		loc = s.SettingService.GetUserLocation(ctx, userID, organizationID)
+		locations[organizationID] = loc
+	}
+	return loc
+}
+
+func (s *todoService) GetTodoByID(ctx context.Context, todoID string, userID string) (*TodoResponse, error) {
 	objectID, err := primitive.ObjectIDFromHex(todoID)
 	if err != nil {
 		return nil, err
@@ -69,7 +125,18 @@ func (s *todoService) GetTodoByID(ctx context.Context, todoID string) (*TodoResp
 		return nil, fmt.Errorf("todo not found")
 	}
 
-	return s.buildTodoResponse(ctx, todo), nil
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+
+	response := s.buildTodoResponse(ctx, todo, loc)
+	if response != nil && s.TimesheetService != nil {
+		timeSpent, err := s.TimesheetService.GetTargetTotal(ctx, timesheet.TargetTodo, todo.ID)
+		if err != nil {
+			log.Printf("[WARN] failed to load time spent on todo %s: %v", todo.ID.Hex(), err)
+		}
+		response.TimeSpent = timeSpent
+	}
+
+	return response, nil
 }
 
 func (s *todoService) CreateTodo(ctx context.Context, req CreateTodoRequest, userID string) (*string, error) {
@@ -86,9 +153,27 @@ func (s *todoService) CreateTodo(ctx context.Context, req CreateTodoRequest, use
 		return nil, fmt.Errorf("organization id is required")
 	}
 
-	dueDate, err := time.Parse("2006-01-02 15:04:05", req.DueDate)
+	joinPolicy := req.JoinPolicy
+	if joinPolicy == "" {
+		joinPolicy = JoinPolicyOpen
+	}
+	if err := validateJoinPolicy(joinPolicy); err != nil {
+		return nil, err
+	}
+
+	progressMode := req.ProgressMode
+	if progressMode == "" {
+		progressMode = ProgressModeMean
+	}
+	if err := validateProgressMode(progressMode); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetOrganizationLocation(ctx, req.OrganizationID)
+
+	dueDate, err := helper.ParseDateTime(req.DueDate, loc)
 	if err != nil {
-		return nil, fmt.Errorf("invalid due_date format, expected YYYY-MM-DD HH:MM:SS: %v", err)
+		return nil, fmt.Errorf("invalid due_date format: %v", err)
 	}
 
 	ID := primitive.NewObjectID()
@@ -104,8 +189,7 @@ func (s *todoService) CreateTodo(ctx context.Context, req CreateTodoRequest, use
 		Urgent:         req.Urgent,
 		Link:           req.Link,
 		Progress:       0,
-		Status:         "pending",
-		Stage:          req.Stage,
+		Status:         TodoStatusPending,
 		QRCode:         QRCocde,
 		Options:        req.Options,
 		CreatedBy:      userID,
@@ -115,15 +199,27 @@ func (s *todoService) CreateTodo(ctx context.Context, req CreateTodoRequest, use
 			Students: []string{},
 			Staffs:   []string{},
 		},
-		Feedback:  nil,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		ImageTask: req.ImageTask,
-		DeletedAt: nil,
-		DeletedBy: nil,
+		JoinPolicy:     joinPolicy,
+		ProgressMode:   progressMode,
+		MemberProgress: []MemberProgress{},
+		BlockedBy:      []primitive.ObjectID{},
+		Feedback:       nil,
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		ImageTask:      req.ImageTask,
+		DeletedAt:      nil,
+		DeletedBy:      nil,
 	}
 
-	id, err := s.TodoRepo.CreateTodo(ctx, todo)
+	// The board places the card, so that a new todo respects the column's WIP limit
+	var id *string
+	err = s.BoardService.AddCard(ctx, req.OrganizationID, req.Stage, func(stage *string, position float64) error {
+		todo.Stage = stage
+		todo.Position = position
+		created, err := s.TodoRepo.CreateTodo(ctx, todo)
+		id = created
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +228,7 @@ func (s *todoService) CreateTodo(ctx context.Context, req CreateTodoRequest, use
 
 }
 
-func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequest, id string) error {
+func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequest, id string, userID string) error {
 
 	if id == "" {
 		return fmt.Errorf("id is required")
@@ -151,8 +247,17 @@ func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequ
 		return fmt.Errorf("todo not found")
 	}
 
-	if req.Progress < 0 || req.Progress > 100 {
-		return fmt.Errorf("progress must be between 0 and 100")
+	progress := existingTodo.Progress
+	if req.Progress != nil {
+		if *req.Progress < 0 || *req.Progress > 100 {
+			return fmt.Errorf("progress must be between 0 and 100")
+		}
+
+		if existingTodo.TracksMemberProgress() && *req.Progress != existingTodo.Progress {
+			return fmt.Errorf("progress is rolled up from member progress, report it per member instead")
+		}
+
+		progress = *req.Progress
 	}
 
 	if req.Name == "" {
@@ -163,16 +268,45 @@ func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequ
 		req.Description = existingTodo.Description
 	}
 
-	if req.DueDate == "" {
-		req.DueDate = existingTodo.DueDate.Format("2006-01-02 15:04:05")
+	dueDate := existingTodo.DueDate
+	if req.DueDate != "" {
+		loc := s.SettingService.GetOrganizationLocation(ctx, existingTodo.OrganizationID)
+		dueDate, err = helper.ParseDateTime(req.DueDate, loc)
+		if err != nil {
+			return fmt.Errorf("invalid due_date format: %v", err)
+		}
+	}
+
+	if err := s.Policy.CanUpdateTodo(ctx, existingTodo, userID, changedFields(existingTodo, req, dueDate)); err != nil {
+		return err
+	}
+
+	joinPolicy := existingTodo.JoinPolicy
+	if req.JoinPolicy != nil {
+		if err := validateJoinPolicy(*req.JoinPolicy); err != nil {
+			return err
+		}
+		joinPolicy = *req.JoinPolicy
+	}
+
+	progressMode := existingTodo.ProgressMode
+	if req.ProgressMode != nil {
+		if err := validateProgressMode(*req.ProgressMode); err != nil {
+			return err
+		}
+		progressMode = *req.ProgressMode
 	}
 
 	if req.Link == nil {
 		req.Link = existingTodo.Link
 	}
 
-	if req.Stage == nil {
-		req.Stage = existingTodo.Stage
+	// Stage and position are kept by the board, see the stage change below
+	changingStage := req.Stage != nil && (existingTodo.Stage == nil || *req.Stage != *existingTodo.Stage)
+	if changingStage {
+		if _, err := s.BoardService.ResolveStage(ctx, existingTodo.OrganizationID, req.Stage); err != nil {
+			return err
+		}
 	}
 
 	if req.Options == nil {
@@ -199,6 +333,10 @@ func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequ
 		req.ImageTask = existingTodo.ImageTask
 	}
 
+	if req.Feedback == nil {
+		req.Feedback = existingTodo.Feedback
+	}
+
 	if req.Status == "" {
 		req.Status = existingTodo.Status
 	}
@@ -208,24 +346,55 @@ func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequ
 		urgentValue = *req.Urgent
 	}
 
-	updatedAt := time.Now()
+	finishing := (req.Status == TodoStatusDone && existingTodo.Status != TodoStatusDone) ||
+		(progress == 100 && existingTodo.Progress != 100)
+	if finishing {
+		if err := s.ensureNotBlocked(ctx, existingTodo); err != nil {
+			return err
+		}
+	}
+
+	// A stage change through PUT lands the card at the bottom of its new column, through the
+	// board so that it takes the move lock and respects the WIP limit like a drag would
+	if changingStage {
+		if err := s.BoardService.ChangeStage(ctx, existingTodo.OrganizationID, existingTodo.ID, req.Stage); err != nil {
+			return err
+		}
+	}
+
+	updatedAt := time.Now().UTC()
+
+	completedAt := existingTodo.CompletedAt
+	if req.Status != TodoStatusDone {
+		completedAt = nil
+	} else if existingTodo.Status != TodoStatusDone {
+		completedAt = &updatedAt
+	}
 
 	todo := &Todo{
 		ID:             existingTodo.ID,
 		Name:           req.Name,
 		OrganizationID: existingTodo.OrganizationID,
 		Description:    req.Description,
-		DueDate:        existingTodo.DueDate,
+		DueDate:        dueDate,
 		Urgent:         urgentValue,
 		Link:           req.Link,
-		Progress:       req.Progress,
+		Progress:       progress,
 		Status:         req.Status,
-		Stage:          req.Stage,
+		Stage:          existingTodo.Stage,
+		Position:       existingTodo.Position,
 		QRCode:         existingTodo.QRCode,
 		Options:        req.Options,
 		CreatedBy:      existingTodo.CreatedBy,
 		Pictures:       req.Pictures,
 		TaskUsers:      existingTodo.TaskUsers,
+		JoinPolicy:     joinPolicy,
+		ProgressMode:   progressMode,
+		MemberProgress: existingTodo.MemberProgress,
+		BlockedBy:      existingTodo.BlockedBy,
+		CompletedAt:    completedAt,
+		OverdueSince:   existingTodo.OverdueSince,
+		Escalations:    existingTodo.Escalations,
 		CreatedAt:      existingTodo.CreatedAt,
 		UpdatedAt:      updatedAt,
 		Feedback:       req.Feedback,
@@ -234,11 +403,28 @@ func (s *todoService) UpdateTodo(ctx context.Context, req UpdateTaskProgressRequ
 		DeletedBy:      existingTodo.DeletedBy,
 	}
 
-	return s.TodoRepo.UpdateTodo(ctx, todo)
+	if err := s.TodoRepo.UpdateTodo(ctx, todo); err != nil {
+		return err
+	}
+
+	// A finished or rescheduled todo escalates from the start if it is missed again
+	if existingTodo.OverdueSince != nil && !todo.IsOverdue(updatedAt) {
+		if err := s.TodoRepo.ClearResolvedOverdueTodo(ctx, todo.ID, updatedAt); err != nil {
+			log.Printf("[WARN] failed to clear the overdue state of todo %s: %v", todo.ID.Hex(), err)
+		}
+	}
+
+	// The saved progress is the aggregate as it was read, so roll it up again in case a
+	// member reported in between
+	if existingTodo.TracksMemberProgress() || progressMode != existingTodo.ProgressMode {
+		s.recomputeProgress(ctx, todo.ID)
+	}
+
+	return nil
 
 }
 
-func (s *todoService) DeleteTodo(ctx context.Context, id string) error {
+func (s *todoService) DeleteTodo(ctx context.Context, id string, userID string) error {
 
 	if id == "" {
 		return fmt.Errorf("id is required")
@@ -249,94 +435,180 @@ func (s *todoService) DeleteTodo(ctx context.Context, id string) error {
 		return err
 	}
 
+	existingTodo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if existingTodo == nil {
+		return fmt.Errorf("todo not found")
+	}
+
+	if err := s.Policy.CanDeleteTodo(ctx, existingTodo, userID); err != nil {
+		return err
+	}
+
 	return s.TodoRepo.DeleteTodo(ctx, objectID)
 
 }
 
-func (s *todoService) JoinTodo(ctx context.Context, req JoinTodoRequest, userID string, isCreator bool) error {
+func (s *todoService) JoinTodo(ctx context.Context, req JoinTodoRequest, userID string, isCreator bool) (*JoinTodoResponse, error) {
 
-	if req.QRCode == "" {
-		return fmt.Errorf("qrcode is required")
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
 	}
 
-	if userID == "" {
-		return fmt.Errorf("user id is required")
+	inviteCode := strings.TrimSpace(req.InviteCode)
+	if inviteCode == "" && strings.HasPrefix(req.QRCode, InviteQRCodePrefix) {
+		inviteCode = strings.TrimPrefix(req.QRCode, InviteQRCodePrefix)
+	}
+
+	if inviteCode != "" {
+		return s.redeemInvite(ctx, strings.ToUpper(inviteCode), userID, isCreator)
+	}
+
+	if req.QRCode == "" {
+		return nil, fmt.Errorf("qrcode or invite_code is required")
 	}
 
 	if req.Type == "" {
-		return fmt.Errorf("type is required")
+		return nil, fmt.Errorf("type is required")
 	}
 
 	todoExist, err := s.TodoRepo.GetTodoByQRCode(ctx, req.QRCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if todoExist == nil {
-		return fmt.Errorf("todo not found")
+		return nil, fmt.Errorf("todo not found")
 	}
 
-	if todoExist.CreatedBy == userID {
-		return fmt.Errorf("you cannot join your own todo")
+	orgSetting, err := s.SettingService.GetOrganizationSetting(ctx, todoExist.OrganizationID)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(todoExist.TaskUsers.Students) > 0 {
-		for _, student := range todoExist.TaskUsers.Students {
-			if student == userID && req.Type == "students" {
-				return fmt.Errorf("you have already joined this todo as student")
-			}
-		}
+	if orgSetting.DisableLegacyQRJoin {
+		return nil, fmt.Errorf("joining with the todo qrcode is disabled for this organization, ask for an invite code")
 	}
 
-	if len(todoExist.TaskUsers.Staffs) > 0 {
-		for _, staff := range todoExist.TaskUsers.Staffs {
-			if staff == userID && req.Type == "staffs" {
-				return fmt.Errorf("you have already joined this todo as staff")
-			}
-		}
+	if err := ensureCanJoin(todoExist, userID, req.Type); err != nil {
+		return nil, err
 	}
 
-	if len(todoExist.TaskUsers.Teachers) > 0 {
-		for _, teacher := range todoExist.TaskUsers.Teachers {
-			if teacher == userID && req.Type == "teachers" {
-				return fmt.Errorf("you have already joined this todo as teacher")
-			}
-		}
+	switch todoExist.EffectiveJoinPolicy() {
+	case JoinPolicyClosed:
+		return nil, fmt.Errorf("this todo is not accepting new members")
+	case JoinPolicyApproval:
+		return s.createJoinRequest(ctx, todoExist, userID, req.Type, req.Message)
+	}
+
+	if err := s.TodoRepo.JoinTodo(ctx, todoExist.ID, userID, req.Type, isCreator); err != nil {
+		return nil, err
 	}
 
-	return s.TodoRepo.JoinTodo(ctx, todoExist.ID, userID, req.Type, isCreator)
+	s.recomputeProgress(ctx, todoExist.ID)
+
+	return &JoinTodoResponse{TodoID: todoExist.ID, Status: JoinResultJoined, Role: req.Type}, nil
 }
 
-func (s *todoService) AddUser(ctx context.Context, req AddUserRequest) error {
+// ensureCanJoin rejects the creator and people already in the requested role.
+func ensureCanJoin(todo *Todo, userID, memberType string) error {
 
-	if req.TodoID == "" {
-		return fmt.Errorf("todo id is required")
+	if todo.CreatedBy == userID {
+		return fmt.Errorf("you cannot join your own todo")
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(req.TodoID)
-	if err != nil {
+	if _, err := memberField(memberType); err != nil {
 		return err
 	}
 
-	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if containsString(membersOf(todo, memberType), userID) {
+		return fmt.Errorf("you have already joined this todo as %s", strings.TrimSuffix(memberType, "s"))
+	}
+
+	return nil
+}
+
+// redeemInvite joins through an invite code. The invite was issued by the creator or a
+// teacher, so it counts as approval; only closed todos turn it away.
+func (s *todoService) redeemInvite(ctx context.Context, code, userID string, isCreator bool) (*JoinTodoResponse, error) {
+
+	invite, err := s.TodoRepo.GetInviteByCode(ctx, code)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if invite == nil {
+		return nil, fmt.Errorf("invite code not found")
+	}
+
+	now := time.Now().UTC()
+	if status := inviteStatus(invite, now); status != InviteStatusActive {
+		return nil, fmt.Errorf("invite code is %s", strings.ReplaceAll(status, "_", " "))
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, invite.TodoID)
+	if err != nil {
+		return nil, err
 	}
 
 	if todo == nil {
-		return fmt.Errorf("todo not found")
+		return nil, fmt.Errorf("todo not found")
 	}
 
-	if req.Type == "" {
-		return fmt.Errorf("type is required")
+	if todo.EffectiveJoinPolicy() == JoinPolicyClosed {
+		return nil, fmt.Errorf("this todo is not accepting new members")
+	}
+
+	if err := ensureCanJoin(todo, userID, invite.Role); err != nil {
+		return nil, err
+	}
+
+	// Another redemption may have taken the last use since the status check
+	claimed, err := s.TodoRepo.ClaimInvite(ctx, code, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed == nil {
+		return nil, fmt.Errorf("invite code is no longer valid")
+	}
+
+	if err := s.TodoRepo.JoinTodo(ctx, todo.ID, userID, claimed.Role, isCreator); err != nil {
+		if releaseErr := s.TodoRepo.ReleaseInvite(ctx, claimed.ID); releaseErr != nil {
+			log.Printf("[WARN] failed to release invite %s: %v", claimed.ID.Hex(), releaseErr)
+		}
+		return nil, err
+	}
+
+	redemption := &InviteRedemption{
+		ID:         primitive.NewObjectID(),
+		InviteID:   claimed.ID,
+		TodoID:     todo.ID,
+		UserID:     userID,
+		Role:       claimed.Role,
+		RedeemedAt: now,
 	}
 
-	if len(req.UserIDs) == 0 {
-		return fmt.Errorf("user array is required")
+	if err := s.TodoRepo.CreateRedemption(ctx, redemption); err != nil {
+		log.Printf("[WARN] joined todo %s but failed to record redemption of invite %s: %v", todo.ID.Hex(), claimed.ID.Hex(), err)
 	}
 
-	return s.TodoRepo.AddUsers(ctx, objectID, req.UserIDs, req.Type)
+	s.recomputeProgress(ctx, todo.ID)
+
+	return &JoinTodoResponse{TodoID: todo.ID, Status: JoinResultJoined, Role: claimed.Role}, nil
+}
+
+// AddUser is the older add-user endpoint, kept as an alias of AddMembers.
+func (s *todoService) AddUser(ctx context.Context, req AddUserRequest, userID string) error {
+
+	if req.TodoID == "" {
+		return fmt.Errorf("todo id is required")
+	}
 
+	return s.AddMembers(ctx, req.TodoID, AddMembersRequest{Type: req.Type, UserIDs: req.UserIDs}, userID)
 }
 
 func (s *todoService) GetMyTodo(ctx context.Context, userID string) ([]*TodoResponse, float64, error) {
@@ -355,8 +627,11 @@ func (s *todoService) GetMyTodo(ctx context.Context, userID string) ([]*TodoResp
 
 	var results []*TodoResponse
 	var totalProgress float64
+	locations := map[string]*time.Location{}
 
 	for _, todo := range myTodo {
+		loc := s.userLocation(ctx, userID, todo.OrganizationID, locations)
+
 		var createdBy TaskUser
 		if todo.CreatedBy != "" {
 			createdByInfor, err := s.UserService.GetUserInfor(ctx, todo.CreatedBy)
@@ -426,12 +701,13 @@ func (s *todoService) GetMyTodo(ctx context.Context, userID string) ([]*TodoResp
 			Name:           todo.Name,
 			Description:    todo.Description,
 			OrganizationID: todo.OrganizationID,
-			DueDate:        todo.DueDate,
+			DueDate:        todo.DueDate.In(loc),
 			Urgent:         todo.Urgent,
 			Link:           todo.Link,
 			Status:         todo.Status,
 			Progress:       todo.Progress,
 			Stage:          todo.Stage,
+			Position:       todo.Position,
 			QRCode:         todo.QRCode,
 			Options:        todo.Options,
 			CreatedBy:      createdBy,
@@ -439,8 +715,12 @@ func (s *todoService) GetMyTodo(ctx context.Context, userID string) ([]*TodoResp
 			ImageTask:      todo.ImageTask,
 			FeedBack:       todo.Feedback,
 			TaskUsers:      taskUsersResp,
-			CreatedAt:      todo.CreatedAt,
-			UpdatedAt:      todo.UpdatedAt,
+			JoinPolicy:     todo.EffectiveJoinPolicy(),
+			ProgressMode:   todo.EffectiveProgressMode(),
+			CompletedAt:    helper.InLocation(todo.CompletedAt, loc),
+			OverdueSince:   helper.InLocation(todo.OverdueSince, loc),
+			CreatedAt:      todo.CreatedAt.In(loc),
+			UpdatedAt:      todo.UpdatedAt.In(loc),
 			DeletedAt:      todo.DeletedAt,
 			DeletedBy:      todo.DeletedBy,
 		}
@@ -467,7 +747,7 @@ func safeCreateTaskUser(info *user.UserInfor) TaskUser {
 	}
 }
 
-func (s *todoService) buildTodoResponse(ctx context.Context, todo *Todo) *TodoResponse {
+func (s *todoService) buildTodoResponse(ctx context.Context, todo *Todo, loc *time.Location) *TodoResponse {
 
 	if todo == nil {
 		log.Printf("[ERROR] buildTodoResponse: todo is nil")
@@ -572,11 +852,12 @@ func (s *todoService) buildTodoResponse(ctx context.Context, todo *Todo) *TodoRe
 		Name:           todo.Name,
 		Description:    todo.Description,
 		OrganizationID: todo.OrganizationID,
-		DueDate:        todo.DueDate,
+		DueDate:        todo.DueDate.In(loc),
 		Urgent:         todo.Urgent,
 		Link:           todo.Link,
 		Progress:       todo.Progress,
 		Stage:          todo.Stage,
+		Position:       todo.Position,
 		Status:         todo.Status,
 		QRCode:         todo.QRCode,
 		Options:        todo.Options,
@@ -585,11 +866,1419 @@ func (s *todoService) buildTodoResponse(ctx context.Context, todo *Todo) *TodoRe
 		ImageTask:      todo.ImageTask,
 		FeedBack:       todo.Feedback,
 		TaskUsers:      taskUsersResp,
-		CreatedAt:      todo.CreatedAt,
-		UpdatedAt:      todo.UpdatedAt,
+		JoinPolicy:     todo.EffectiveJoinPolicy(),
+		ProgressMode:   todo.EffectiveProgressMode(),
+		CompletedAt:    helper.InLocation(todo.CompletedAt, loc),
+		OverdueSince:   helper.InLocation(todo.OverdueSince, loc),
+		CreatedAt:      todo.CreatedAt.In(loc),
+		UpdatedAt:      todo.UpdatedAt.In(loc),
 		DeletedAt:      todo.DeletedAt,
 		DeletedBy:      todo.DeletedBy,
 	}
 
 	return data
 }
+
+// AddDependency links two todos. Blocking one todo on another changes both, so the caller
+// must be allowed to edit each of them.
+func (s *todoService) AddDependency(ctx context.Context, todoID string, req AddDependencyRequest, userID string) error {
+
+	if (req.BlockedBy == "") == (req.Blocks == "") {
+		return fmt.Errorf("exactly one of blocked_by or blocks is required")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return err
+	}
+
+	otherID := req.BlockedBy
+	if otherID == "" {
+		otherID = req.Blocks
+	}
+
+	otherObjectID, err := primitive.ObjectIDFromHex(otherID)
+	if err != nil {
+		return err
+	}
+
+	// Normalise to "blocked is blocked by blocker"
+	blockedID, blockerID := objectID, otherObjectID
+	if req.Blocks != "" {
+		blockedID, blockerID = otherObjectID, objectID
+	}
+
+	if blockedID == blockerID {
+		return fmt.Errorf("a todo cannot depend on itself")
+	}
+
+	blocked, err := s.TodoRepo.GetTodoByID(ctx, blockedID)
+	if err != nil {
+		return err
+	}
+	if blocked == nil {
+		return fmt.Errorf("todo %s not found", blockedID.Hex())
+	}
+
+	blocker, err := s.TodoRepo.GetTodoByID(ctx, blockerID)
+	if err != nil {
+		return err
+	}
+	if blocker == nil {
+		return fmt.Errorf("todo %s not found", blockerID.Hex())
+	}
+
+	if blocked.OrganizationID != blocker.OrganizationID {
+		return fmt.Errorf("dependencies can only link todos in the same organization")
+	}
+
+	for _, todo := range []*Todo{blocked, blocker} {
+		if err := s.Policy.CanUpdateTodo(ctx, todo, userID, []string{FieldBlockedBy}); err != nil {
+			return err
+		}
+	}
+
+	if blocked.Status == TodoStatusDone {
+		return fmt.Errorf("todo %s is already done and cannot be blocked", blockedID.Hex())
+	}
+
+	cycle, err := s.reachesUpstream(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return fmt.Errorf("adding this dependency would create a cycle")
+	}
+
+	added, err := s.TodoRepo.AddDependency(ctx, blockedID, blockerID)
+	if err != nil || !added {
+		return err
+	}
+
+	// Two links added at the same time can each pass the check above and close a cycle
+	// together, so look again now that ours is written and take it back if so
+	cycle, err = s.reachesUpstream(ctx, blockerID, blockedID)
+	if err == nil && !cycle {
+		return nil
+	}
+	if removeErr := s.TodoRepo.RemoveDependency(ctx, blockedID, blockerID); removeErr != nil {
+		log.Printf("[WARN] failed to take back dependency of todo %s on %s: %v", blockedID.Hex(), blockerID.Hex(), removeErr)
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("adding this dependency would create a cycle")
+}
+
+// RemoveDependency unlinks a blocker. A blocker that was deleted since needs no check, so
+// stale links can still be cleared.
+func (s *todoService) RemoveDependency(ctx context.Context, todoID, blockerID string, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return err
+	}
+
+	blockerObjectID, err := primitive.ObjectIDFromHex(blockerID)
+	if err != nil {
+		return err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if todo == nil {
+		return fmt.Errorf("todo not found")
+	}
+
+	if err := s.Policy.CanUpdateTodo(ctx, todo, userID, []string{FieldBlockedBy}); err != nil {
+		return err
+	}
+
+	blocker, err := s.TodoRepo.GetTodoByID(ctx, blockerObjectID)
+	if err != nil {
+		return err
+	}
+	if blocker != nil {
+		if err := s.Policy.CanUpdateTodo(ctx, blocker, userID, []string{FieldBlockedBy}); err != nil {
+			return err
+		}
+	}
+
+	return s.TodoRepo.RemoveDependency(ctx, objectID, blockerObjectID)
+}
+
+func (s *todoService) GetDependencies(ctx context.Context, todoID string, userID string) (*DependencyGraphResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil {
+		return nil, fmt.Errorf("todo not found")
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+
+	graph := &DependencyGraphResponse{
+		Todo:       buildDependencyNode(todo, 0, loc),
+		Upstream:   []DependencyNode{},
+		Downstream: []DependencyNode{},
+		Edges:      []DependencyEdge{},
+	}
+
+	// Walk blockers level by level
+	visited := map[primitive.ObjectID]bool{todo.ID: true}
+	frontier := []*Todo{todo}
+	for depth := 1; len(frontier) > 0; depth++ {
+		var nextIDs []primitive.ObjectID
+		for _, current := range frontier {
+			for _, blockerID := range current.BlockedBy {
+				graph.Edges = append(graph.Edges, DependencyEdge{Blocker: blockerID, Blocked: current.ID})
+				if !visited[blockerID] {
+					visited[blockerID] = true
+					nextIDs = append(nextIDs, blockerID)
+				}
+			}
+		}
+
+		blockers, err := s.TodoRepo.GetTodosByIDs(ctx, nextIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, blocker := range blockers {
+			graph.Upstream = append(graph.Upstream, buildDependencyNode(blocker, depth, loc))
+			if depth == 1 && blocker.Status != TodoStatusDone {
+				graph.OpenBlockers++
+			}
+		}
+		frontier = blockers
+	}
+
+	// Walk dependents level by level
+	visited = map[primitive.ObjectID]bool{todo.ID: true}
+	frontier = []*Todo{todo}
+	for depth := 1; len(frontier) > 0; depth++ {
+		var next []*Todo
+		for _, current := range frontier {
+			dependents, err := s.TodoRepo.GetBlockedTodos(ctx, current.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, dependent := range dependents {
+				graph.Edges = append(graph.Edges, DependencyEdge{Blocker: current.ID, Blocked: dependent.ID})
+				if visited[dependent.ID] {
+					continue
+				}
+				visited[dependent.ID] = true
+				graph.Downstream = append(graph.Downstream, buildDependencyNode(dependent, depth, loc))
+				next = append(next, dependent)
+			}
+		}
+		frontier = next
+	}
+
+	return graph, nil
+}
+
+// reachesUpstream reports whether target is among start's blockers, directly or transitively.
+func (s *todoService) reachesUpstream(ctx context.Context, start, target primitive.ObjectID) (bool, error) {
+
+	visited := map[primitive.ObjectID]bool{start: true}
+	frontier := []primitive.ObjectID{start}
+
+	for len(frontier) > 0 {
+		todos, err := s.TodoRepo.GetTodosByIDs(ctx, frontier)
+		if err != nil {
+			return false, err
+		}
+
+		frontier = nil
+		for _, todo := range todos {
+			for _, blockerID := range todo.BlockedBy {
+				if blockerID == target {
+					return true, nil
+				}
+				if !visited[blockerID] {
+					visited[blockerID] = true
+					frontier = append(frontier, blockerID)
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// ensureNotBlocked rejects finishing a todo while any of its direct blockers is still open.
+func (s *todoService) ensureNotBlocked(ctx context.Context, todo *Todo) error {
+
+	blockers, err := s.TodoRepo.GetTodosByIDs(ctx, todo.BlockedBy)
+	if err != nil {
+		return err
+	}
+
+	var open []string
+	for _, blocker := range blockers {
+		if blocker.Status != TodoStatusDone {
+			open = append(open, blocker.Name)
+		}
+	}
+
+	if len(open) > 0 {
+		return fmt.Errorf("todo is blocked by %d open todo(s): %s", len(open), strings.Join(open, ", "))
+	}
+
+	return nil
+}
+
+func buildDependencyNode(todo *Todo, depth int, loc *time.Location) DependencyNode {
+	return DependencyNode{
+		ID:       todo.ID,
+		Name:     todo.Name,
+		Status:   todo.Status,
+		Progress: todo.Progress,
+		DueDate:  todo.DueDate.In(loc),
+		Depth:    depth,
+	}
+}
+
+func (s *todoService) GetTodoQRCode(ctx context.Context, todoID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return "", err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return "", err
+	}
+	if todo == nil {
+		return "", fmt.Errorf("todo not found")
+	}
+
+	return todo.QRCode, nil
+}
+
+const (
+	defaultInviteTTL   = 7 * 24 * time.Hour
+	inviteCodeLength   = 8
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+func (s *todoService) CreateInvite(ctx context.Context, todoID string, req CreateInviteRequest, userID string) (*InviteResponse, error) {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Role {
+	case MemberTypeTeachers, MemberTypeStudents, MemberTypeStaffs:
+	default:
+		return nil, fmt.Errorf("role must be teachers, students or staffs")
+	}
+
+	if req.MaxUses < 0 {
+		return nil, fmt.Errorf("max_uses must be greater than 0")
+	}
+
+	maxUses := req.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+	now := time.Now().UTC()
+
+	expiresAt := now.Add(defaultInviteTTL)
+	if req.ExpiresAt != "" {
+		expiresAt, err = helper.ParseDateTime(req.ExpiresAt, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at format: %v", err)
+		}
+	} else if req.ExpiresInHours > 0 {
+		expiresAt = now.Add(time.Duration(req.ExpiresInHours) * time.Hour)
+	}
+
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	code, err := s.newInviteCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &TodoInvite{
+		ID:             primitive.NewObjectID(),
+		TodoID:         todo.ID,
+		OrganizationID: todo.OrganizationID,
+		Code:           code,
+		Role:           req.Role,
+		MaxUses:        maxUses,
+		ExpiresAt:      expiresAt,
+		CreatedBy:      userID,
+		CreatedAt:      now,
+	}
+
+	if err := s.TodoRepo.CreateInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	return buildInviteResponse(invite, now, loc), nil
+}
+
+func (s *todoService) GetInvites(ctx context.Context, todoID string, userID string) ([]*InviteResponse, error) {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := s.TodoRepo.GetInvitesByTodo(ctx, todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+	now := time.Now().UTC()
+
+	results := make([]*InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		results = append(results, buildInviteResponse(invite, now, loc))
+	}
+
+	return results, nil
+}
+
+func (s *todoService) RevokeInvite(ctx context.Context, todoID, inviteID string, userID string) error {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return err
+	}
+
+	invite, err := s.getInviteOfTodo(ctx, todo, inviteID)
+	if err != nil {
+		return err
+	}
+
+	if invite.RevokedAt != nil {
+		return fmt.Errorf("invite is already revoked")
+	}
+
+	return s.TodoRepo.RevokeInvite(ctx, invite.ID, userID, time.Now().UTC())
+}
+
+func (s *todoService) GetInviteRedemptions(ctx context.Context, todoID, inviteID string, userID string) ([]*InviteRedemption, error) {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := s.getInviteOfTodo(ctx, todo, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+
+	redemptions, err := s.TodoRepo.GetRedemptionsByInvite(ctx, invite.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, redemption := range redemptions {
+		redemption.RedeemedAt = redemption.RedeemedAt.In(loc)
+	}
+
+	return redemptions, nil
+}
+
+// getTodoForMembers loads a todo and checks the caller may manage its members.
+func (s *todoService) getTodoForMembers(ctx context.Context, todoID string, userID string) (*Todo, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if todo == nil {
+		return nil, fmt.Errorf("todo not found")
+	}
+
+	if err := s.Policy.CanManageMembers(ctx, todo, userID); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) getInviteOfTodo(ctx context.Context, todo *Todo, inviteID string) (*TodoInvite, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := s.TodoRepo.GetInviteByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invite == nil || invite.TodoID != todo.ID {
+		return nil, fmt.Errorf("invite not found")
+	}
+
+	return invite, nil
+}
+
+// newInviteCode draws a random code without look-alike characters such as 0/O and 1/I.
+func (s *todoService) newInviteCode(ctx context.Context) (string, error) {
+
+	for attempt := 0; attempt < 5; attempt++ {
+		buf := make([]byte, inviteCodeLength)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+
+		code := make([]byte, inviteCodeLength)
+		for i, b := range buf {
+			code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+		}
+
+		existing, err := s.TodoRepo.GetInviteByCode(ctx, string(code))
+		if err != nil {
+			return "", err
+		}
+
+		if existing == nil {
+			return string(code), nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a unique invite code")
+}
+
+func inviteStatus(invite *TodoInvite, now time.Time) string {
+	switch {
+	case invite.RevokedAt != nil:
+		return InviteStatusRevoked
+	case !invite.ExpiresAt.After(now):
+		return InviteStatusExpired
+	case invite.Uses >= invite.MaxUses:
+		return InviteStatusUsedUp
+	default:
+		return InviteStatusActive
+	}
+}
+
+func buildInviteResponse(invite *TodoInvite, now time.Time, loc *time.Location) *InviteResponse {
+
+	remaining := invite.MaxUses - invite.Uses
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &InviteResponse{
+		ID:            invite.ID,
+		TodoID:        invite.TodoID,
+		Code:          invite.Code,
+		QRCode:        InviteQRCodePrefix + invite.Code,
+		Role:          invite.Role,
+		MaxUses:       invite.MaxUses,
+		Uses:          invite.Uses,
+		RemainingUses: remaining,
+		Status:        inviteStatus(invite, now),
+		ExpiresAt:     invite.ExpiresAt.In(loc),
+		RevokedAt:     helper.InLocation(invite.RevokedAt, loc),
+		RevokedBy:     invite.RevokedBy,
+		CreatedBy:     invite.CreatedBy,
+		CreatedAt:     invite.CreatedAt.In(loc),
+	}
+}
+
+func validateJoinPolicy(policy string) error {
+	switch policy {
+	case JoinPolicyOpen, JoinPolicyApproval, JoinPolicyClosed:
+		return nil
+	default:
+		return fmt.Errorf("join_policy must be open, approval or closed")
+	}
+}
+
+// createJoinRequest records a pending membership and lets the creator and teachers know.
+// Asking twice returns the request that is already waiting.
+func (s *todoService) createJoinRequest(ctx context.Context, todo *Todo, userID, role, message string) (*JoinTodoResponse, error) {
+
+	existing, err := s.TodoRepo.GetPendingJoinRequest(ctx, todo.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return &JoinTodoResponse{TodoID: todo.ID, Status: JoinResultPending, Role: existing.Role, RequestID: &existing.ID}, nil
+	}
+
+	request := &JoinRequest{
+		ID:             primitive.NewObjectID(),
+		TodoID:         todo.ID,
+		OrganizationID: todo.OrganizationID,
+		UserID:         userID,
+		Role:           role,
+		Message:        strings.TrimSpace(message),
+		Status:         JoinRequestPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+
+	if err := s.TodoRepo.CreateJoinRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	reviewers := append([]string{todo.CreatedBy}, todo.TaskUsers.Teachers...)
+	s.notify(ctx, notification.Message{
+		UserIDs:        reviewers,
+		OrganizationID: todo.OrganizationID,
+		Type:           "todo.join_request_created",
+		Title:          "New join request",
+		Body:           fmt.Sprintf("Someone asked to join %q as %s", todo.Name, strings.TrimSuffix(role, "s")),
+		Data: map[string]string{
+			"todo_id":    todo.ID.Hex(),
+			"request_id": request.ID.Hex(),
+			"user_id":    userID,
+		},
+	})
+
+	return &JoinTodoResponse{TodoID: todo.ID, Status: JoinResultPending, Role: role, RequestID: &request.ID}, nil
+}
+
+func (s *todoService) GetJoinRequests(ctx context.Context, todoID, status string, userID string) ([]*JoinRequestResponse, error) {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == "" {
+		status = JoinRequestPending
+	}
+
+	requests, err := s.TodoRepo.GetJoinRequestsByTodo(ctx, todo.ID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+
+	results := make([]*JoinRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		requester := TaskUser{UserID: request.UserID}
+		info, err := s.UserService.GetUserInfor(ctx, request.UserID)
+		if err != nil {
+			log.Printf("[WARN] failed to load user %s for join request: %v", request.UserID, err)
+		} else if info != nil {
+			requester.UserName = info.UserName
+			requester.Avartar = info.Avartar
+		}
+
+		results = append(results, &JoinRequestResponse{
+			ID:        request.ID,
+			TodoID:    request.TodoID,
+			User:      requester,
+			Role:      request.Role,
+			Message:   request.Message,
+			Status:    request.Status,
+			DecidedBy: request.DecidedBy,
+			DecidedAt: helper.InLocation(request.DecidedAt, loc),
+			Reason:    request.Reason,
+			CreatedAt: request.CreatedAt.In(loc),
+		})
+	}
+
+	return results, nil
+}
+
+func (s *todoService) ApproveJoinRequest(ctx context.Context, todoID, requestID string, userID string) error {
+
+	todo, request, err := s.getPendingJoinRequest(ctx, todoID, requestID, userID)
+	if err != nil {
+		return err
+	}
+
+	decided, err := s.TodoRepo.DecideJoinRequest(ctx, request.ID, JoinRequestApproved, userID, nil, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if !decided {
+		return fmt.Errorf("join request has already been decided")
+	}
+
+	if err := s.TodoRepo.JoinTodo(ctx, todo.ID, request.UserID, request.Role, false); err != nil {
+		return err
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	s.notify(ctx, notification.Message{
+		UserIDs:        []string{request.UserID},
+		OrganizationID: todo.OrganizationID,
+		Type:           "todo.join_request_approved",
+		Title:          "Join request approved",
+		Body:           fmt.Sprintf("You have joined %q", todo.Name),
+		Data: map[string]string{
+			"todo_id":    todo.ID.Hex(),
+			"request_id": request.ID.Hex(),
+		},
+	})
+
+	return nil
+}
+
+func (s *todoService) RejectJoinRequest(ctx context.Context, todoID, requestID string, req RejectJoinRequestRequest, userID string) error {
+
+	todo, request, err := s.getPendingJoinRequest(ctx, todoID, requestID, userID)
+	if err != nil {
+		return err
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+
+	decided, err := s.TodoRepo.DecideJoinRequest(ctx, request.ID, JoinRequestRejected, userID, reason, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if !decided {
+		return fmt.Errorf("join request has already been decided")
+	}
+
+	body := fmt.Sprintf("Your request to join %q was declined", todo.Name)
+	if reason != nil {
+		body = fmt.Sprintf("%s: %s", body, *reason)
+	}
+
+	s.notify(ctx, notification.Message{
+		UserIDs:        []string{request.UserID},
+		OrganizationID: todo.OrganizationID,
+		Type:           "todo.join_request_rejected",
+		Title:          "Join request declined",
+		Body:           body,
+		Data: map[string]string{
+			"todo_id":    todo.ID.Hex(),
+			"request_id": request.ID.Hex(),
+		},
+	})
+
+	return nil
+}
+
+func (s *todoService) getPendingJoinRequest(ctx context.Context, todoID, requestID string, userID string) (*Todo, *JoinRequest, error) {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request, err := s.TodoRepo.GetJoinRequestByID(ctx, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request == nil || request.TodoID != todo.ID {
+		return nil, nil, fmt.Errorf("join request not found")
+	}
+
+	if request.Status != JoinRequestPending {
+		return nil, nil, fmt.Errorf("join request has already been %s", request.Status)
+	}
+
+	return todo, request, nil
+}
+
+// notify sends a notification without failing the action that triggered it.
+func (s *todoService) notify(ctx context.Context, msg notification.Message) {
+	if s.NotificationService == nil {
+		return
+	}
+	if err := s.NotificationService.Notify(ctx, msg); err != nil {
+		log.Printf("[WARN] failed to send %s notification: %v", msg.Type, err)
+	}
+}
+
+var memberTypes = []string{MemberTypeTeachers, MemberTypeStudents, MemberTypeStaffs}
+
+func (s *todoService) AddMembers(ctx context.Context, todoID string, req AddMembersRequest, userID string) error {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := memberField(req.Type); err != nil {
+		return fmt.Errorf("type must be teachers, students or staffs")
+	}
+
+	seen := map[string]bool{}
+	var userIDs []string
+	for _, id := range req.UserIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		if id == todo.CreatedBy {
+			return fmt.Errorf("the creator cannot be added as a member")
+		}
+		seen[id] = true
+		userIDs = append(userIDs, id)
+	}
+
+	if len(userIDs) == 0 {
+		return fmt.Errorf("user_ids is required")
+	}
+
+	if err := s.validateMembers(ctx, todo.OrganizationID, req.Type, userIDs); err != nil {
+		return err
+	}
+
+	if err := s.TodoRepo.AddUsers(ctx, todo.ID, userIDs, req.Type); err != nil {
+		return err
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	// Only people who were not already in the list hear about it
+	existing := membersOf(todo, req.Type)
+	var added []string
+	for _, id := range userIDs {
+		if !containsString(existing, id) {
+			added = append(added, id)
+		}
+	}
+
+	s.notify(ctx, notification.Message{
+		UserIDs:        added,
+		OrganizationID: todo.OrganizationID,
+		Type:           "todo.member_added",
+		Title:          "Added to a todo",
+		Body:           fmt.Sprintf("You were added to %q as %s", todo.Name, strings.TrimSuffix(req.Type, "s")),
+		Data:           map[string]string{"todo_id": todo.ID.Hex()},
+	})
+
+	return nil
+}
+
+// RemoveMember takes a member off the todo. With an empty memberType the member is
+// removed from every role they hold.
+func (s *todoService) RemoveMember(ctx context.Context, todoID, memberID, memberType string, userID string) error {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return err
+	}
+
+	types := memberTypes
+	if memberType != "" {
+		if _, err := memberField(memberType); err != nil {
+			return fmt.Errorf("type must be teachers, students or staffs")
+		}
+		types = []string{memberType}
+	}
+
+	removed, err := s.TodoRepo.RemoveMember(ctx, todo.ID, memberID, types)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("user is not a member of this todo")
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	return nil
+}
+
+func (s *todoService) MoveMember(ctx context.Context, todoID, memberID string, req MoveMemberRequest, userID string) error {
+
+	todo, err := s.getTodoForMembers(ctx, todoID, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := memberField(req.To); err != nil {
+		return fmt.Errorf("to must be teachers, students or staffs")
+	}
+
+	from := req.From
+	if from == "" {
+		var held []string
+		for _, memberType := range memberTypes {
+			if containsString(membersOf(todo, memberType), memberID) {
+				held = append(held, memberType)
+			}
+		}
+		switch len(held) {
+		case 0:
+			return fmt.Errorf("user is not a member of this todo")
+		case 1:
+			from = held[0]
+		default:
+			return fmt.Errorf("user holds several roles (%s), set from", strings.Join(held, ", "))
+		}
+	} else if _, err := memberField(from); err != nil {
+		return fmt.Errorf("from must be teachers, students or staffs")
+	}
+
+	if from == req.To {
+		return fmt.Errorf("user is already in %s", req.To)
+	}
+
+	if err := s.validateMembers(ctx, todo.OrganizationID, req.To, []string{memberID}); err != nil {
+		return err
+	}
+
+	moved, err := s.TodoRepo.MoveMember(ctx, todo.ID, memberID, from, req.To)
+	if err != nil {
+		return err
+	}
+
+	if !moved {
+		return fmt.Errorf("user is not in %s on this todo", from)
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	return nil
+}
+
+func (s *todoService) LeaveTodo(ctx context.Context, todoID string, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if todo == nil {
+		return fmt.Errorf("todo not found")
+	}
+
+	if todo.CreatedBy == userID {
+		return fmt.Errorf("the creator cannot leave their own todo")
+	}
+
+	left, err := s.TodoRepo.RemoveMember(ctx, todo.ID, userID, memberTypes)
+	if err != nil {
+		return err
+	}
+
+	if !left {
+		return fmt.Errorf("you are not a member of this todo")
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	return nil
+}
+
+// validateMembers checks every ID belongs to the organization in the given role. Students
+// have no organization lookup, so they only need to exist.
+func (s *todoService) validateMembers(ctx context.Context, organizationID, memberType string, userIDs []string) error {
+
+	var invalid []string
+	for _, id := range userIDs {
+		var (
+			info *user.UserInfor
+			err  error
+		)
+
+		switch memberType {
+		case MemberTypeTeachers:
+			info, err = s.UserService.GetTeacherInforByOrg(ctx, id, organizationID)
+		case MemberTypeStaffs:
+			info, err = s.UserService.GetStaffInforByOrg(ctx, id, organizationID)
+		case MemberTypeStudents:
+			info, err = s.UserService.GetStudentInfor(ctx, id)
+		default:
+			return fmt.Errorf("type user not found")
+		}
+
+		if err != nil {
+			log.Printf("[WARN] failed to verify %s %s in organization %s: %v", memberType, id, organizationID, err)
+		}
+
+		if err != nil || info == nil || info.UserID == "" {
+			invalid = append(invalid, id)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("not %s of this organization: %s", memberType, strings.Join(invalid, ", "))
+	}
+
+	return nil
+}
+
+func membersOf(todo *Todo, memberType string) []string {
+	switch memberType {
+	case MemberTypeTeachers:
+		return todo.TaskUsers.Teachers
+	case MemberTypeStudents:
+		return todo.TaskUsers.Students
+	case MemberTypeStaffs:
+		return todo.TaskUsers.Staffs
+	default:
+		return nil
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validateProgressMode(mode string) error {
+	switch mode {
+	case ProgressModeMean, ProgressModeDoneShare:
+		return nil
+	default:
+		return fmt.Errorf("progress_mode must be mean or done_share")
+	}
+}
+
+// recomputeProgress refreshes the aggregate after membership changes. The change itself
+// already happened, so a failure is only logged.
+func (s *todoService) recomputeProgress(ctx context.Context, todoID primitive.ObjectID) {
+	resolved, err := s.resolvedBlockers(ctx, todoID)
+	if err != nil {
+		log.Printf("[WARN] failed to check blockers of todo %s: %v", todoID.Hex(), err)
+		return
+	}
+	if err := s.TodoRepo.RecomputeProgress(ctx, todoID, resolved); err != nil {
+		log.Printf("[WARN] failed to recompute progress of todo %s: %v", todoID.Hex(), err)
+	}
+}
+
+// resolvedBlockers lists the todo's blockers that no longer hold it back, the same way
+// ensureNotBlocked sees them: done, or deleted since.
+func (s *todoService) resolvedBlockers(ctx context.Context, todoID primitive.ObjectID) ([]primitive.ObjectID, error) {
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, todoID)
+	if err != nil || todo == nil || len(todo.BlockedBy) == 0 {
+		return nil, err
+	}
+
+	blockers, err := s.TodoRepo.GetTodosByIDs(ctx, todo.BlockedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	open := map[primitive.ObjectID]bool{}
+	for _, blocker := range blockers {
+		if blocker.Status != TodoStatusDone {
+			open[blocker.ID] = true
+		}
+	}
+
+	resolved := make([]primitive.ObjectID, 0, len(todo.BlockedBy))
+	for _, blockerID := range todo.BlockedBy {
+		if !open[blockerID] {
+			resolved = append(resolved, blockerID)
+		}
+	}
+	return resolved, nil
+}
+
+// UpdateMemberProgress records a student's or staff member's own progress and rolls it up
+// into the todo. A missing status follows the progress: 100 is done, anything above 0 is
+// in progress.
+func (s *todoService) UpdateMemberProgress(ctx context.Context, todoID, memberID string, req UpdateMemberProgressRequest, userID string) error {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	if todo == nil {
+		return fmt.Errorf("todo not found")
+	}
+
+	if memberRole(todo, memberID) == "" {
+		return fmt.Errorf("user is not a student or staff member of this todo")
+	}
+
+	if err := s.Policy.CanUpdateMemberProgress(ctx, todo, userID, memberID); err != nil {
+		return err
+	}
+
+	entry := MemberProgress{UserID: memberID, Status: MemberStatusPending, Pictures: []string{}}
+	if existing := findMemberProgress(todo, memberID); existing != nil {
+		entry = *existing
+	}
+	wasDone := entry.Status == MemberStatusDone
+
+	if req.Progress != nil {
+		if *req.Progress < 0 || *req.Progress > 100 {
+			return fmt.Errorf("progress must be between 0 and 100")
+		}
+		entry.Progress = *req.Progress
+	}
+
+	switch req.Status {
+	case "":
+		if req.Progress != nil {
+			switch {
+			case entry.Progress == 100:
+				entry.Status = MemberStatusDone
+			case entry.Progress > 0:
+				entry.Status = MemberStatusInProgress
+			default:
+				entry.Status = MemberStatusPending
+			}
+		}
+	case MemberStatusPending, MemberStatusInProgress:
+		entry.Status = req.Status
+	case MemberStatusDone:
+		entry.Status = req.Status
+		entry.Progress = 100
+	default:
+		return fmt.Errorf("status must be pending, in_progress or done")
+	}
+
+	if entry.Status != MemberStatusDone && entry.Progress == 100 {
+		return fmt.Errorf("progress 100 requires status done")
+	}
+
+	for _, picture := range req.Pictures {
+		if picture != "" {
+			entry.Pictures = append(entry.Pictures, picture)
+		}
+	}
+
+	now := time.Now().UTC()
+	done := entry.Status == MemberStatusDone
+	if done && !wasDone {
+		if err := s.ensureNotBlocked(ctx, todo); err != nil {
+			return err
+		}
+		entry.SubmittedAt = &now
+	} else if !done {
+		entry.SubmittedAt = nil
+	}
+	entry.UpdatedAt = now
+
+	if err := s.TodoRepo.SaveMemberProgress(ctx, todo.ID, entry); err != nil {
+		return err
+	}
+
+	s.recomputeProgress(ctx, todo.ID)
+
+	if done && !wasDone && memberID == userID {
+		recipients := append([]string{todo.CreatedBy}, todo.TaskUsers.Teachers...)
+		s.notify(ctx, notification.Message{
+			UserIDs:        recipients,
+			OrganizationID: todo.OrganizationID,
+			Type:           "todo.member_done",
+			Title:          "Todo submitted",
+			Body:           fmt.Sprintf("A member finished %q", todo.Name),
+			Data: map[string]string{
+				"todo_id": todo.ID.Hex(),
+				"user_id": memberID,
+			},
+		})
+	}
+
+	return nil
+}
+
+// GetProgressBreakdown lists every student and staff member with their own progress so the
+// creator and teachers can see who is behind.
+func (s *todoService) GetProgressBreakdown(ctx context.Context, todoID string, userID string) (*ProgressBreakdownResponse, error) {
+
+	objectID, err := primitive.ObjectIDFromHex(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.TodoRepo.GetTodoByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if todo == nil {
+		return nil, fmt.Errorf("todo not found")
+	}
+
+	if err := s.Policy.CanViewProgress(ctx, todo, userID); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, todo.OrganizationID)
+	now := time.Now().UTC()
+
+	var members []*MemberProgressResponse
+	seen := map[string]bool{}
+	done, sum := 0, 0
+
+	for _, memberType := range []string{MemberTypeStudents, MemberTypeStaffs} {
+		for _, memberID := range membersOf(todo, memberType) {
+			if memberID == "" || seen[memberID] {
+				continue
+			}
+			seen[memberID] = true
+
+			member := &MemberProgressResponse{
+				User:     TaskUser{UserID: memberID},
+				Role:     strings.TrimSuffix(memberType, "s"),
+				Status:   MemberStatusPending,
+				Pictures: []string{},
+			}
+
+			info, err := s.UserService.GetUserInfor(ctx, memberID)
+			if err != nil {
+				log.Printf("[WARN] failed to load user %s for progress breakdown: %v", memberID, err)
+			} else if info != nil {
+				member.User.UserName = info.UserName
+				member.User.Avartar = info.Avartar
+			}
+
+			if entry := findMemberProgress(todo, memberID); entry != nil {
+				updatedAt := entry.UpdatedAt.In(loc)
+				member.Progress = entry.Progress
+				member.Status = entry.Status
+				member.Pictures = entry.Pictures
+				member.SubmittedAt = helper.InLocation(entry.SubmittedAt, loc)
+				member.UpdatedAt = &updatedAt
+			}
+
+			if member.Status == MemberStatusDone {
+				done++
+			}
+			sum += member.Progress
+			members = append(members, member)
+		}
+	}
+
+	overdue := todo.Status != TodoStatusDone && !todo.DueDate.IsZero() && now.After(todo.DueDate)
+
+	average := 0.0
+	if len(members) > 0 {
+		average = float64(sum) / float64(len(members))
+	}
+
+	for _, member := range members {
+		if member.Status != MemberStatusDone {
+			member.Behind = overdue || float64(member.Progress) < average
+		}
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Progress != members[j].Progress {
+			return members[i].Progress < members[j].Progress
+		}
+		return members[i].User.UserName < members[j].User.UserName
+	})
+
+	return &ProgressBreakdownResponse{
+		TodoID:   todo.ID,
+		Mode:     todo.EffectiveProgressMode(),
+		Progress: todo.Progress,
+		Status:   todo.Status,
+		Overdue:  overdue,
+		Done:     done,
+		Total:    len(members),
+		Members:  members,
+	}, nil
+}
+
+// memberRole returns the role that carries progress for the user, or "" when they are
+// neither a student nor staff on the todo.
+func memberRole(todo *Todo, userID string) string {
+	for _, memberType := range []string{MemberTypeStudents, MemberTypeStaffs} {
+		if containsString(membersOf(todo, memberType), userID) {
+			return memberType
+		}
+	}
+	return ""
+}
+
+func findMemberProgress(todo *Todo, userID string) *MemberProgress {
+	for i := range todo.MemberProgress {
+		if todo.MemberProgress[i].UserID == userID {
+			return &todo.MemberProgress[i]
+		}
+	}
+	return nil
+}
+
+const (
+	defaultStatsRangeDays   = 30
+	maxStatsRangeDays       = 366
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// GetTodoStats reports on the organization's todos created between from and to, which
+// default to the last 30 days.
+func (s *todoService) GetTodoStats(ctx context.Context, organizationID, from, to string, limit int, userID string) (*TodoStatsResponse, error) {
+
+	if organizationID == "" {
+		return nil, fmt.Errorf("organization id is required")
+	}
+
+	if err := s.Policy.CanViewStats(ctx, userID, s.isOrganizationMember(ctx, userID, organizationID)); err != nil {
+		return nil, err
+	}
+
+	loc := s.SettingService.GetUserLocation(ctx, userID, organizationID)
+	now := time.Now().UTC()
+
+	toTime := now
+	if to != "" {
+		parsed, err := helper.ParseDateTime(to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to format: %v", err)
+		}
+		toTime = parsed
+	}
+
+	fromTime := helper.StartOfDay(toTime.AddDate(0, 0, -defaultStatsRangeDays), loc).UTC()
+	if from != "" {
+		parsed, err := helper.ParseDateTime(from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from format: %v", err)
+		}
+		fromTime = parsed
+	}
+
+	if !fromTime.Before(toTime) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	if toTime.Sub(fromTime) > maxStatsRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("the range cannot be longer than %d days", maxStatsRangeDays)
+	}
+
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+
+	stats, err := s.TodoRepo.GetTodoStats(ctx, organizationID, fromTime, toTime, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TodoStatsResponse{
+		OrganizationID:    organizationID,
+		From:              fromTime.In(loc),
+		To:                toTime.In(loc),
+		Total:             stats.Total,
+		ByStatus:          countResponses(stats.ByStatus),
+		ByStage:           countResponses(stats.ByStage),
+		Overdue:           stats.Overdue,
+		Completed:         stats.Completed,
+		OnTimeRate:        percentage(stats.CompletedOnTime, stats.Completed),
+		AverageCycleHours: math.Round(stats.AverageCycleTime/float64(time.Hour/time.Millisecond)*10) / 10,
+		UrgentShare:       percentage(stats.Urgent, stats.Total),
+		Leaderboard:       make([]LeaderboardEntry, 0, len(stats.Leaderboard)),
+	}
+
+	for _, member := range stats.Leaderboard {
+		entry := LeaderboardEntry{
+			User:            TaskUser{UserID: member.UserID},
+			Assigned:        member.Assigned,
+			Completed:       member.Completed,
+			CompletedOnTime: member.CompletedOnTime,
+			AverageProgress: math.Round(member.AverageProgress*10) / 10,
+		}
+
+		info, err := s.UserService.GetUserInfor(ctx, member.UserID)
+		if err != nil {
+			log.Printf("[WARN] failed to load user %s for todo stats: %v", member.UserID, err)
+		} else if info != nil {
+			entry.User.UserName = info.UserName
+			entry.User.Avartar = info.Avartar
+		}
+
+		result.Leaderboard = append(result.Leaderboard, entry)
+	}
+
+	return result, nil
+}
+
+// isOrganizationMember reports whether the user is a teacher or staff member of the organization.
+func (s *todoService) isOrganizationMember(ctx context.Context, userID, organizationID string) bool {
+
+	teacher, err := s.UserService.GetTeacherInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check teacher %s in organization %s: %v", userID, organizationID, err)
+	} else if teacher != nil && teacher.UserID != "" {
+		return true
+	}
+
+	staff, err := s.UserService.GetStaffInforByOrg(ctx, userID, organizationID)
+	if err != nil {
+		log.Printf("[WARN] failed to check staff %s in organization %s: %v", userID, organizationID, err)
+		return false
+	}
+
+	return staff != nil && staff.UserID != ""
+}
+
+func countResponses(buckets []CountBucket) []CountResponse {
+	results := make([]CountResponse, 0, len(buckets))
+	for _, bucket := range buckets {
+		key := ""
+		if bucket.Key != nil {
+			key = *bucket.Key
+		}
+		results = append(results, CountResponse{Key: key, Count: bucket.Count})
+	}
+	return results
+}
+
+// percentage returns part of total as a percentage rounded to one decimal, or 0 for an empty total.
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
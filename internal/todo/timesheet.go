@@ -0,0 +1,47 @@
+package todo
+
+import (
+	"context"
+	"todo-service/internal/timesheet"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimesheetTarget lets the timesheet log time against todos. Every member can log
+// time; the creator and teachers see everyone's.
+type TimesheetTarget struct {
+	TodoRepo TodoRepository
+}
+
+func NewTimesheetTarget(todoRepo TodoRepository) *TimesheetTarget {
+	return &TimesheetTarget{
+		TodoRepo: todoRepo,
+	}
+}
+
+func (t *TimesheetTarget) ResolveTarget(ctx context.Context, id primitive.ObjectID) (*timesheet.Target, error) {
+
+	todo, err := t.TodoRepo.GetTodoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if todo == nil {
+		return nil, nil
+	}
+
+	managers := append([]string{todo.CreatedBy}, todo.TaskUsers.Teachers...)
+
+	members := append([]string{}, managers...)
+	members = append(members, todo.TaskUsers.Students...)
+	members = append(members, todo.TaskUsers.Staffs...)
+
+	return &timesheet.Target{
+		ID:             todo.ID,
+		Type:           timesheet.TargetTodo,
+		OrganizationID: todo.OrganizationID,
+		Name:           todo.Name,
+		Members:        members,
+		Managers:       managers,
+	}, nil
+}
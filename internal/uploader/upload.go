@@ -1,9 +1,11 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"time"
@@ -21,11 +23,19 @@ type ImageKey struct {
 	Key string `json:"key"`
 }
 
+// PDFFile is a PDF stored by the main service.
+type PDFFile struct {
+	Key string `json:"key"`
+	Url string `json:"url"`
+}
+
 type ImageService interface {
 	GetImageKey(ctx context.Context, key string) (*Avatar, error)
 	GetPDFKey(ctx context.Context, key string) (*Avatar, error)
+	GetPrivatePDFKey(ctx context.Context, key string) (*Avatar, error)
 	DeleteImageKey(ctx context.Context, key string) error
 	DeletePDFKey(ctx context.Context, key string) error
+	UploadPDF(ctx context.Context, fileName, folder string, data []byte) (*PDFFile, error)
 }
 
 type imageService struct {
@@ -109,13 +119,22 @@ func (s *imageService) GetImageKey(ctx context.Context, key string) (*Avatar, er
 }
 
 func (s *imageService) GetPDFKey(ctx context.Context, key string) (*Avatar, error) {
+	return s.getPDF(ctx, key, "public")
+}
+
+// GetPrivatePDFKey gets a short-lived URL for a PDF stored through UploadPDF.
+func (s *imageService) GetPrivatePDFKey(ctx context.Context, key string) (*Avatar, error) {
+	return s.getPDF(ctx, key, "private")
+}
+
+func (s *imageService) getPDF(ctx context.Context, key, mode string) (*Avatar, error) {
 
 	token, ok := ctx.Value(constants.TokenKey).(string)
 	if !ok {
 		return nil, fmt.Errorf("token not found in context")
 	}
 
-	pdf, err := s.client.getPDFKey(key, token)
+	pdf, err := s.client.getPDFKey(key, mode, token)
 	if err != nil {
 		return nil, err
 	}
@@ -171,6 +190,37 @@ func (s *imageService) DeletePDFKey(ctx context.Context, key string) error {
 	return nil
 }
 
+// UploadPDF stores the PDF privately, so it can only be read through GetPrivatePDFKey.
+func (s *imageService) UploadPDF(ctx context.Context, fileName, folder string, data []byte) (*PDFFile, error) {
+
+	token, ok := ctx.Value(constants.TokenKey).(string)
+	if !ok {
+		return nil, fmt.Errorf("token not found in context")
+	}
+
+	pdf, err := s.client.uploadPDF(fileName, folder, data, token)
+	if err != nil {
+		return nil, err
+	}
+
+	dataMap, ok := pdf["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected upload response")
+	}
+
+	key, _ := dataMap["key"].(string)
+	if key == "" {
+		return nil, fmt.Errorf("upload response has no key")
+	}
+
+	url, _ := dataMap["url"].(string)
+
+	return &PDFFile{
+		Key: key,
+		Url: url,
+	}, nil
+}
+
 func (c *callAPI) getImageKey(key string, token string) (map[string]interface{}, error) {
 
 	endpoint := "/v1/images"
@@ -209,7 +259,7 @@ func (c *callAPI) getImageKey(key string, token string) (map[string]interface{},
 	return myMap, nil
 }
 
-func (c *callAPI) getPDFKey(key string, token string) (map[string]interface{}, error) {
+func (c *callAPI) getPDFKey(key string, mode string, token string) (map[string]interface{}, error) {
 
 	endpoint := "/v1/pdfs"
 
@@ -220,7 +270,7 @@ func (c *callAPI) getPDFKey(key string, token string) (map[string]interface{}, e
 
 	body := map[string]string{
 		"key":  key,
-		"mode": "public",
+		"mode": mode,
 	}
 
 	jsonBody, err := json.Marshal(body)
@@ -297,4 +347,51 @@ func (c *callAPI) deletePDFKey(key string, token string) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (c *callAPI) uploadPDF(fileName, folder string, data []byte, token string) (map[string]interface{}, error) {
+
+	endpoint := "/v1/pdfs/upload"
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error creating form file: %v", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("error writing form file: %v", err)
+	}
+	if err := writer.WriteField("folder", folder); err != nil {
+		return nil, fmt.Errorf("error writing form field: %v", err)
+	}
+	if err := writer.WriteField("file_name", fileName); err != nil {
+		return nil, fmt.Errorf("error writing form field: %v", err)
+	}
+	if err := writer.WriteField("mode", "private"); err != nil {
+		return nil, fmt.Errorf("error writing form field: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error closing form: %v", err)
+	}
+
+	header := map[string]string{
+		"Content-Type":  writer.FormDataContentType(),
+		"Authorization": "Bearer " + token,
+	}
+
+	res, err := c.client.CallAPI(c.clientServer, endpoint, http.MethodPost, body.Bytes(), header)
+	if err != nil {
+		return nil, fmt.Errorf("error calling API: %v", err)
+	}
+
+	var pdfData map[string]interface{}
+
+	err = json.Unmarshal([]byte(res), &pdfData)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling response: %v", err)
+	}
+
+	return pdfData, nil
+}
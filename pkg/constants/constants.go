@@ -106,6 +106,7 @@ const (
 	MaximumUsageTime = "maximum_usage_time"
 
 	UserID = "user_id"
+	Claims = "claims"
 )
 
 type contextKey string